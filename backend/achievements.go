@@ -0,0 +1,46 @@
+package main
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/aygoko/EcoMInd/backend/config"
+    "github.com/aygoko/EcoMInd/backend/repository"
+    "github.com/aygoko/EcoMInd/backend/repository/postgres"
+    "github.com/aygoko/EcoMInd/backend/usecases/service"
+)
+
+const achievementsUsage = "usage: ecomind achievements [flags] backfill"
+
+// runAchievements implements the achievements subcommand, which awards the
+// badges users already qualify for, such as badges added in a new release
+func runAchievements(cfg *config.Config, args []string) error {
+    if cfg.Storage != "postgres" {
+        return fmt.Errorf("achievements needs storage postgres, configured storage is %q", cfg.Storage)
+    }
+    if len(args) != 1 || args[0] != "backfill" {
+        return fmt.Errorf(achievementsUsage)
+    }
+
+    pgDB := openPostgres(cfg)
+    defer pgDB.Close()
+    redisClient := openRedis(cfg)
+    defer redisClient.Close()
+
+    achievements := service.NewAchievementService(
+        postgres.NewAchievementRepository(pgDB, repository.DefaultLogger),
+        postgres.NewUserRepository(pgDB, redisClient, cfg.Redis.CacheTTL, repository.DefaultLogger),
+        postgres.NewTaskRepository(pgDB, redisClient, repository.DefaultLogger),
+        postgres.NewGoalRepository(pgDB, repository.DefaultLogger),
+        postgres.NewStatsRepository(pgDB, repository.DefaultLogger),
+        service.DefaultBadges...,
+    )
+    awarded, err := achievements.Backfill(context.Background(), time.Now())
+    if err != nil {
+        return err
+    }
+    log.Printf("Awarded %d badge(s)", awarded)
+    return nil
+}
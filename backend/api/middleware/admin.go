@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireAdmin only lets users with one of the given logins through. It
+// must run after RequireAuth.
+func RequireAdmin(logins []string) fiber.Handler {
+	admins := make(map[string]struct{}, len(logins))
+	for _, login := range logins {
+		admins[login] = struct{}{}
+	}
+	return func(c *fiber.Ctx) error {
+		user := domain.UserFromContext(c.UserContext())
+		if user == nil {
+			return unauthorized(c, "missing bearer token")
+		}
+		if _, ok := admins[user.Login]; !ok {
+			return errs.Forbidden("admin access required")
+		}
+		return c.Next()
+	}
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequireAuth rejects requests without a valid bearer access token of a live
+// session and puts the authenticated domain.User into the request's user
+// context, where handlers and services read it with domain.UserFromContext.
+func RequireAuth(tokens *service.TokenService, sessions *service.SessionService, users *service.UserService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		header := c.Get(fiber.HeaderAuthorization)
+		tokenString, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok || tokenString == "" {
+			return unauthorized(c, "missing bearer token")
+		}
+
+		claims, err := tokens.Parse(tokenString, service.TokenTypeAccess)
+		if err != nil {
+			return unauthorized(c, "invalid or expired token")
+		}
+
+		err = sessions.Check(c.UserContext(), claims.UserID, claims.SessionID)
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			// The session was signed out or revoked after the token was issued
+			return unauthorized(c, "invalid or expired token")
+		} else if err != nil {
+			return err
+		}
+
+		user, err := users.GetByID(c.UserContext(), claims.UserID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			// The account was deleted after the token was issued
+			return unauthorized(c, "invalid or expired token")
+		} else if err != nil {
+			return err
+		}
+
+		ctx := domain.ContextWithUser(c.UserContext(), user)
+		c.SetUserContext(domain.ContextWithSessionID(ctx, claims.SessionID))
+		return c.Next()
+	}
+}
+
+// OptionalAuth authenticates requests that carry an Authorization header
+// like RequireAuth, rejecting bad tokens, and lets anonymous requests through
+// without a user in their context.
+func OptionalAuth(tokens *service.TokenService, sessions *service.SessionService, users *service.UserService) fiber.Handler {
+	requireAuth := RequireAuth(tokens, sessions, users)
+	return func(c *fiber.Ctx) error {
+		if c.Get(fiber.HeaderAuthorization) == "" {
+			return c.Next()
+		}
+		return requireAuth(c)
+	}
+}
+
+func unauthorized(c *fiber.Ctx, message string) error {
+	c.Set(fiber.HeaderWWWAuthenticate, `Bearer error="invalid_token"`)
+	return errs.Unauthorized(message)
+}
@@ -0,0 +1,22 @@
+package middleware
+
+import (
+	"github.com/aygoko/EcoMInd/backend/domain"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Language picks the best of the supported languages for the request's
+// Accept-Language header, the first one when none matches, and puts it into
+// the request's user context for domain.LanguageFromContext.
+func Language(supported []string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		language := c.AcceptsLanguages(supported...)
+		if language == "" {
+			language = supported[0]
+		}
+		c.SetUserContext(domain.ContextWithLanguage(c.UserContext(), language))
+		c.Vary(fiber.HeaderAcceptLanguage)
+		return c.Next()
+	}
+}
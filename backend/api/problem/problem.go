@@ -0,0 +1,106 @@
+// Package problem reports errors to API clients as RFC 7807 problem details.
+// Handlers return errors instead of writing error responses, and Handler,
+// the app's error handler, renders them all the same way.
+package problem
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ContentType is the media type of problem details
+const ContentType = "application/problem+json"
+
+// ErrInvalidBody is returned for request bodies that don't parse
+var ErrInvalidBody = errs.Validation("invalid request body")
+
+// Details is an RFC 7807 problem details object. Code, the errs.Kind, and
+// Errors, the fields at fault, are extension members.
+type Details struct {
+	Type     string            `json:"type"`
+	Title    string            `json:"title"`
+	Status   int               `json:"status"`
+	Detail   string            `json:"detail,omitempty"`
+	Instance string            `json:"instance,omitempty"`
+	Code     errs.Kind         `json:"code,omitempty"`
+	Errors   []errs.FieldError `json:"errors,omitempty"`
+}
+
+// statuses maps error kinds to HTTP statuses
+var statuses = map[errs.Kind]int{
+	errs.KindValidation:   http.StatusBadRequest,
+	errs.KindUnauthorized: http.StatusUnauthorized,
+	errs.KindForbidden:    http.StatusForbidden,
+	errs.KindNotFound:     http.StatusNotFound,
+	errs.KindConflict:     http.StatusConflict,
+	errs.KindRateLimited:  http.StatusTooManyRequests,
+	errs.KindUpstream:     http.StatusBadGateway,
+	errs.KindInternal:     http.StatusInternalServerError,
+}
+
+// kindOfStatus maps the statuses of Fiber's own errors, like unknown routes,
+// back to kinds
+func kindOfStatus(status int) errs.Kind {
+	for kind, s := range statuses {
+		if s == status {
+			return kind
+		}
+	}
+	return ""
+}
+
+// For describes err as problem details for a request to instance. Internal
+// errors are described generically so their causes don't leak.
+func For(err error, instance string) *Details {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return &Details{
+			Type:     "about:blank",
+			Title:    http.StatusText(fiberErr.Code),
+			Status:   fiberErr.Code,
+			Detail:   fiberErr.Message,
+			Instance: instance,
+			Code:     kindOfStatus(fiberErr.Code),
+		}
+	}
+
+	e := errs.From(err)
+	status, ok := statuses[e.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+	details := &Details{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   err.Error(),
+		Instance: instance,
+		Code:     e.Kind,
+		Errors:   e.Fields,
+	}
+	if status == http.StatusInternalServerError {
+		details.Detail = "Internal server error"
+		details.Code = errs.KindInternal
+	}
+	return details
+}
+
+// Handler is a fiber.ErrorHandler that renders every error returned by a
+// handler or middleware as problem details, logging internal ones
+func Handler(c *fiber.Ctx, err error) error {
+	details := For(err, c.Path())
+	if details.Status >= http.StatusInternalServerError && details.Code != errs.KindUpstream {
+		log.Printf("%s %s: %v", c.Method(), c.Path(), err)
+	}
+	if retryAfter := errs.From(err).RetryAfter; retryAfter > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	return c.Status(details.Status).JSON(details, ContentType)
+}
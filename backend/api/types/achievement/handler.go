@@ -0,0 +1,36 @@
+package http
+
+import (
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AchievementHandler serves the signed-in user's badges
+type AchievementHandler struct {
+	Achievements *service.AchievementService
+}
+
+// NewAchievementHandler creates a new achievement handler instance
+func NewAchievementHandler(achievements *service.AchievementService) *AchievementHandler {
+	return &AchievementHandler{Achievements: achievements}
+}
+
+// RegisterRoutes registers achievement routes with Fiber, all behind requireAuth
+func (h *AchievementHandler) RegisterRoutes(app *fiber.App, requireAuth fiber.Handler) {
+	app.Get("/api/me/achievements", requireAuth, h.ListAchievements)
+}
+
+// ListAchievements returns every badge with whether the user earned it and
+// their progress towards it
+func (h *AchievementHandler) ListAchievements(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	achievements, err := h.Achievements.For(c.UserContext(), user, time.Now())
+	if err != nil {
+		return err
+	}
+	return c.JSON(achievements)
+}
@@ -0,0 +1,22 @@
+package types
+
+import (
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// LogActivityRequest records an activity. OccurredAt defaults to now and
+// Region, an ISO 3166-1 alpha-2 code, selects regional emission factors.
+type LogActivityRequest struct {
+	Category   string    `json:"category"`
+	Quantity   float64   `json:"quantity"`
+	Region     string    `json:"region"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// EmissionFactorCatalogue is a catalogue version with its factors
+type EmissionFactorCatalogue struct {
+	Version *domain.EmissionFactorVersion `json:"version"`
+	Factors []*domain.EmissionFactor      `json:"factors"`
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/aygoko/EcoMInd/backend/api/types"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CurrentFactors lists the emission factors new activities are computed with
+func (h *ActivityHandler) CurrentFactors(c *fiber.Ctx) error {
+	version, factors, err := h.Factors.Current(c.UserContext())
+	if errors.Is(err, domain.ErrFactorVersionNotFound) {
+		return errs.NotFound("no emission factors published yet")
+	} else if err != nil {
+		return err
+	}
+	return c.JSON(types.EmissionFactorCatalogue{Version: version, Factors: factors})
+}
+
+// ListFactorVersions lists every catalogue version, drafts included
+func (h *ActivityHandler) ListFactorVersions(c *fiber.Ctx) error {
+	versions, err := h.Factors.ListVersions(c.UserContext())
+	if err != nil {
+		return err
+	}
+	return c.JSON(versions)
+}
+
+// ImportFactorVersion stores the uploaded CSV or JSON file, chosen by
+// Content-Type, as a draft version. The note query parameter describes it.
+func (h *ActivityHandler) ImportFactorVersion(c *fiber.Ctx) error {
+	format := "json"
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), "text/csv") {
+		format = "csv"
+	}
+	factors, err := service.ParseEmissionFactors(bytes.NewReader(c.Body()), format)
+	if err != nil {
+		return err
+	}
+
+	version, err := h.Factors.Import(c.UserContext(), c.Query("note"), factors)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusCreated).JSON(version)
+}
+
+// GetFactorVersion returns a catalogue version with its factors
+func (h *ActivityHandler) GetFactorVersion(c *fiber.Ctx) error {
+	number, err := c.ParamsInt("version")
+	if err != nil {
+		return domain.ErrFactorVersionNotFound
+	}
+	version, factors, err := h.Factors.GetVersion(c.UserContext(), number)
+	if err != nil {
+		return err
+	}
+	return c.JSON(types.EmissionFactorCatalogue{Version: version, Factors: factors})
+}
+
+// PublishFactorVersion makes a draft the current catalogue version
+func (h *ActivityHandler) PublishFactorVersion(c *fiber.Ctx) error {
+	number, err := c.ParamsInt("version")
+	if err != nil {
+		return domain.ErrFactorVersionNotFound
+	}
+	err = h.Factors.Publish(c.UserContext(), number)
+	if err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
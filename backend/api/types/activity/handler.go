@@ -0,0 +1,118 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/api/types"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+// ActivityHandler handles the carbon footprint activity log and the
+// emission-factor catalogue it is computed with
+type ActivityHandler struct {
+	Activities *service.ActivityService
+	Factors    *service.EmissionFactorService
+}
+
+// NewActivityHandler creates a new activity handler instance
+func NewActivityHandler(activities *service.ActivityService, factors *service.EmissionFactorService) *ActivityHandler {
+	return &ActivityHandler{
+		Activities: activities,
+		Factors:    factors,
+	}
+}
+
+// RegisterRoutes registers activity routes with Fiber. The activity log is
+// only reachable through requireAuth, catalogue management also through
+// requireAdmin.
+func (h *ActivityHandler) RegisterRoutes(app *fiber.App, requireAuth, requireAdmin fiber.Handler) {
+	apiGroup := app.Group("/api")
+	apiGroup.Get("/emission-factors", h.CurrentFactors)
+
+	adminGroup := apiGroup.Group("/admin/emission-factors", requireAuth, requireAdmin)
+	adminGroup.Get("/versions", h.ListFactorVersions)
+	adminGroup.Post("/versions", h.ImportFactorVersion)
+	adminGroup.Get("/versions/:version", h.GetFactorVersion)
+	adminGroup.Post("/versions/:version/publish", h.PublishFactorVersion)
+
+	activityGroup := apiGroup.Group("/activities", requireAuth)
+	activityGroup.Post("/", h.LogActivity)
+	activityGroup.Get("/", h.ListActivities)
+	activityGroup.Get("/:id", h.GetActivity)
+	activityGroup.Delete("/:id", h.DeleteActivity)
+}
+
+// LogActivity records an activity for the signed-in user
+func (h *ActivityHandler) LogActivity(c *fiber.Ctx) error {
+	var req types.LogActivityRequest
+	if err := c.BodyParser(&req); err != nil || req.Category == "" {
+		return errs.Invalid("category", "category and quantity are required")
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	activity, err := h.Activities.Log(c.UserContext(), user.ID, req.Category, req.Region, req.Quantity, req.OccurredAt)
+	if errors.Is(err, domain.ErrEmissionFactorNotFound) {
+		// The category comes from the request, so an unknown one is a bad request
+		return errs.WithKind(errs.KindValidation, err)
+	} else if err != nil {
+		return err
+	}
+	return c.Status(http.StatusCreated).JSON(activity)
+}
+
+// ListActivities lists the signed-in user's activities, optionally between
+// the RFC 3339 times from and to
+func (h *ActivityHandler) ListActivities(c *fiber.Ctx) error {
+	filter := domain.ActivityFilter{Limit: c.QueryInt("limit", defaultListLimit)}
+	if filter.Limit < 1 || filter.Limit > maxListLimit {
+		return errs.Invalid("limit", "limit must be between 1 and "+strconv.Itoa(maxListLimit))
+	}
+	for name, target := range map[string]*time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := c.Query(name); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return errs.Invalid(name, name+" must be an RFC 3339 time")
+			}
+			*target = t
+		}
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	activities, err := h.Activities.List(c.UserContext(), user.ID, filter)
+	if err != nil {
+		return err
+	}
+	return c.JSON(activities)
+}
+
+// GetActivity returns one of the signed-in user's activities
+func (h *ActivityHandler) GetActivity(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	activity, err := h.Activities.Get(c.UserContext(), user.ID, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(activity)
+}
+
+// DeleteActivity removes one of the signed-in user's activities
+func (h *ActivityHandler) DeleteActivity(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	err := h.Activities.Delete(c.UserContext(), user.ID, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
@@ -0,0 +1,6 @@
+package types
+
+// CoachMessageRequest is a question to the sustainability coach
+type CoachMessageRequest struct {
+	Message string `json:"message"`
+}
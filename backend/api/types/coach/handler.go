@@ -0,0 +1,67 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/api/problem"
+	"github.com/aygoko/EcoMInd/backend/api/types"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultHistoryLimit = 20
+	maxHistoryLimit     = 100
+)
+
+// CoachHandler serves the sustainability coach chat
+type CoachHandler struct {
+	Coach *service.CoachService
+}
+
+// NewCoachHandler creates a new coach handler instance
+func NewCoachHandler(coach *service.CoachService) *CoachHandler {
+	return &CoachHandler{Coach: coach}
+}
+
+// RegisterRoutes registers coach routes with Fiber, all behind requireAuth
+func (h *CoachHandler) RegisterRoutes(app *fiber.App, requireAuth fiber.Handler) {
+	coachGroup := app.Group("/api/coach", requireAuth)
+	coachGroup.Get("/messages", h.ListMessages)
+	coachGroup.Post("/messages", h.SendMessage)
+}
+
+// SendMessage asks the coach a question and returns its answer
+func (h *CoachHandler) SendMessage(c *fiber.Ctx) error {
+	var req types.CoachMessageRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	exchange, err := h.Coach.Ask(c.UserContext(), user, req.Message, time.Now())
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusCreated).JSON(exchange)
+}
+
+// ListMessages returns the user's conversation with the coach, most recent first
+func (h *CoachHandler) ListMessages(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", defaultHistoryLimit)
+	if limit < 1 || limit > maxHistoryLimit {
+		return errs.Invalid("limit", "limit must be between 1 and "+strconv.Itoa(maxHistoryLimit))
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	exchanges, err := h.Coach.History(c.UserContext(), user.ID, limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(exchanges)
+}
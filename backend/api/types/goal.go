@@ -0,0 +1,9 @@
+package types
+
+// SetGoalRequest sets a weekly CO2 reduction goal. Timezone is an IANA name
+// used for new goals, defaulting to UTC; TargetCO2 in kg defaults to a
+// tenth of the weekly survey baseline.
+type SetGoalRequest struct {
+	TargetCO2 *float64 `json:"target_co2"`
+	Timezone  string   `json:"timezone"`
+}
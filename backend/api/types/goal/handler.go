@@ -0,0 +1,89 @@
+package http
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/api/problem"
+	"github.com/aygoko/EcoMInd/backend/api/types"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultHistoryLimit = 12
+	maxHistoryLimit     = 104
+)
+
+// GoalHandler handles the signed-in user's weekly CO2 reduction goals
+type GoalHandler struct {
+	Goals *service.GoalService
+}
+
+// NewGoalHandler creates a new weekly goal handler instance
+func NewGoalHandler(goals *service.GoalService) *GoalHandler {
+	return &GoalHandler{Goals: goals}
+}
+
+// RegisterRoutes registers weekly goal routes with Fiber, all behind requireAuth
+func (h *GoalHandler) RegisterRoutes(app *fiber.App, requireAuth fiber.Handler) {
+	goalGroup := app.Group("/api/me/goals", requireAuth)
+	goalGroup.Get("/", h.ListGoals)
+	goalGroup.Get("/current", h.CurrentGoal)
+	goalGroup.Get("/:week", h.GetGoal)
+	goalGroup.Put("/:week", h.SetGoal)
+}
+
+// ListGoals lists the user's goals with their results, most recent week first
+func (h *GoalHandler) ListGoals(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", defaultHistoryLimit)
+	if limit < 1 || limit > maxHistoryLimit {
+		return errs.Invalid("limit", "limit must be between 1 and "+strconv.Itoa(maxHistoryLimit))
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	goals, err := h.Goals.History(c.UserContext(), user.ID, limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(goals)
+}
+
+// CurrentGoal returns this week's goal with live progress
+func (h *GoalHandler) CurrentGoal(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	goal, err := h.Goals.Current(c.UserContext(), user.ID, time.Now())
+	if err != nil {
+		return err
+	}
+	return c.JSON(goal)
+}
+
+// GetGoal returns the goal of an ISO week like 2026-W42
+func (h *GoalHandler) GetGoal(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	goal, err := h.Goals.Get(c.UserContext(), user.ID, c.Params("week"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(goal)
+}
+
+// SetGoal sets or confirms the goal of this or next ISO week; the week
+// "current" is this week in the request's timezone
+func (h *GoalHandler) SetGoal(c *fiber.Ctx) error {
+	var req types.SetGoalRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	goal, err := h.Goals.Set(c.UserContext(), user.ID, c.Params("week"), req.Timezone, req.TargetCO2, time.Now())
+	if err != nil {
+		return err
+	}
+	return c.JSON(goal)
+}
@@ -0,0 +1,50 @@
+package http
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	defaultPageLimit = 20
+	maxPageLimit     = 100
+)
+
+// LeaderboardHandler serves the global, weekly and friends leaderboards
+type LeaderboardHandler struct {
+	Leaderboard *service.LeaderboardService
+}
+
+// NewLeaderboardHandler creates a new leaderboard handler instance
+func NewLeaderboardHandler(leaderboard *service.LeaderboardService) *LeaderboardHandler {
+	return &LeaderboardHandler{Leaderboard: leaderboard}
+}
+
+// RegisterRoutes registers leaderboard routes with Fiber behind requireAuth,
+// since every page includes the viewer's own standing
+func (h *LeaderboardHandler) RegisterRoutes(app *fiber.App, requireAuth fiber.Handler) {
+	app.Get("/api/leaderboard", requireAuth, h.GetLeaderboard)
+}
+
+// GetLeaderboard returns a page of the leaderboard chosen by scope (global
+// or friends), period (all or week) and metric (points or co2_saved)
+func (h *LeaderboardHandler) GetLeaderboard(c *fiber.Ctx) error {
+	offset := c.QueryInt("offset", 0)
+	limit := c.QueryInt("limit", defaultPageLimit)
+	if offset < 0 || limit < 1 || limit > maxPageLimit {
+		return errs.Validation("offset must not be negative and limit must be between 1 and " + strconv.Itoa(maxPageLimit))
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	page, err := h.Leaderboard.Page(c.UserContext(), user.ID, c.Query("scope"), c.Query("period"), c.Query("metric"), offset, limit, time.Now())
+	if err != nil {
+		return err
+	}
+	return c.JSON(page)
+}
@@ -0,0 +1,68 @@
+package types
+
+import "github.com/aygoko/EcoMInd/backend/domain"
+
+// ProfileResponse is a user as shown to other users and anonymous
+// visitors. Footprint fields and activities are left out when the owner's
+// privacy settings hide them from the viewer; contact details never appear.
+type ProfileResponse struct {
+	Login            string             `json:"login"`
+	Relation         string             `json:"relation"` // self, friends, request_sent, request_received or none
+	Following        bool               `json:"following"`
+	FollowsYou       bool               `json:"follows_you"`
+	Followers        int                `json:"followers"`
+	FollowingCount   int                `json:"following_count"`
+	Points           int                `json:"points"`
+	CO2              *float64           `json:"co2,omitempty"`          // kg CO2e of logged activities
+	CO2Baseline      *float64           `json:"co2_baseline,omitempty"` // estimated annual kg CO2e from the onboarding survey
+	CO2Saved         *float64           `json:"co2_saved,omitempty"`    // estimated kg CO2e saved by completed tasks
+	RecentActivities []*domain.Activity `json:"recent_activities,omitempty"`
+}
+
+// AccountResponse is the signed-in user's own account: their profile with
+// everything visible, plus the private details only they may see
+type AccountResponse struct {
+	ID            string                  `json:"id"`
+	Email         string                  `json:"email"`
+	EmailVerified bool                    `json:"email_verified"`
+	PhoneNumber   string                  `json:"phone_number"`
+	Privacy       *domain.PrivacySettings `json:"privacy"`
+	ProfileResponse
+}
+
+// UserResponse serializes a user for the API and is the only place that
+// does; domain.User's own JSON is its cache encoding and includes contact
+// details. The owner gets an AccountResponse, anyone else a ProfileResponse
+// with only what the owner's privacy settings show them.
+func UserResponse(profile *domain.Profile) interface{} {
+	user := profile.User
+	public := ProfileResponse{
+		Login:          user.Login,
+		Relation:       profile.Relation,
+		Following:      profile.Following,
+		FollowsYou:     profile.FollowsYou,
+		Followers:      profile.Followers,
+		FollowingCount: profile.FollowingCount,
+		Points:         user.Points,
+	}
+	if profile.Shows(profile.Privacy.CO2) {
+		co2, baseline, saved := user.CO2, user.CO2Baseline, user.CO2Saved
+		public.CO2, public.CO2Baseline, public.CO2Saved = &co2, &baseline, &saved
+	}
+	if profile.Shows(profile.Privacy.Activity) {
+		public.RecentActivities = profile.RecentActivities
+	}
+
+	if profile.Relation != domain.ProfileSelf {
+		return &public
+	}
+	privacy := profile.Privacy
+	return &AccountResponse{
+		ID:              user.ID,
+		Email:           user.Email,
+		EmailVerified:   user.EmailVerified,
+		PhoneNumber:     user.PhoneNumber,
+		Privacy:         &privacy,
+		ProfileResponse: public,
+	}
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RecommendationHandler serves the signed-in user's reduction recommendations
+type RecommendationHandler struct {
+	Recommendations *service.RecommendationService
+}
+
+// NewRecommendationHandler creates a new recommendation handler instance
+func NewRecommendationHandler(recommendations *service.RecommendationService) *RecommendationHandler {
+	return &RecommendationHandler{Recommendations: recommendations}
+}
+
+// RegisterRoutes registers recommendation routes with Fiber, all behind requireAuth
+func (h *RecommendationHandler) RegisterRoutes(app *fiber.App, requireAuth fiber.Handler) {
+	recommendationGroup := app.Group("/api/me/recommendations", requireAuth)
+	recommendationGroup.Get("/", h.ListRecommendations)
+	recommendationGroup.Post("/:id/dismiss", h.DismissRecommendation)
+}
+
+// ListRecommendations returns suggested actions, best first, with explanations
+func (h *RecommendationHandler) ListRecommendations(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	recommendations, err := h.Recommendations.For(c.UserContext(), user.ID, time.Now())
+	if err != nil {
+		return err
+	}
+	return c.JSON(recommendations)
+}
+
+// DismissRecommendation stops a recommendation from being suggested again
+func (h *RecommendationHandler) DismissRecommendation(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	err := h.Recommendations.Dismiss(c.UserContext(), user.ID, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
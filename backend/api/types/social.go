@@ -0,0 +1,16 @@
+package types
+
+import "github.com/aygoko/EcoMInd/backend/domain"
+
+// PrivacyRequest changes who sees a user's footprint and activity. Levels
+// are public, friends or private; empty ones stay as they are.
+type PrivacyRequest struct {
+	CO2      string `json:"co2"`
+	Activity string `json:"activity"`
+}
+
+// FriendRequestsResponse lists pending friend requests by direction
+type FriendRequestsResponse struct {
+	Incoming []*domain.Contact `json:"incoming"`
+	Outgoing []*domain.Contact `json:"outgoing"`
+}
@@ -0,0 +1,200 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/aygoko/EcoMInd/backend/api/problem"
+	"github.com/aygoko/EcoMInd/backend/api/types"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SocialHandler handles the signed-in user's friends, follows, blocks and
+// privacy settings
+type SocialHandler struct {
+	Social *service.SocialService
+}
+
+// NewSocialHandler creates a new social handler instance
+func NewSocialHandler(social *service.SocialService) *SocialHandler {
+	return &SocialHandler{Social: social}
+}
+
+// RegisterRoutes registers social routes with Fiber, all behind requireAuth.
+// Other users are addressed by login.
+func (h *SocialHandler) RegisterRoutes(app *fiber.App, requireAuth fiber.Handler) {
+	meGroup := app.Group("/api/me", requireAuth)
+
+	meGroup.Get("/friends", h.ListFriends)
+	meGroup.Get("/friends/requests", h.ListFriendRequests)
+	meGroup.Post("/friends/:login", h.RequestFriend)
+	meGroup.Post("/friends/:login/accept", h.AcceptFriend)
+	meGroup.Post("/friends/:login/decline", h.DeclineFriend)
+	meGroup.Delete("/friends/:login", h.RemoveFriend)
+
+	meGroup.Get("/blocks", h.ListBlocked)
+	meGroup.Put("/blocks/:login", h.Block)
+	meGroup.Delete("/blocks/:login", h.Unblock)
+
+	meGroup.Get("/followers", h.ListFollowers)
+	meGroup.Get("/following", h.ListFollowing)
+	meGroup.Put("/following/:login", h.Follow)
+	meGroup.Delete("/following/:login", h.Unfollow)
+
+	meGroup.Get("/privacy", h.GetPrivacy)
+	meGroup.Put("/privacy", h.SetPrivacy)
+}
+
+// ListFriends lists the user's friends, most recent first
+func (h *SocialHandler) ListFriends(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	friends, err := h.Social.Friends(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(friends)
+}
+
+// ListFriendRequests lists the friend requests the user received and sent
+func (h *SocialHandler) ListFriendRequests(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	incoming, outgoing, err := h.Social.Requests(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(types.FriendRequestsResponse{Incoming: incoming, Outgoing: outgoing})
+}
+
+// RequestFriend sends a friend request, accepting theirs if they sent one
+func (h *SocialHandler) RequestFriend(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	relation, err := h.Social.RequestFriend(c.UserContext(), user.ID, c.Params("login"))
+	if err != nil {
+		return err
+	}
+	if relation.Status == domain.RelationFriends {
+		return c.JSON(fiber.Map{"status": relation.Status})
+	}
+	return c.Status(http.StatusCreated).JSON(fiber.Map{"status": relation.Status})
+}
+
+// AcceptFriend accepts a received friend request
+func (h *SocialHandler) AcceptFriend(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	relation, err := h.Social.AcceptFriend(c.UserContext(), user.ID, c.Params("login"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(fiber.Map{"status": relation.Status})
+}
+
+// DeclineFriend turns down a received friend request
+func (h *SocialHandler) DeclineFriend(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.Social.DeclineFriend(c.UserContext(), user.ID, c.Params("login")); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// RemoveFriend unfriends a user or withdraws a sent request
+func (h *SocialHandler) RemoveFriend(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.Social.RemoveFriend(c.UserContext(), user.ID, c.Params("login")); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// ListBlocked lists the users the user blocked
+func (h *SocialHandler) ListBlocked(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	blocked, err := h.Social.Blocked(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(blocked)
+}
+
+// Block blocks a user, ending any friendship and follows between them
+func (h *SocialHandler) Block(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.Social.Block(c.UserContext(), user.ID, c.Params("login")); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// Unblock lifts a block
+func (h *SocialHandler) Unblock(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.Social.Unblock(c.UserContext(), user.ID, c.Params("login")); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// ListFollowers lists who follows the user
+func (h *SocialHandler) ListFollowers(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	followers, err := h.Social.Followers(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(followers)
+}
+
+// ListFollowing lists whom the user follows
+func (h *SocialHandler) ListFollowing(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	following, err := h.Social.Following(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(following)
+}
+
+// Follow follows a user
+func (h *SocialHandler) Follow(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.Social.Follow(c.UserContext(), user.ID, c.Params("login")); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// Unfollow stops following a user
+func (h *SocialHandler) Unfollow(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.Social.Unfollow(c.UserContext(), user.ID, c.Params("login")); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// GetPrivacy returns the user's privacy settings
+func (h *SocialHandler) GetPrivacy(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	settings, err := h.Social.Privacy(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(settings)
+}
+
+// SetPrivacy changes the user's privacy settings
+func (h *SocialHandler) SetPrivacy(c *fiber.Ctx) error {
+	var req types.PrivacyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	settings, err := h.Social.SetPrivacy(c.UserContext(), user.ID, domain.PrivacySettings{CO2: req.CO2, Activity: req.Activity})
+	if err != nil {
+		return err
+	}
+	return c.JSON(settings)
+}
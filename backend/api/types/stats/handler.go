@@ -0,0 +1,45 @@
+package http
+
+import (
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// StatsHandler serves the signed-in user's footprint statistics
+type StatsHandler struct {
+	Stats *service.StatsService
+}
+
+// NewStatsHandler creates a new stats handler instance
+func NewStatsHandler(stats *service.StatsService) *StatsHandler {
+	return &StatsHandler{Stats: stats}
+}
+
+// RegisterRoutes registers stats routes with Fiber, all behind requireAuth
+func (h *StatsHandler) RegisterRoutes(app *fiber.App, requireAuth fiber.Handler) {
+	app.Get("/api/me/stats", requireAuth, h.GetStats)
+}
+
+// GetStats returns the CO2 time series by period (day, week or month),
+// category breakdown, comparisons and streaks. from and to are dates in
+// the IANA timezone tz; region picks the national average to compare with.
+func (h *StatsHandler) GetStats(c *fiber.Ctx) error {
+	query := service.StatsQuery{
+		Period:   c.Query("period"),
+		Timezone: c.Query("tz"),
+		From:     c.Query("from"),
+		To:       c.Query("to"),
+		Region:   c.Query("region"),
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	stats, err := h.Stats.For(c.UserContext(), user, query, time.Now())
+	if err != nil {
+		return err
+	}
+	return c.JSON(stats)
+}
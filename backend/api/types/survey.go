@@ -0,0 +1,8 @@
+package types
+
+import "github.com/aygoko/EcoMInd/backend/domain"
+
+// SubmitSurveyRequest answers a survey, keyed by question ID
+type SubmitSurveyRequest struct {
+	Answers map[string]domain.Answer `json:"answers"`
+}
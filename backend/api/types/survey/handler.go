@@ -0,0 +1,65 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/aygoko/EcoMInd/backend/api/types"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// SurveyHandler serves footprint surveys and scores the answers
+type SurveyHandler struct {
+	Surveys *service.SurveyService
+}
+
+// NewSurveyHandler creates a new survey handler instance
+func NewSurveyHandler(surveys *service.SurveyService) *SurveyHandler {
+	return &SurveyHandler{Surveys: surveys}
+}
+
+// RegisterRoutes registers survey routes with Fiber. Definitions are
+// public, responses are only reachable through requireAuth.
+func (h *SurveyHandler) RegisterRoutes(app *fiber.App, requireAuth fiber.Handler) {
+	surveyGroup := app.Group("/api/surveys")
+	surveyGroup.Get("/:id", h.GetSurvey)
+	surveyGroup.Post("/:id/responses", requireAuth, h.SubmitSurvey)
+	surveyGroup.Get("/:id/responses/latest", requireAuth, h.LatestResponse)
+}
+
+// GetSurvey returns a survey's questions
+func (h *SurveyHandler) GetSurvey(c *fiber.Ctx) error {
+	survey, err := h.Surveys.Get(c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(survey)
+}
+
+// SubmitSurvey scores the signed-in user's answers into their CO2 baseline
+func (h *SurveyHandler) SubmitSurvey(c *fiber.Ctx) error {
+	var req types.SubmitSurveyRequest
+	if err := c.BodyParser(&req); err != nil || req.Answers == nil {
+		return errs.Invalid("answers", "answers are required")
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	response, err := h.Surveys.Submit(c.UserContext(), user.ID, c.Params("id"), req.Answers)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusCreated).JSON(response)
+}
+
+// LatestResponse returns the signed-in user's latest answers and result
+func (h *SurveyHandler) LatestResponse(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	response, err := h.Surveys.Latest(c.UserContext(), user.ID, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(response)
+}
@@ -0,0 +1,20 @@
+package types
+
+// TaskRequest creates or replaces a catalogue task. Active defaults to true
+// on create and Repeat to weekly.
+type TaskRequest struct {
+	Title       string  `json:"title"`
+	Description string  `json:"description"`
+	Category    string  `json:"category"`
+	CO2Saved    float64 `json:"co2_saved"`
+	Difficulty  string  `json:"difficulty"`
+	Points      int     `json:"points"`
+	Repeat      string  `json:"repeat"`
+	Active      *bool   `json:"active"`
+}
+
+// CompleteTaskRequest completes an assigned task with an optional proof,
+// a note or photo URL
+type CompleteTaskRequest struct {
+	Proof string `json:"proof"`
+}
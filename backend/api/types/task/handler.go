@@ -0,0 +1,148 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/aygoko/EcoMInd/backend/api/problem"
+	"github.com/aygoko/EcoMInd/backend/api/types"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TaskHandler handles the eco task catalogue and users' assigned tasks
+type TaskHandler struct {
+	Tasks *service.TaskService
+}
+
+// NewTaskHandler creates a new task handler instance
+func NewTaskHandler(tasks *service.TaskService) *TaskHandler {
+	return &TaskHandler{Tasks: tasks}
+}
+
+// RegisterRoutes registers task routes with Fiber. The catalogue is public,
+// assignments need requireAuth and catalogue edits also requireAdmin.
+func (h *TaskHandler) RegisterRoutes(app *fiber.App, requireAuth, requireAdmin fiber.Handler) {
+	taskGroup := app.Group("/api/tasks")
+	taskGroup.Get("/", h.ListTasks)
+	taskGroup.Post("/", requireAuth, requireAdmin, h.CreateTask)
+
+	// Registered before /:id so "assignments" isn't taken for a task ID
+	taskGroup.Get("/assignments", requireAuth, h.ListAssignments)
+	taskGroup.Get("/assignments/:id", requireAuth, h.GetAssignment)
+	taskGroup.Post("/assignments/:id/complete", requireAuth, h.CompleteAssignment)
+
+	taskGroup.Get("/:id", h.GetTask)
+	taskGroup.Put("/:id", requireAuth, requireAdmin, h.UpdateTask)
+	taskGroup.Post("/:id/assign", requireAuth, h.AssignTask)
+}
+
+// ListTasks lists active catalogue tasks, optionally by category and difficulty
+func (h *TaskHandler) ListTasks(c *fiber.Ctx) error {
+	tasks, err := h.Tasks.Catalogue(c.UserContext(), c.Query("category"), c.Query("difficulty"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(tasks)
+}
+
+// GetTask returns a catalogue task
+func (h *TaskHandler) GetTask(c *fiber.Ctx) error {
+	task, err := h.Tasks.GetTask(c.UserContext(), c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(task)
+}
+
+// CreateTask adds a task to the catalogue
+func (h *TaskHandler) CreateTask(c *fiber.Ctx) error {
+	var req types.TaskRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
+	}
+	task := taskFromRequest(&req)
+	if err := h.Tasks.CreateTask(c.UserContext(), task); err != nil {
+		return err
+	}
+	return c.Status(http.StatusCreated).JSON(task)
+}
+
+// UpdateTask replaces a catalogue task; set active to false to retire it
+func (h *TaskHandler) UpdateTask(c *fiber.Ctx) error {
+	var req types.TaskRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
+	}
+	task := taskFromRequest(&req)
+	task.ID = c.Params("id")
+	if err := h.Tasks.UpdateTask(c.UserContext(), task); err != nil {
+		return err
+	}
+	return c.JSON(task)
+}
+
+// AssignTask gives a catalogue task to the signed-in user
+func (h *TaskHandler) AssignTask(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	assignment, err := h.Tasks.Assign(c.UserContext(), user.ID, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusCreated).JSON(assignment)
+}
+
+// ListAssignments lists the signed-in user's tasks, optionally by status
+func (h *TaskHandler) ListAssignments(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	assignments, err := h.Tasks.Assignments(c.UserContext(), user.ID, c.Query("status"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(assignments)
+}
+
+// GetAssignment returns one of the signed-in user's tasks
+func (h *TaskHandler) GetAssignment(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	assignment, err := h.Tasks.GetAssignment(c.UserContext(), user.ID, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(assignment)
+}
+
+// CompleteAssignment completes one of the signed-in user's tasks
+func (h *TaskHandler) CompleteAssignment(c *fiber.Ctx) error {
+	var req types.CompleteTaskRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return problem.ErrInvalidBody
+		}
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	assignment, err := h.Tasks.Complete(c.UserContext(), user.ID, c.Params("id"), req.Proof)
+	if err != nil {
+		return err
+	}
+	return c.JSON(assignment)
+}
+
+func taskFromRequest(req *types.TaskRequest) *domain.Task {
+	task := &domain.Task{
+		Title:       req.Title,
+		Description: req.Description,
+		Category:    req.Category,
+		CO2Saved:    req.CO2Saved,
+		Difficulty:  req.Difficulty,
+		Points:      req.Points,
+		Repeat:      req.Repeat,
+		Active:      true,
+	}
+	if req.Active != nil {
+		task.Active = *req.Active
+	}
+	return task
+}
@@ -0,0 +1,41 @@
+package types
+
+import "time"
+
+// CreateTeamRequest creates a team owned by the signed-in user
+type CreateTeamRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// UpdateTeamRequest changes a team; omitted fields stay as they are
+type UpdateTeamRequest struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+}
+
+// JoinTeamRequest joins a team with its invite code
+type JoinTeamRequest struct {
+	Code string `json:"code"`
+}
+
+// InviteRequest invites a user to a team by login
+type InviteRequest struct {
+	Login string `json:"login"`
+}
+
+// SetRoleRequest changes a team member's role to owner, admin or member
+type SetRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// CreateChallengeRequest starts a team challenge. Metric is points or
+// co2_saved, the default; Target is in its unit and StartsAt defaults to now.
+type CreateChallengeRequest struct {
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Metric      string    `json:"metric"`
+	Target      float64   `json:"target"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+}
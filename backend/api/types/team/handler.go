@@ -0,0 +1,261 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/api/problem"
+	"github.com/aygoko/EcoMInd/backend/api/types"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TeamHandler handles teams, their members and challenges
+type TeamHandler struct {
+	Teams *service.TeamService
+}
+
+// NewTeamHandler creates a new team handler instance
+func NewTeamHandler(teams *service.TeamService) *TeamHandler {
+	return &TeamHandler{Teams: teams}
+}
+
+// RegisterRoutes registers team routes with Fiber, all behind requireAuth.
+// Members are addressed by login.
+func (h *TeamHandler) RegisterRoutes(app *fiber.App, requireAuth fiber.Handler) {
+	teamGroup := app.Group("/api/teams", requireAuth)
+	teamGroup.Get("/", h.ListTeams)
+	teamGroup.Post("/", h.CreateTeam)
+	teamGroup.Post("/join", h.JoinTeam)
+	teamGroup.Get("/invites", h.ListInvites)
+	teamGroup.Post("/invites/:id/accept", h.AcceptInvite)
+	teamGroup.Delete("/invites/:id", h.DeclineInvite)
+
+	teamGroup.Get("/:id", h.GetTeam)
+	teamGroup.Patch("/:id", h.UpdateTeam)
+	teamGroup.Delete("/:id", h.DeleteTeam)
+	teamGroup.Post("/:id/code", h.RotateCode)
+
+	teamGroup.Get("/:id/members", h.ListMembers)
+	teamGroup.Post("/:id/invites", h.Invite)
+	teamGroup.Put("/:id/members/:login", h.SetRole)
+	teamGroup.Delete("/:id/members/:login", h.RemoveMember)
+
+	teamGroup.Get("/:id/challenges", h.ListChallenges)
+	teamGroup.Post("/:id/challenges", h.CreateChallenge)
+	teamGroup.Get("/:id/challenges/:challengeID", h.GetChallenge)
+	teamGroup.Delete("/:id/challenges/:challengeID", h.DeleteChallenge)
+}
+
+// ListTeams lists the user's teams with their totals
+func (h *TeamHandler) ListTeams(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	teams, err := h.Teams.List(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(teams)
+}
+
+// CreateTeam creates a team owned by the user
+func (h *TeamHandler) CreateTeam(c *fiber.Ctx) error {
+	var req types.CreateTeamRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	team, err := h.Teams.Create(c.UserContext(), user.ID, req.Name, req.Description)
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusCreated).JSON(team)
+}
+
+// JoinTeam joins a team with its invite code
+func (h *TeamHandler) JoinTeam(c *fiber.Ctx) error {
+	var req types.JoinTeamRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	team, err := h.Teams.Join(c.UserContext(), user.ID, req.Code)
+	if err != nil {
+		return err
+	}
+	return c.JSON(team)
+}
+
+// ListInvites lists the user's pending team invites
+func (h *TeamHandler) ListInvites(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	invites, err := h.Teams.Invites(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(invites)
+}
+
+// AcceptInvite joins the team of an invite
+func (h *TeamHandler) AcceptInvite(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	team, err := h.Teams.AcceptInvite(c.UserContext(), user.ID, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(team)
+}
+
+// DeclineInvite turns down a team invite
+func (h *TeamHandler) DeclineInvite(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.Teams.DeclineInvite(c.UserContext(), user.ID, c.Params("id")); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// GetTeam returns a team with its totals
+func (h *TeamHandler) GetTeam(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	team, err := h.Teams.Get(c.UserContext(), user.ID, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(team)
+}
+
+// UpdateTeam changes a team's name or description
+func (h *TeamHandler) UpdateTeam(c *fiber.Ctx) error {
+	var req types.UpdateTeamRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	team, err := h.Teams.Update(c.UserContext(), user.ID, c.Params("id"), req.Name, req.Description)
+	if err != nil {
+		return err
+	}
+	return c.JSON(team)
+}
+
+// DeleteTeam deletes a team
+func (h *TeamHandler) DeleteTeam(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.Teams.Delete(c.UserContext(), user.ID, c.Params("id")); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// RotateCode replaces a team's invite code
+func (h *TeamHandler) RotateCode(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	team, err := h.Teams.RotateCode(c.UserContext(), user.ID, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(team)
+}
+
+// ListMembers lists a team's members with their totals
+func (h *TeamHandler) ListMembers(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	members, err := h.Teams.Members(c.UserContext(), user.ID, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.JSON(members)
+}
+
+// Invite invites a user to a team
+func (h *TeamHandler) Invite(c *fiber.Ctx) error {
+	var req types.InviteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	if _, err := h.Teams.Invite(c.UserContext(), user.ID, c.Params("id"), req.Login); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// SetRole changes a member's role
+func (h *TeamHandler) SetRole(c *fiber.Ctx) error {
+	var req types.SetRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.Teams.SetRole(c.UserContext(), user.ID, c.Params("id"), c.Params("login"), req.Role); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// RemoveMember removes a member from a team; removing oneself leaves it
+func (h *TeamHandler) RemoveMember(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.Teams.RemoveMember(c.UserContext(), user.ID, c.Params("id"), c.Params("login")); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// ListChallenges lists a team's challenges with their progress
+func (h *TeamHandler) ListChallenges(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	challenges, err := h.Teams.Challenges(c.UserContext(), user.ID, c.Params("id"), time.Now())
+	if err != nil {
+		return err
+	}
+	return c.JSON(challenges)
+}
+
+// CreateChallenge starts a team challenge
+func (h *TeamHandler) CreateChallenge(c *fiber.Ctx) error {
+	var req types.CreateChallengeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
+	}
+
+	user := domain.UserFromContext(c.UserContext())
+	challenge, err := h.Teams.CreateChallenge(c.UserContext(), user.ID, c.Params("id"), &domain.Challenge{
+		Title:       req.Title,
+		Description: req.Description,
+		Metric:      req.Metric,
+		Target:      req.Target,
+		StartsAt:    req.StartsAt,
+		EndsAt:      req.EndsAt,
+	}, time.Now())
+	if err != nil {
+		return err
+	}
+	return c.Status(http.StatusCreated).JSON(challenge)
+}
+
+// GetChallenge returns a challenge with its progress and leaderboard
+func (h *TeamHandler) GetChallenge(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	challenge, err := h.Teams.Challenge(c.UserContext(), user.ID, c.Params("id"), c.Params("challengeID"), time.Now())
+	if err != nil {
+		return err
+	}
+	return c.JSON(challenge)
+}
+
+// DeleteChallenge deletes a challenge
+func (h *TeamHandler) DeleteChallenge(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.Teams.DeleteChallenge(c.UserContext(), user.ID, c.Params("id"), c.Params("challengeID")); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
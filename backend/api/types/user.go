@@ -1,77 +1,67 @@
 package types
 
-import (
-	"errors"
+import "github.com/aygoko/EcoMInd/backend/domain"
 
-	"github.com/aygoko/EcoMInd/backend/repository"
-	"github.com/aygoko/EcoMInd/backend/usecases/service"
-	"golang.org/x/crypto/bcrypt"
-)
-
-func (s *repository.UserService) CreateUser(login, email, phone_number, password string) (*repository.User, error) {
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, err
-	}
-
-	if login == "" || email == "" || phone_number == "" {
-		return nil, errors.New("login and email and phone_number are required")
-	}
+// CreateUserRequest is the registration payload. domain.User never decodes a
+// password, so registration needs its own type.
+type CreateUserRequest struct {
+	Login       string `json:"login"`
+	Email       string `json:"email"`
+	PhoneNumber string `json:"phone_number"`
+	Password    string `json:"password"`
+}
 
-	if _, exists := s.Users[login]; exists {
-		return nil, errors.New("user with this login already exists")
-	}
+// UpdateUserRequest changes the signed-in user's contact details. Omitted
+// fields stay as they are.
+type UpdateUserRequest struct {
+	Email       *string `json:"email"`
+	PhoneNumber *string `json:"phone_number"`
+}
 
-	if existingLogin, exists := s.Emails[email]; exists {
-		return nil, errors.New("email already in use by " + existingLogin)
-	}
+// VerifyEmailRequest redeems the token of an email verification link
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
 
-	if existingLogin, exists := s.PhoneNumbers[phone_number]; exists {
-		return nil, errors.New("phone_number already in use by " + existingLogin)
-	}
+// PasswordResetRequest asks for a password reset link sent to Email
+type PasswordResetRequest struct {
+	Email string `json:"email"`
+}
 
-	user := &repository.User{
-		ID:          service.GenerateUserID(),
-		Login:       login,
-		Email:       email,
-		PhoneNumber: phone_number,
-		Password:    string(hashedPassword),
-	}
+// ResetPasswordRequest sets a new password with the token of a password
+// reset link
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
 
-	s.Users[login] = user
-	s.Emails[email] = login
+// LoginRequest signs a user in with a password. Identifier may be the
+// login, email or phone number.
+type LoginRequest struct {
+	Identifier string `json:"identifier"`
+	Password   string `json:"password"`
+}
 
-	return user, nil
+// TokenResponse carries the tokens issued on sign-in
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"` // access token lifetime in seconds
 }
 
-func (s *repository.UserService) GetUserByLogin(login string) (*repository.User, error) {
-	user, exists := s.Users[login]
-	if !exists {
-		return nil, errors.New("user not found")
-	}
-	return user, nil
+// RefreshRequest carries a refresh token to rotate or revoke
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
-func (s *repository.UserService) GetUserByEmail(email string) (*repository.User, error) {
-	login, exists := s.Emails[email]
-	if !exists {
-		return nil, errors.New("user not found")
-	}
-	user, exists := s.Users[login]
-	if !exists {
-		return nil, errors.New("user not found")
-	}
-	return user, nil
+// SessionResponse is a signed-in device as listed to its owner
+type SessionResponse struct {
+	*domain.Session
+	Current bool `json:"current"` // the session of the requesting access token
 }
 
-func (s *repository.UserService) GetUserByPhoneNumber(phone_number string) (*repository.User, error) {
-	login, exists := s.PhoneNumbers[phone_number]
-	if !exists {
-		return nil, errors.New("user not found")
-	}
-	user, exists := s.Users[login]
-	if !exists {
-		return nil, errors.New("user not found")
-	}
-	return user, nil
+// AuthorizationResponse points the client at a provider's consent page
+type AuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
 }
@@ -0,0 +1,76 @@
+package http
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/aygoko/EcoMInd/backend/api/problem"
+	"github.com/aygoko/EcoMInd/backend/api/types"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestVerification emails the signed-in user a link confirming their
+// email address
+func (h *UserHandler) RequestVerification(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.Accounts.RequestVerification(c.UserContext(), user); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusAccepted)
+}
+
+// VerifyEmail redeems an email verification link
+func (h *UserHandler) VerifyEmail(c *fiber.Ctx) error {
+	var req types.VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return errs.Invalid("token", "token is required")
+	}
+	if _, err := h.Accounts.VerifyEmail(c.UserContext(), req.Token); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// RequestPasswordReset emails a password reset link to the address if it
+// belongs to a user. The response is the same either way, including when
+// the email can't be sent.
+func (h *UserHandler) RequestPasswordReset(c *fiber.Ctx) error {
+	var req types.PasswordResetRequest
+	if err := c.BodyParser(&req); err != nil || req.Email == "" {
+		return errs.Invalid("email", "email is required")
+	}
+	if err := h.Accounts.RequestPasswordReset(c.UserContext(), req.Email); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusAccepted)
+}
+
+// ResetPassword sets a new password with a password reset link. Every
+// session of the user is signed out.
+func (h *UserHandler) ResetPassword(c *fiber.Ctx) error {
+	var req types.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
+	}
+	if req.Token == "" {
+		return errs.Invalid("token", "token is required")
+	}
+	if err := h.Accounts.ResetPassword(c.UserContext(), req.Token, req.Password); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// sendVerification emails a verification link after the user's email was
+// set. Failing to send doesn't fail the request; the user can ask again.
+func (h *UserHandler) sendVerification(c *fiber.Ctx, user *domain.User) {
+	if user.Email == "" || user.EmailVerified {
+		return
+	}
+	if err := h.Accounts.RequestVerification(c.UserContext(), user); err != nil {
+		log.Printf("Failed to send verification mail to user %s: %v", user.ID, err)
+	}
+}
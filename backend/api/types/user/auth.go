@@ -0,0 +1,53 @@
+package http
+
+import (
+	"github.com/aygoko/EcoMInd/backend/api/types"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Login signs a user in with a login, email or phone number and a password
+func (h *UserHandler) Login(c *fiber.Ctx) error {
+	var req types.LoginRequest
+	if err := c.BodyParser(&req); err != nil || req.Identifier == "" || req.Password == "" {
+		return errs.Validation("identifier and password are required")
+	}
+
+	user, err := h.AuthService.Login(c.UserContext(), req.Identifier, req.Password)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := h.issueTokens(c, user.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(tokens)
+}
+
+// issueTokens starts a session for the requesting device and returns its
+// first access and refresh token pair
+func (h *UserHandler) issueTokens(c *fiber.Ctx, userID string) (*types.TokenResponse, error) {
+	session, refreshToken, err := h.Sessions.Start(c.UserContext(), userID, c.Get(fiber.HeaderUserAgent), c.IP())
+	if err != nil {
+		return nil, err
+	}
+	return h.tokenResponse(session, refreshToken)
+}
+
+// tokenResponse signs an access token for the session
+func (h *UserHandler) tokenResponse(session *domain.Session, refreshToken string) (*types.TokenResponse, error) {
+	accessToken, err := h.Tokens.Issue(session.UserID, session.ID, service.TokenTypeAccess, h.JWT.TTL)
+	if err != nil {
+		return nil, err
+	}
+	return &types.TokenResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(h.JWT.TTL.Seconds()),
+	}, nil
+}
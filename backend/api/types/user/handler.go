@@ -1,220 +1,159 @@
 package http
 
 import (
-	"context"
-	"encoding/json"
 	"net/http"
-	"time"
 
-	"EcoMind/backend/domain"
-	"EcoMind/usecases/service"
+	"github.com/aygoko/EcoMInd/backend/api/problem"
+	"github.com/aygoko/EcoMInd/backend/api/types"
+	"github.com/aygoko/EcoMInd/backend/config"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	oauthprovider "github.com/aygoko/EcoMInd/backend/usecases/oauth"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 )
 
 // UserHandler handles user-related HTTP endpoints
 type UserHandler struct {
 	UserService *service.UserService
+	AuthService *service.AuthService
+	Tokens      *service.TokenService
+	Sessions    *service.SessionService
+	OAuth       *service.OAuthService
+	Social      *service.SocialService
+	Accounts    *service.AccountService
+	JWT         config.JWTConfig
+	Providers   *oauthprovider.Registry // enabled OAuth sign-in providers
 }
 
 // NewUserHandler creates a new user handler instance
-func NewUserHandler(s *service.UserService) *UserHandler {
+func NewUserHandler(s *service.UserService, auth *service.AuthService, tokens *service.TokenService, sessions *service.SessionService, oauth *service.OAuthService, social *service.SocialService, accounts *service.AccountService, providers *oauthprovider.Registry) *UserHandler {
 	return &UserHandler{
 		UserService: s,
+		AuthService: auth,
+		Tokens:      tokens,
+		Sessions:    sessions,
+		OAuth:       oauth,
+		Social:      social,
+		Accounts:    accounts,
+		JWT:         tokens.Config,
+		Providers:   providers,
 	}
 }
 
-// RegisterRoutes registers user routes with Fiber
-func (h *UserHandler) RegisterRoutes(app *fiber.App) {
+// RegisterRoutes registers user routes with Fiber. Routes in groups built
+// with requireAuth are only reachable with a valid access token; those with
+// optionalAuth see the signed-in user when there is one.
+func (h *UserHandler) RegisterRoutes(app *fiber.App, requireAuth, optionalAuth fiber.Handler) {
 	apiGroup := app.Group("/api")
 
-	// Existing user routes
+	// Public user routes
 	userGroup := apiGroup.Group("/users")
 	userGroup.Post("/", h.CreateUser)
-	userGroup.Get("/:login", h.GetUserByLogin)
-
-	// Authentication routes
+	userGroup.Get("/:login", optionalAuth, h.GetUserByLogin)
+
+	// Routes of the signed-in user
+	meGroup := apiGroup.Group("/me", requireAuth)
+	meGroup.Get("/", h.GetMe)
+	meGroup.Patch("/", h.UpdateMe)
+	meGroup.Delete("/", h.DeleteMe)
+	meGroup.Get("/sessions", h.ListSessions)
+	meGroup.Delete("/sessions/:id", h.RevokeSession)
+	meGroup.Get("/identities", h.ListIdentities)
+	meGroup.Post("/identities/:provider", h.LinkIdentity)
+	meGroup.Delete("/identities/:provider", h.UnlinkIdentity)
+	meGroup.Post("/email/verification", h.RequestVerification)
+
+	// Authentication routes; OAuth for every enabled provider
 	authGroup := apiGroup.Group("/auth")
-	authGroup.Get("/google", h.GoogleAuthInit)
-	authGroup.Get("/google/callback", h.GoogleAuthCallback)
-	authGroup.Get("/tiktok", h.TikTokAuthInit)
-	authGroup.Get("/tiktok/callback", h.TikTokAuthCallback)
-}
-
-// GoogleAuthInit initiates Google authentication flow
-func (h *UserHandler) GoogleAuthInit(c *fiber.Ctx) error {
-	url := googleConfig.AuthCodeURL("state", oauth2.AccessTypeOnline)
-	return c.Redirect(url, 302)
+	authGroup.Post("/login", h.Login)
+	authGroup.Post("/refresh", h.Refresh)
+	authGroup.Post("/logout", h.Logout)
+	authGroup.Post("/logout-all", requireAuth, h.LogoutAll)
+	authGroup.Post("/verify-email", h.VerifyEmail)
+	authGroup.Post("/password-reset", h.RequestPasswordReset)
+	authGroup.Post("/password-reset/confirm", h.ResetPassword)
+	authGroup.Get("/:provider", h.OAuthInit)
+	authGroup.Get("/:provider/link", h.OAuthLink)
+	authGroup.Get("/:provider/callback", h.OAuthCallback)
 }
 
-// GoogleAuthCallback handles Google authentication callback
-func (h *UserHandler) GoogleAuthCallback(c *fiber.Ctx) error {
-	code := c.Query("code")
-	if code == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "Missing authorization code"})
-	}
-
-	// Exchange code for token
-	token, err := googleConfig.Exchange(context.Background(), code)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to exchange authorization code"})
+// CreateUser registers a new user and emails them a link to verify their
+// email address
+func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
+	var req types.CreateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
 	}
 
-	// Get user info from Google
-	client := googleConfig.Client(context.Background(), token)
-	resp, err := client.Get("https://www.googleapis.com/oauth2/v3/userinfo")
+	createdUser, err := h.UserService.Create(c.UserContext(), req.Login, req.Email, req.PhoneNumber, req.Password)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to retrieve user info from Google"})
-	}
-	defer resp.Body.Close()
-
-	// Parse Google user info
-	var googleUser struct {
-		Email string `json:"email"`
-		Sub   string `json:"sub"` // Google's user ID
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&googleUser); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Invalid user info format"})
+		return err
 	}
+	h.sendVerification(c, createdUser)
 
-	// Find or create user
-	user, err := h.UserService.FindOrCreateUserByProvider(
-		"google",
-		googleUser.Sub,
-		googleUser.Email,
-	)
+	profile, err := h.Social.Profile(c.UserContext(), createdUser, createdUser.Login)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
+	return c.Status(http.StatusCreated).JSON(types.UserResponse(profile))
+}
 
-	// Generate JWT token
-	tokenString, err := generateJWT(user.ID)
+// GetMe returns the signed-in user's account
+func (h *UserHandler) GetMe(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	profile, err := h.Social.Profile(c.UserContext(), user, user.Login)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate token"})
+		return err
 	}
-
-	return c.JSON(fiber.Map{"token": tokenString})
-}
-
-// TikTokAuthInit initiates TikTok authentication flow
-func (h *UserHandler) TikTokAuthInit(c *fiber.Ctx) error {
-	url := tiktokConfig.AuthCodeURL("state", oauth2.AccessTypeOnline)
-	return c.Redirect(url, 302)
+	return c.JSON(types.UserResponse(profile))
 }
 
-// TikTokAuthCallback handles TikTok authentication callback
-func (h *UserHandler) TikTokAuthCallback(c *fiber.Ctx) error {
-	code := c.Query("code")
-	if code == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "Missing authorization code"})
+// UpdateMe changes the signed-in user's email or phone number. A new email
+// address is sent a verification link.
+func (h *UserHandler) UpdateMe(c *fiber.Ctx) error {
+	var req types.UpdateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return problem.ErrInvalidBody
 	}
 
-	// Exchange code for token (TikTok requires custom handling)
-	token, err := tiktokConfig.Exchange(context.Background(), code)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to exchange authorization code"})
-	}
-
-	// Get user info from TikTok
-	resp, err := http.Get("https://open-api.tiktok.com/user/info/?access_token=" + token.AccessToken)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to retrieve user info from TikTok"})
+	current := domain.UserFromContext(c.UserContext())
+	user := *current
+	update := domain.UserUpdate{Email: req.Email, PhoneNumber: req.PhoneNumber}
+	if err := h.UserService.Update(c.UserContext(), &user, update); err != nil {
+		return err
 	}
-	defer resp.Body.Close()
-
-	// Parse TikTok user info
-	var tiktokUser struct {
-		User struct {
-			UserID   string `json:"user_id"`
-			NickName string `json:"nick_name"`
-		} `json:"user"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&tiktokUser); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Invalid user info format"})
+	if user.Email != current.Email {
+		h.sendVerification(c, &user)
 	}
 
-	// Find or create user
-	user, err := h.UserService.FindOrCreateUserByProvider(
-		"tiktok",
-		tiktokUser.User.UserID,
-		"", // TikTok doesn't provide email by default
-	)
+	profile, err := h.Social.Profile(c.UserContext(), &user, user.Login)
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
-
-	// Generate JWT token
-	tokenString, err := generateJWT(user.ID)
-	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to generate token"})
-	}
-
-	return c.JSON(fiber.Map{"token": tokenString})
+	return c.JSON(types.UserResponse(profile))
 }
 
-// Existing methods remain unchanged
-func (h *UserHandler) CreateUser(c *fiber.Ctx) error {
-	var user domain.User
-	if err := c.BodyParser(&user); err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid request body",
-		})
+// DeleteMe soft-deletes the signed-in user's account
+func (h *UserHandler) DeleteMe(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.UserService.Delete(c.UserContext(), user.Login); err != nil {
+		return err
 	}
-
-	createdUser, err := h.UserService.Create(&user)
-	if err != nil {
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+	if err := h.Sessions.RevokeAll(c.UserContext(), user.ID); err != nil {
+		return err
 	}
-
-	return c.Status(http.StatusCreated).JSON(createdUser)
+	return c.SendStatus(http.StatusNoContent)
 }
 
+// GetUserByLogin returns a user as the caller, who may be anonymous, sees
+// them: their account for themselves, otherwise their public profile
 func (h *UserHandler) GetUserByLogin(c *fiber.Ctx) error {
-	login := c.Params("login")
-	user, err := h.UserService.Get(login)
+	viewer := domain.UserFromContext(c.UserContext())
+	profile, err := h.Social.Profile(c.UserContext(), viewer, c.Params("login"))
 	if err != nil {
-		return c.Status(http.StatusNotFound).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
-	return c.JSON(user)
-}
-
-// JWT Generation Helper
-func generateJWT(userID string) (string, error) {
-	// JWT Secret (replace with a secure value in production)
-	jwtSecret := []byte("your-secure-jwt-secret")
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(time.Hour * 24).Unix(),
-	})
-
-	return token.SignedString(jwtSecret)
-}
-
-// OAuth2 Configurations (should be in separate config file)
-var googleConfig = &oauth2.Config{
-	ClientID:     "YOUR_GOOGLE_CLIENT_ID",
-	ClientSecret: "YOUR_GOOGLE_CLIENT_SECRET",
-	RedirectURL:  "http://localhost:3000/api/auth/google/callback",
-	Endpoint:     google.Endpoint,
-	Scopes:       []string{"openid", "email", "profile"},
-}
-
-var tiktokConfig = &oauth2.Config{
-	ClientID:     "YOUR_TIKTOK_CLIENT_ID",
-	ClientSecret: "YOUR_TIKTOK_CLIENT_SECRET",
-	RedirectURL:  "http://localhost:3000/api/auth/tiktok/callback",
-	Endpoint: oauth2.Endpoint{
-		AuthURL:  "https://www.tiktok.com/v2/oauth/authorize/",
-		TokenURL: "https://open-api.tiktok.com/oauth/access_token/",
-	},
-	Scopes: []string{"user.info.basic"},
+	return c.JSON(types.UserResponse(profile))
 }
@@ -0,0 +1,168 @@
+package http
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/api/types"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	oauthprovider "github.com/aygoko/EcoMInd/backend/usecases/oauth"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errUnknownProvider is returned for a :provider that isn't enabled
+var errUnknownProvider = errs.NotFound("unknown provider")
+
+// oauthBindingCookie holds the binding of the browser's pending authorization
+// request; the callback only accepts states started by the same browser
+const oauthBindingCookie = "oauth_binding"
+
+// oauthCookiePath scopes the binding cookie to the OAuth routes
+const oauthCookiePath = "/api/auth/"
+
+// provider resolves the :provider route parameter
+func (h *UserHandler) provider(c *fiber.Ctx) (oauthprovider.Provider, bool) {
+	return h.Providers.Get(c.Params("provider"))
+}
+
+// redirectToProvider binds this browser to an authorization request begun by
+// OAuth.Begin or OAuth.StartLink through an HttpOnly cookie, and redirects it
+// to the provider's consent page
+func (h *UserHandler) redirectToProvider(c *fiber.Ctx, provider oauthprovider.Provider, state *domain.OAuthState, binding string) error {
+	url, err := provider.AuthCodeURL(c.UserContext(), state.State, state.CodeVerifier)
+	if err != nil {
+		log.Printf("oauth %s: %v", provider.Name(), err)
+		return errs.Upstream("failed to reach " + provider.Name())
+	}
+	setBindingCookie(c, binding, state.ExpiresAt)
+	return c.Redirect(url, http.StatusFound)
+}
+
+// setBindingCookie sets the binding cookie, or deletes it when expires has
+// passed
+func setBindingCookie(c *fiber.Ctx, binding string, expires time.Time) {
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthBindingCookie,
+		Value:    binding,
+		Path:     oauthCookiePath,
+		Expires:  expires,
+		Secure:   c.Protocol() == "https",
+		HTTPOnly: true,
+		// Lax still sends the cookie on the provider's top-level redirect back
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+// OAuthInit redirects to the provider's consent page to sign in
+func (h *UserHandler) OAuthInit(c *fiber.Ctx) error {
+	provider, ok := h.provider(c)
+	if !ok {
+		return errUnknownProvider
+	}
+	state, binding, err := h.OAuth.Begin(c.UserContext(), provider.Name(), "")
+	if err != nil {
+		return err
+	}
+	return h.redirectToProvider(c, provider, state, binding)
+}
+
+// OAuthLink opens a link ticket from LinkIdentity in the browser and
+// redirects to the provider's consent page to link the account
+func (h *UserHandler) OAuthLink(c *fiber.Ctx) error {
+	provider, ok := h.provider(c)
+	if !ok {
+		return errUnknownProvider
+	}
+	state, binding, err := h.OAuth.StartLink(c.UserContext(), provider.Name(), c.Query("ticket"))
+	if err != nil {
+		return err
+	}
+	return h.redirectToProvider(c, provider, state, binding)
+}
+
+// OAuthCallback completes an authorization request. It signs the user in, or
+// links the provider account when the request was started by LinkIdentity.
+func (h *UserHandler) OAuthCallback(c *fiber.Ctx) error {
+	provider, ok := h.provider(c)
+	if !ok {
+		return errUnknownProvider
+	}
+	code := c.Query("code")
+	if code == "" {
+		return errs.Invalid("code", "missing authorization code")
+	}
+
+	binding := c.Cookies(oauthBindingCookie)
+	setBindingCookie(c, "", time.Unix(0, 0))
+	pending, err := h.OAuth.Complete(c.UserContext(), provider.Name(), c.Query("state"), binding)
+	if err != nil {
+		return err
+	}
+
+	profile, err := provider.Exchange(c.UserContext(), code, pending.CodeVerifier)
+	if err != nil {
+		log.Printf("oauth %s: %v", provider.Name(), err)
+		return errs.Upstream("failed to sign in with " + provider.Name())
+	}
+
+	if pending.LinkUserID != "" {
+		identity, err := h.OAuth.Link(c.UserContext(), pending.LinkUserID, provider.Name(), profile.Subject, profile.Email)
+		if err != nil {
+			return err
+		}
+		return c.Status(http.StatusCreated).JSON(identity)
+	}
+
+	user, err := h.OAuth.SignIn(c.UserContext(), provider.Name(), profile.Subject, profile.Email)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := h.issueTokens(c, user.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(tokens)
+}
+
+// ListIdentities lists the providers linked to the signed-in user
+func (h *UserHandler) ListIdentities(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	identities, err := h.OAuth.ListIdentities(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(identities)
+}
+
+// LinkIdentity starts linking a provider to the signed-in user. The client
+// opens the returned URL, a single-use link ticket, in the browser: OAuthLink
+// binds that browser to the request and sends it to the provider, which
+// redirects back to the callback to attach the account to the user.
+func (h *UserHandler) LinkIdentity(c *fiber.Ctx) error {
+	provider, ok := h.provider(c)
+	if !ok {
+		return errUnknownProvider
+	}
+	user := domain.UserFromContext(c.UserContext())
+	ticket, err := h.OAuth.BeginLink(c.UserContext(), provider.Name(), user.ID)
+	if err != nil {
+		return err
+	}
+	link := c.BaseURL() + "/api/auth/" + provider.Name() + "/link?ticket=" + url.QueryEscape(ticket)
+	return c.JSON(types.AuthorizationResponse{AuthorizationURL: link})
+}
+
+// UnlinkIdentity detaches a provider from the signed-in user
+func (h *UserHandler) UnlinkIdentity(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	err := h.OAuth.Unlink(c.UserContext(), user, c.Params("provider"))
+	if err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
@@ -0,0 +1,84 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/aygoko/EcoMInd/backend/api/types"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Refresh rotates a refresh token and returns a new token pair. Replaying an
+// already rotated token revokes its session.
+func (h *UserHandler) Refresh(c *fiber.Ctx) error {
+	var req types.RefreshRequest
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return errs.Invalid("refresh_token", "refresh_token is required")
+	}
+
+	session, refreshToken, err := h.Sessions.Refresh(c.UserContext(), req.RefreshToken, c.Get(fiber.HeaderUserAgent), c.IP())
+	if errors.Is(err, domain.ErrSessionNotFound) {
+		return errs.WithKind(errs.KindUnauthorized, err)
+	} else if err != nil {
+		return err
+	}
+
+	tokens, err := h.tokenResponse(session, refreshToken)
+	if err != nil {
+		return err
+	}
+	return c.JSON(tokens)
+}
+
+// Logout revokes the session of the given refresh token
+func (h *UserHandler) Logout(c *fiber.Ctx) error {
+	var req types.RefreshRequest
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return errs.Invalid("refresh_token", "refresh_token is required")
+	}
+
+	err := h.Sessions.Logout(c.UserContext(), req.RefreshToken)
+	if err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+		return err
+	}
+	// Logging out twice is not an error
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// LogoutAll revokes every session of the signed-in user
+func (h *UserHandler) LogoutAll(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	if err := h.Sessions.RevokeAll(c.UserContext(), user.ID); err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// ListSessions lists the signed-in user's active devices
+func (h *UserHandler) ListSessions(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	sessions, err := h.Sessions.List(c.UserContext(), user.ID)
+	if err != nil {
+		return err
+	}
+
+	current := domain.SessionIDFromContext(c.UserContext())
+	response := make([]types.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		response = append(response, types.SessionResponse{Session: session, Current: session.ID == current})
+	}
+	return c.JSON(response)
+}
+
+// RevokeSession signs one of the user's devices out
+func (h *UserHandler) RevokeSession(c *fiber.Ctx) error {
+	user := domain.UserFromContext(c.UserContext())
+	err := h.Sessions.Revoke(c.UserContext(), user.ID, c.Params("id"))
+	if err != nil {
+		return err
+	}
+	return c.SendStatus(http.StatusNoContent)
+}
@@ -0,0 +1,666 @@
+// Package config loads the backend configuration.
+//
+// Every setting has a dotted key derived from the `key` struct tags, e.g.
+// postgres.dsn. The same key is used in the config file, as a command-line
+// flag (-postgres.dsn) and, upper-cased with dots replaced by underscores and
+// an ECOMIND_ prefix, as an environment variable (ECOMIND_POSTGRES_DSN).
+// Sources are applied in order defaults < file < environment < flags.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	envPrefix     = "ECOMIND_"
+	redactedValue = "[REDACTED]"
+)
+
+// providerName restricts OAuth provider names to what fits a URL path
+// segment and a config key
+var providerName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+
+// Config is the root backend configuration
+type Config struct {
+	Addr         string        `key:"addr" usage:"HTTP server address"`
+	Storage      string        `key:"storage" usage:"Storage backend: postgres or memory"`
+	ReadTimeout  time.Duration `key:"read_timeout" usage:"HTTP read timeout"`
+	WriteTimeout time.Duration `key:"write_timeout" usage:"HTTP write timeout"`
+	ExposeConfig bool          `key:"expose_config" usage:"Serve the redacted configuration to admins at /debug/config"`
+
+	CORS     CORSConfig     `key:"cors"`
+	Postgres PostgresConfig `key:"postgres"`
+	Redis    RedisConfig    `key:"redis"`
+	JWT      JWTConfig      `key:"jwt"`
+	Login    LoginConfig    `key:"login"`
+	Users    UsersConfig    `key:"users"`
+	Mail     MailConfig     `key:"mail"`
+	OAuth    OAuthConfig    `key:"oauth"`
+	Admin    AdminConfig    `key:"admin"`
+	Goals    GoalsConfig    `key:"goals"`
+	Coach    CoachConfig    `key:"coach"`
+	Stats    StatsConfig    `key:"stats"`
+}
+
+// CORSConfig controls cross-origin access for the mobile and web clients
+type CORSConfig struct {
+	AllowOrigins []string `key:"allow_origins" usage:"Comma-separated list of allowed origins"`
+}
+
+// PostgresConfig holds the Postgres connection settings
+type PostgresConfig struct {
+	DSN            string `key:"dsn" secret:"true" usage:"PostgreSQL connection string"`
+	MigrateOnStart bool   `key:"migrate_on_start" usage:"Apply pending schema migrations when the server starts"`
+}
+
+// RedisConfig holds the Redis connection and cache settings
+type RedisConfig struct {
+	Addr     string        `key:"addr" usage:"Redis server address"`
+	Password string        `key:"password" secret:"true" usage:"Redis password"`
+	DB       int           `key:"db" usage:"Redis database number"`
+	CacheTTL time.Duration `key:"cache_ttl" usage:"How long users stay cached in Redis"`
+}
+
+// JWTConfig controls access token signing
+type JWTConfig struct {
+	Algorithm      string        `key:"algorithm" usage:"Token signing algorithm: HS256 or RS256"`
+	Secret         string        `key:"secret" secret:"true" usage:"HMAC secret used to sign tokens with HS256"`
+	PrivateKeyFile string        `key:"private_key_file" usage:"PEM RSA private key used to sign tokens with RS256"`
+	Issuer         string        `key:"issuer" usage:"Issuer (iss) of issued tokens"`
+	Audience       string        `key:"audience" usage:"Audience (aud) of issued tokens"`
+	TTL            time.Duration `key:"ttl" usage:"Access token lifetime"`
+	RefreshTTL     time.Duration `key:"refresh_ttl" usage:"Refresh token lifetime"`
+}
+
+// LoginConfig controls password sign-in lockout
+type LoginConfig struct {
+	MaxAttempts   int           `key:"max_attempts" usage:"Failed sign-ins allowed before the account is locked"`
+	LockoutWindow time.Duration `key:"lockout_window" usage:"How long failed sign-ins are counted and the lock lasts"`
+}
+
+// UsersConfig controls how user input is read and the links emailed to
+// verify addresses and reset passwords
+type UsersConfig struct {
+	PhoneRegion     string        `key:"phone_region" usage:"Region, such as RU, of phone numbers entered without a country code"`
+	LinkBaseURL     string        `key:"link_base_url" usage:"Base URL of the app pages emailed links open, like https://ecomind.app"`
+	VerificationTTL time.Duration `key:"verification_ttl" usage:"How long an email verification link stays valid"`
+	ResetTTL        time.Duration `key:"reset_ttl" usage:"How long a password reset link stays valid"`
+	MailLimit       int           `key:"mail_limit" usage:"Verification or reset emails sent per user in a mail window"`
+	MailWindow      time.Duration `key:"mail_window" usage:"Window of the per-user email limit"`
+}
+
+// MailConfig selects how email is sent. The log provider writes mails to
+// File, or the log while it is empty, for local development and tests.
+type MailConfig struct {
+	Provider string        `key:"provider" usage:"Mail provider: smtp or log"`
+	From     string        `key:"from" usage:"Sender address of outgoing mail"`
+	File     string        `key:"file" usage:"File the log provider appends mails to; empty logs them"`
+	Host     string        `key:"host" usage:"SMTP server host"`
+	Port     int           `key:"port" usage:"SMTP server port; 465 uses implicit TLS, others STARTTLS when offered"`
+	Username string        `key:"username" usage:"SMTP username; empty skips authentication"`
+	Password string        `key:"password" secret:"true" usage:"SMTP password"`
+	Timeout  time.Duration `key:"timeout" usage:"Timeout of sending one mail"`
+}
+
+// AdminConfig names the users allowed to use the admin API
+type AdminConfig struct {
+	Logins []string `key:"logins" usage:"Comma-separated logins allowed to use the admin API"`
+}
+
+// GoalsConfig controls the weekly goal scheduler
+type GoalsConfig struct {
+	RolloverInterval time.Duration `key:"rollover_interval" usage:"How often ended weeks are closed and next week's goals proposed"`
+}
+
+// StatsConfig controls the statistics cache
+type StatsConfig struct {
+	CacheTTL time.Duration `key:"cache_ttl" usage:"How long aggregated statistics stay cached when no activity is logged"`
+}
+
+// CoachConfig selects the provider behind /api/coach and limits its use.
+// The openai provider works with any OpenAI-compatible chat completions API.
+type CoachConfig struct {
+	Provider   string        `key:"provider" usage:"Coaching provider: local or openai"`
+	BaseURL    string        `key:"base_url" usage:"Base URL of the OpenAI-compatible API"`
+	APIKey     string        `key:"api_key" secret:"true" usage:"API key of the OpenAI-compatible API"`
+	Model      string        `key:"model" usage:"Chat model used by the openai provider"`
+	MaxTokens  int           `key:"max_tokens" usage:"Upper bound on the length of a reply in tokens"`
+	Timeout    time.Duration `key:"timeout" usage:"Timeout of one provider call"`
+	RateLimit  int           `key:"rate_limit" usage:"Coach messages allowed per user in a rate window"`
+	RateWindow time.Duration `key:"rate_window" usage:"Window of the per-user coach rate limit"`
+}
+
+// OAuthConfig lists the OAuth sign-in providers by name. The name is the
+// provider's path segment under /api/auth/. Providers other than the
+// defaults are declared in the config file; their settings can then also be
+// overridden from the environment.
+type OAuthConfig struct {
+	StateSecret string                          `key:"state_secret" secret:"true" usage:"HMAC secret signing OAuth state values, at least 32 bytes"`
+	StateTTL    time.Duration                   `key:"state_ttl" usage:"How long an OAuth authorization request stays valid"`
+	Providers   map[string]*OAuthProviderConfig `key:"providers"`
+}
+
+// OAuthProviderConfig configures one sign-in provider. OIDC providers only
+// need an issuer; their endpoints and signing keys are discovered. Plain
+// OAuth2 providers list their endpoints and where the account ID, email and
+// email verified flag sit in the userinfo response, as dotted JSON paths like
+// data.user.open_id. Their email is only used when the flag is true.
+// A provider is disabled while its client ID is empty.
+type OAuthProviderConfig struct {
+	Type               string   `key:"type" usage:"Provider protocol: oidc or oauth2"`
+	Issuer             string   `key:"issuer" usage:"OIDC issuer URL"`
+	ClientID           string   `key:"client_id" usage:"OAuth client ID"`
+	ClientSecret       string   `key:"client_secret" secret:"true" usage:"OAuth client secret"`
+	RedirectURL        string   `key:"redirect_url" usage:"OAuth redirect URL"`
+	Scopes             []string `key:"scopes" usage:"Comma-separated list of requested scopes"`
+	AuthURL            string   `key:"auth_url" usage:"OAuth2 authorization endpoint"`
+	TokenURL           string   `key:"token_url" usage:"OAuth2 token endpoint"`
+	UserInfoURL        string   `key:"userinfo_url" usage:"OAuth2 userinfo endpoint"`
+	SubjectField       string   `key:"subject_field" usage:"Userinfo path of the account ID"`
+	EmailField         string   `key:"email_field" usage:"Userinfo path of the email, if shared"`
+	EmailVerifiedField string   `key:"email_verified_field" usage:"Userinfo path of the flag telling the email is verified"`
+}
+
+// Enabled reports whether the provider has credentials configured
+func (p OAuthProviderConfig) Enabled() bool {
+	return p.ClientID != ""
+}
+
+// Default returns the configuration used when nothing overrides it
+func Default() *Config {
+	return &Config{
+		Addr:         ":8080",
+		Storage:      "postgres",
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		CORS: CORSConfig{
+			AllowOrigins: []string{"http://localhost:3000"},
+		},
+		Redis: RedisConfig{
+			Addr:     "localhost:6379",
+			CacheTTL: 5 * time.Minute,
+		},
+		JWT: JWTConfig{
+			Algorithm:  "HS256",
+			Issuer:     "ecomind",
+			Audience:   "ecomind-app",
+			TTL:        15 * time.Minute,
+			RefreshTTL: 30 * 24 * time.Hour,
+		},
+		Login: LoginConfig{
+			MaxAttempts:   5,
+			LockoutWindow: 15 * time.Minute,
+		},
+		Users: UsersConfig{
+			PhoneRegion:     "RU",
+			LinkBaseURL:     "http://localhost:3000",
+			VerificationTTL: 48 * time.Hour,
+			ResetTTL:        time.Hour,
+			MailLimit:       5,
+			MailWindow:      time.Hour,
+		},
+		Mail: MailConfig{
+			Provider: "log",
+			From:     "EcoMind <no-reply@ecomind.local>",
+			Port:     587,
+			Timeout:  10 * time.Second,
+		},
+		Goals: GoalsConfig{
+			RolloverInterval: 15 * time.Minute,
+		},
+		Stats: StatsConfig{
+			CacheTTL: time.Hour,
+		},
+		Coach: CoachConfig{
+			Provider:   "local",
+			BaseURL:    "https://api.openai.com/v1",
+			Model:      "gpt-4o-mini",
+			MaxTokens:  400,
+			Timeout:    30 * time.Second,
+			RateLimit:  20,
+			RateWindow: time.Hour,
+		},
+		OAuth: OAuthConfig{
+			StateTTL: 10 * time.Minute,
+			Providers: map[string]*OAuthProviderConfig{
+				"google": {
+					Type:        "oidc",
+					Issuer:      "https://accounts.google.com",
+					RedirectURL: "http://localhost:3000/api/auth/google/callback",
+					Scopes:      []string{"openid", "email", "profile"},
+				},
+				"tiktok": {
+					Type:         "oauth2",
+					RedirectURL:  "http://localhost:3000/api/auth/tiktok/callback",
+					Scopes:       []string{"user.info.basic"},
+					AuthURL:      "https://www.tiktok.com/v2/auth/authorize/",
+					TokenURL:     "https://open.tiktokapis.com/v2/oauth/token/",
+					UserInfoURL:  "https://open.tiktokapis.com/v2/user/info/?fields=open_id",
+					SubjectField: "data.user.open_id",
+				},
+			},
+		},
+	}
+}
+
+// field is one addressable leaf setting of Config
+type field struct {
+	key    string
+	usage  string
+	secret bool
+	value  reflect.Value
+}
+
+// env returns the environment variable name of the field
+func (f field) env() string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(f.key, ".", "_"))
+}
+
+// fields lists every leaf setting of cfg in declaration order
+func (cfg *Config) fields() []field {
+	var out []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			key := prefix + sf.Tag.Get("key")
+			switch sf.Type.Kind() {
+			case reflect.Struct:
+				walk(v.Field(i), key+".")
+				continue
+			case reflect.Map:
+				// Maps hold named groups of settings, walked in name order
+				m := v.Field(i)
+				names := make([]string, 0, m.Len())
+				for _, name := range m.MapKeys() {
+					names = append(names, name.String())
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					walk(m.MapIndex(reflect.ValueOf(name)).Elem(), key+"."+name+".")
+				}
+				continue
+			}
+			out = append(out, field{
+				key:    key,
+				usage:  sf.Tag.Get("usage"),
+				secret: sf.Tag.Get("secret") == "true",
+				value:  v.Field(i),
+			})
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return out
+}
+
+// set assigns raw to the field. Environment variables and flags pass strings,
+// config files pass whatever their decoder produced.
+func (f field) set(raw interface{}) error {
+	v := f.value
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		s, ok := raw.(string)
+		if !ok {
+			return fmt.Errorf("%s: duration must be a string like \"30s\"", f.key)
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.key, err)
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(fmt.Sprint(raw))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(fmt.Sprint(raw))
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.key, err)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(fmt.Sprint(raw))
+		if err != nil {
+			return fmt.Errorf("%s: %w", f.key, err)
+		}
+		v.SetInt(int64(n))
+	case reflect.Slice:
+		var items []string
+		switch raw := raw.(type) {
+		case []interface{}:
+			for _, item := range raw {
+				items = append(items, fmt.Sprint(item))
+			}
+		default:
+			for _, item := range strings.Split(fmt.Sprint(raw), ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("%s: unsupported type %s", f.key, v.Type())
+	}
+	return nil
+}
+
+// String renders the field value the way it would be written in a flag
+func (f field) String() string {
+	if items, ok := f.value.Interface().([]string); ok {
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+// flagValue collects a flag for later application so flags can win over
+// the config file even though the file path itself is a flag
+type flagValue struct {
+	f       field
+	pending map[string]string
+	isBool  bool
+}
+
+func (v *flagValue) String() string {
+	if v == nil || !v.f.value.IsValid() {
+		return ""
+	}
+	return v.f.String()
+}
+
+func (v *flagValue) Set(s string) error {
+	v.pending[v.f.key] = s
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
+
+// Load builds the configuration from defaults, the config file named by
+// -config or ECOMIND_CONFIG, the environment and the given command-line
+// arguments, then validates it. It also returns the positional arguments
+// left after the flags.
+func Load(args []string) (*Config, []string, error) {
+	cfg := Default()
+	fields := cfg.fields()
+
+	fs := flag.NewFlagSet("ecomind", flag.ContinueOnError)
+	configPath := fs.String("config", os.Getenv(envPrefix+"CONFIG"), "Path to a YAML or TOML config file")
+	pending := make(map[string]string)
+	for _, f := range fields {
+		fs.Var(&flagValue{f: f, pending: pending, isBool: f.value.Kind() == reflect.Bool}, f.key, f.usage)
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+
+	if *configPath != "" {
+		if err := cfg.loadFile(*configPath); err != nil {
+			return nil, nil, err
+		}
+		// The file may have declared new map entries
+		fields = cfg.fields()
+	}
+
+	for _, f := range fields {
+		if raw, ok := os.LookupEnv(f.env()); ok {
+			if err := f.set(raw); err != nil {
+				return nil, nil, fmt.Errorf("environment %s: %w", f.env(), err)
+			}
+		}
+	}
+
+	for _, f := range fields {
+		if raw, ok := pending[f.key]; ok {
+			if err := f.set(raw); err != nil {
+				return nil, nil, fmt.Errorf("flag -%w", err)
+			}
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, fs.Args(), nil
+}
+
+// loadFile applies a YAML or TOML file, chosen by extension
+func (cfg *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config file: %w", err)
+	}
+
+	tree := make(map[string]interface{})
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &tree)
+	case ".toml":
+		err = toml.Unmarshal(data, &tree)
+	default:
+		return fmt.Errorf("config file %s: unsupported extension %q (want .yaml, .yml or .toml)", path, ext)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	declare(reflect.ValueOf(cfg).Elem(), tree)
+	fields := cfg.fields()
+	byKey := make(map[string]field, len(fields))
+	for _, f := range fields {
+		byKey[f.key] = f
+	}
+
+	var apply func(node map[string]interface{}, prefix string) error
+	apply = func(node map[string]interface{}, prefix string) error {
+		for name, raw := range node {
+			key := prefix + name
+			if f, ok := byKey[key]; ok {
+				if err := f.set(raw); err != nil {
+					return fmt.Errorf("config file %s: %w", path, err)
+				}
+				continue
+			}
+			child, ok := raw.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("config file %s: unknown key %q", path, key)
+			}
+			if err := apply(child, key+"."); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return apply(tree, "")
+}
+
+// declare adds the map entries named in a config file tree, such as a new
+// oauth.providers.<name>, so that their settings become addressable
+func declare(v reflect.Value, node map[string]interface{}) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		child, ok := node[sf.Tag.Get("key")].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		switch sf.Type.Kind() {
+		case reflect.Struct:
+			declare(v.Field(i), child)
+		case reflect.Map:
+			m := v.Field(i)
+			if m.IsNil() {
+				m.Set(reflect.MakeMap(sf.Type))
+			}
+			for name := range child {
+				if !m.MapIndex(reflect.ValueOf(name)).IsValid() {
+					m.SetMapIndex(reflect.ValueOf(name), reflect.New(sf.Type.Elem().Elem()))
+				}
+			}
+		}
+	}
+}
+
+// Validate checks that the configuration is complete and consistent
+func (cfg *Config) Validate() error {
+	var errs []error
+	switch cfg.Storage {
+	case "memory":
+	case "postgres":
+		if cfg.Postgres.DSN == "" {
+			errs = append(errs, errors.New("postgres.dsn is required when storage is postgres"))
+		}
+		if cfg.Redis.Addr == "" {
+			errs = append(errs, errors.New("redis.addr is required when storage is postgres"))
+		}
+		if cfg.Redis.CacheTTL <= 0 {
+			errs = append(errs, errors.New("redis.cache_ttl must be positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("storage must be postgres or memory, got %q", cfg.Storage))
+	}
+	if cfg.Addr == "" {
+		errs = append(errs, errors.New("addr is required"))
+	}
+	switch cfg.JWT.Algorithm {
+	case "HS256":
+		if len(cfg.JWT.Secret) < 32 {
+			errs = append(errs, errors.New("jwt.secret must be at least 32 bytes"))
+		}
+	case "RS256":
+		if cfg.JWT.PrivateKeyFile == "" {
+			errs = append(errs, errors.New("jwt.private_key_file is required for RS256"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("jwt.algorithm must be HS256 or RS256, got %q", cfg.JWT.Algorithm))
+	}
+	if cfg.JWT.Issuer == "" || cfg.JWT.Audience == "" {
+		errs = append(errs, errors.New("jwt.issuer and jwt.audience are required"))
+	}
+	if cfg.JWT.TTL <= 0 || cfg.JWT.RefreshTTL <= 0 {
+		errs = append(errs, errors.New("jwt.ttl and jwt.refresh_ttl must be positive"))
+	}
+	if cfg.Login.MaxAttempts < 1 || cfg.Login.LockoutWindow <= 0 {
+		errs = append(errs, errors.New("login.max_attempts must be at least 1 and login.lockout_window positive"))
+	}
+	// users.phone_region is checked by main against the validate package's
+	// region table, which config doesn't depend on
+	if cfg.Users.PhoneRegion == "" {
+		errs = append(errs, errors.New("users.phone_region is required"))
+	}
+	if cfg.Users.LinkBaseURL == "" {
+		errs = append(errs, errors.New("users.link_base_url is required"))
+	}
+	if cfg.Users.VerificationTTL <= 0 || cfg.Users.ResetTTL <= 0 {
+		errs = append(errs, errors.New("users.verification_ttl and users.reset_ttl must be positive"))
+	}
+	if cfg.Users.MailLimit < 1 || cfg.Users.MailWindow <= 0 {
+		errs = append(errs, errors.New("users.mail_limit must be at least 1 and users.mail_window positive"))
+	}
+	switch cfg.Mail.Provider {
+	case "log":
+	case "smtp":
+		if cfg.Mail.Host == "" || cfg.Mail.Port <= 0 {
+			errs = append(errs, errors.New("mail.host and mail.port are required for the smtp provider"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("mail.provider must be smtp or log, got %q", cfg.Mail.Provider))
+	}
+	if cfg.Mail.From == "" || cfg.Mail.Timeout <= 0 {
+		errs = append(errs, errors.New("mail.from is required and mail.timeout must be positive"))
+	}
+	if cfg.Goals.RolloverInterval <= 0 {
+		errs = append(errs, errors.New("goals.rollover_interval must be positive"))
+	}
+	if cfg.Stats.CacheTTL <= 0 {
+		errs = append(errs, errors.New("stats.cache_ttl must be positive"))
+	}
+	switch cfg.Coach.Provider {
+	case "local":
+	case "openai":
+		if cfg.Coach.BaseURL == "" || cfg.Coach.Model == "" {
+			errs = append(errs, errors.New("coach.base_url and coach.model are required for the openai provider"))
+		}
+		if cfg.Coach.MaxTokens < 1 || cfg.Coach.Timeout <= 0 {
+			errs = append(errs, errors.New("coach.max_tokens must be at least 1 and coach.timeout positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("coach.provider must be local or openai, got %q", cfg.Coach.Provider))
+	}
+	if cfg.Coach.RateLimit < 1 || cfg.Coach.RateWindow <= 0 {
+		errs = append(errs, errors.New("coach.rate_limit must be at least 1 and coach.rate_window positive"))
+	}
+	for _, origin := range cfg.CORS.AllowOrigins {
+		if origin == "*" {
+			errs = append(errs, errors.New("cors.allow_origins cannot contain * because credentials are allowed"))
+		}
+	}
+	anyProvider := false
+	for name, p := range cfg.OAuth.Providers {
+		if !providerName.MatchString(name) {
+			errs = append(errs, fmt.Errorf("oauth.providers.%s: name must be lower-case letters, digits, - or _", name))
+		}
+		if !p.Enabled() {
+			continue
+		}
+		anyProvider = true
+		if p.ClientSecret == "" || p.RedirectURL == "" {
+			errs = append(errs, fmt.Errorf("oauth.providers.%s needs client_secret and redirect_url when client_id is set", name))
+		}
+		switch p.Type {
+		case "oidc":
+			if p.Issuer == "" {
+				errs = append(errs, fmt.Errorf("oauth.providers.%s.issuer is required for oidc", name))
+			}
+		case "oauth2":
+			if p.AuthURL == "" || p.TokenURL == "" || p.UserInfoURL == "" || p.SubjectField == "" {
+				errs = append(errs, fmt.Errorf("oauth.providers.%s needs auth_url, token_url, userinfo_url and subject_field for oauth2", name))
+			}
+			if p.EmailField != "" && p.EmailVerifiedField == "" {
+				errs = append(errs, fmt.Errorf("oauth.providers.%s.email_verified_field is required with email_field; unverified emails are not used", name))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("oauth.providers.%s.type must be oidc or oauth2, got %q", name, p.Type))
+		}
+	}
+	if anyProvider && len(cfg.OAuth.StateSecret) < 32 {
+		errs = append(errs, errors.New("oauth.state_secret must be at least 32 bytes when a provider is enabled"))
+	}
+	if cfg.OAuth.StateTTL <= 0 {
+		errs = append(errs, errors.New("oauth.state_ttl must be positive"))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// Redacted returns every setting keyed by its dotted name with secrets masked,
+// suitable for logging or the debug endpoint
+func (cfg *Config) Redacted() map[string]string {
+	out := make(map[string]string)
+	for _, f := range cfg.fields() {
+		value := f.String()
+		if f.secret && value != "" {
+			value = redactedValue
+		}
+		out[f.key] = value
+	}
+	return out
+}
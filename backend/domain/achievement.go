@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Badge is an achievement users earn once a metric of theirs reaches
+// Threshold. It's checked when one of the On events happens.
+type Badge struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Icon        string   `json:"icon"`
+	Metric      string   `json:"metric"`
+	Threshold   float64  `json:"threshold"`
+	On          []string `json:"-"`
+}
+
+// Achievement is a badge as seen by one user, earned or not
+type Achievement struct {
+	Badge
+	Progress  float64    `json:"progress"` // the metric's value, capped at the threshold
+	Earned    bool       `json:"earned"`
+	AwardedAt *time.Time `json:"awarded_at,omitempty"`
+}
+
+// AchievementService is the storage contract for awarded badges
+type AchievementService interface {
+	// Award records that the user earned a badge and reports whether it
+	// was new; awarding a badge twice keeps the first award.
+	Award(ctx context.Context, userID, badgeID string, at time.Time) (bool, error)
+	// Awarded returns when the user earned each of their badges.
+	Awarded(ctx context.Context, userID string) (map[string]time.Time, error)
+}
@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Activity is one logged action with a carbon footprint, such as a car trip
+// or a meal. CO2e is computed by the backend from the emission factor in
+// effect when the activity was logged; the factor is kept with the entry so
+// later catalogue versions don't rewrite history.
+type Activity struct {
+	ID            string    `json:"id"`
+	UserID        string    `json:"-"`
+	Category      string    `json:"category"`
+	Quantity      float64   `json:"quantity"`
+	Unit          string    `json:"unit"`
+	Region        string    `json:"region,omitempty"` // where it happened, ISO 3166-1 alpha-2
+	FactorID      string    `json:"factor_id"`
+	FactorVersion int       `json:"factor_version"`
+	Factor        float64   `json:"factor"` // kg CO2e per unit
+	FactorSource  string    `json:"factor_source"`
+	CO2e          float64   `json:"co2e"` // kg CO2e
+	OccurredAt    time.Time `json:"occurred_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// ActivityFilter narrows an activity listing. Zero values don't filter.
+type ActivityFilter struct {
+	From  time.Time // inclusive
+	To    time.Time // exclusive
+	Limit int
+}
+
+// ActivityService is the storage contract for activities. Creating or
+// deleting an activity recomputes the owner's User.CO2 total in the same
+// transaction.
+type ActivityService interface {
+	Create(ctx context.Context, activity *Activity) error
+	Get(ctx context.Context, userID, id string) (*Activity, error)
+	// List returns the user's activities, most recent first.
+	List(ctx context.Context, userID string, filter ActivityFilter) ([]*Activity, error)
+	Delete(ctx context.Context, userID, id string) error
+}
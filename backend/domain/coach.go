@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Coach conversation roles
+const (
+	CoachRoleUser      = "user"
+	CoachRoleAssistant = "assistant"
+)
+
+// FootprintSummary is the structured view of a user's footprint the coach
+// advises on
+type FootprintSummary struct {
+	Name            string             `json:"name"`
+	AnnualBaseline  float64            `json:"annual_baseline"` // kg CO2e a year from the survey, 0 if not answered
+	LoggedTotal     float64            `json:"logged_total"`    // kg CO2e of every logged activity
+	RecentByType    map[string]float64 `json:"recent_by_type"`  // kg CO2e per activity category over the last 30 days
+	CO2Saved        float64            `json:"co2_saved"`       // kg CO2e saved by completed tasks
+	Points          int                `json:"points"`
+	Goal            *WeeklyGoal        `json:"goal,omitempty"` // this week's goal with progress
+	Recommendations []*Recommendation  `json:"recommendations"`
+}
+
+// CoachMessage is one turn of a coaching conversation
+type CoachMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// CoachExchange is a logged question to the coach and its answer, with the
+// prompt the provider was given
+type CoachExchange struct {
+	ID        string        `json:"id"`
+	UserID    string        `json:"-"`
+	Provider  string        `json:"provider"`
+	Prompt    string        `json:"-"`
+	Message   string        `json:"message"`
+	Reply     string        `json:"reply"`
+	Error     string        `json:"-"` // why the provider failed; Reply is empty then
+	Duration  time.Duration `json:"-"` // how long the provider took
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// CoachService is the storage contract for the coaching log
+type CoachService interface {
+	Create(ctx context.Context, exchange *CoachExchange) error
+	// List returns the user's exchanges, most recent first.
+	List(ctx context.Context, userID string, limit int) ([]*CoachExchange, error)
+}
+
+// RateLimitService counts events per key in fixed windows
+type RateLimitService interface {
+	// Hit counts an event for key and returns the count in the current
+	// window, which starts with the first event, and when the window ends.
+	Hit(ctx context.Context, key string, window time.Duration) (count int, resetAt time.Time, err error)
+}
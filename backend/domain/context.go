@@ -0,0 +1,46 @@
+package domain
+
+import "context"
+
+type contextKey int
+
+const (
+	userContextKey contextKey = iota
+	sessionContextKey
+	languageContextKey
+)
+
+// ContextWithUser returns a copy of ctx carrying the authenticated user
+func ContextWithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// UserFromContext returns the authenticated user, or nil for anonymous requests
+func UserFromContext(ctx context.Context) *User {
+	user, _ := ctx.Value(userContextKey).(*User)
+	return user
+}
+
+// ContextWithSessionID returns a copy of ctx carrying the ID of the session
+// the request's access token was issued for
+func ContextWithSessionID(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, sessionContextKey, sessionID)
+}
+
+// SessionIDFromContext returns the current session ID, or "" if unknown
+func SessionIDFromContext(ctx context.Context) string {
+	sessionID, _ := ctx.Value(sessionContextKey).(string)
+	return sessionID
+}
+
+// ContextWithLanguage returns a copy of ctx carrying the language, a BCP 47
+// primary tag like "en", that messages to the caller are written in
+func ContextWithLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, languageContextKey, language)
+}
+
+// LanguageFromContext returns the caller's language, or "" if unknown
+func LanguageFromContext(ctx context.Context) string {
+	language, _ := ctx.Value(languageContextKey).(string)
+	return language
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// EmissionFactor converts a quantity of an activity category into kg CO2e.
+// A factor with a region overrides the regionless one of the same category
+// for activities in that region. ValidFrom and ValidTo bound when it applies;
+// nil means unbounded.
+type EmissionFactor struct {
+	ID        string     `json:"id"`
+	Version   int        `json:"version"`
+	Category  string     `json:"category"`
+	Unit      string     `json:"unit"`
+	Factor    float64    `json:"factor"` // kg CO2e per unit
+	Source    string     `json:"source"`
+	Region    string     `json:"region,omitempty"` // ISO 3166-1 alpha-2
+	ValidFrom *time.Time `json:"valid_from,omitempty"`
+	ValidTo   *time.Time `json:"valid_to,omitempty"` // exclusive
+}
+
+// AppliesAt reports whether the factor is valid at t
+func (f *EmissionFactor) AppliesAt(t time.Time) bool {
+	return (f.ValidFrom == nil || !t.Before(*f.ValidFrom)) && (f.ValidTo == nil || t.Before(*f.ValidTo))
+}
+
+// EmissionFactorVersion is one release of the emission-factor catalogue. A
+// version is complete on its own rather than a patch of the previous one.
+// Versions are imported as drafts and become current once published; a
+// published version never changes.
+type EmissionFactorVersion struct {
+	Version     int        `json:"version"`
+	Note        string     `json:"note"`
+	FactorCount int        `json:"factor_count"`
+	CreatedAt   time.Time  `json:"created_at"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// EmissionFactorService is the storage contract for the versioned
+// emission-factor catalogue.
+type EmissionFactorService interface {
+	// CreateVersion stores a draft version with its factors and sets the
+	// version number on version and every factor.
+	CreateVersion(ctx context.Context, version *EmissionFactorVersion, factors []*EmissionFactor) error
+	GetVersion(ctx context.Context, version int) (*EmissionFactorVersion, error)
+	// ListVersions returns every version, newest first.
+	ListVersions(ctx context.Context) ([]*EmissionFactorVersion, error)
+	// Publish makes a draft the current version. It returns
+	// ErrFactorVersionPublished when it already was published.
+	Publish(ctx context.Context, version int) error
+	// CurrentVersion returns the most recently published version, or
+	// ErrFactorVersionNotFound before anything was published.
+	CurrentVersion(ctx context.Context) (*EmissionFactorVersion, error)
+	// ListFactors returns the factors of a version ordered by category and region.
+	ListFactors(ctx context.Context, version int) ([]*EmissionFactor, error)
+	// FindFactors returns the factors of a category in a version.
+	FindFactors(ctx context.Context, version int, category string) ([]*EmissionFactor, error)
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+)
+
+// Errors of the domain. Each has an errs.Kind, which decides how the API
+// reports it; ErrStatsNotCached never leaves the services.
+var (
+	ErrUserNotFound = errs.NotFound("user not found")
+	ErrUserConflict = errs.Conflict("user already exists")
+
+	ErrInvalidCredentials = errs.Unauthorized("invalid credentials")
+	ErrAccountLocked      = errs.RateLimited("too many failed login attempts, try again later", 0)
+
+	ErrSessionNotFound    = errs.NotFound("session not found or revoked")
+	ErrRefreshTokenReused = errs.Unauthorized("refresh token was already used; session revoked")
+
+	ErrIdentityNotFound  = errs.NotFound("identity not found")
+	ErrIdentityConflict  = errs.Conflict("provider account is already linked")
+	ErrInvalidOAuthState = errs.Validation("invalid or expired OAuth state")
+	ErrLastSignInMethod  = errs.Conflict("cannot remove the only way to sign in; set a password or link another provider first")
+
+	ErrOneTimeTokenInvalid  = errs.Invalid("token", "the link is invalid, has expired or was already used")
+	ErrEmailMissing         = errs.Conflict("the account has no email address")
+	ErrEmailAlreadyVerified = errs.Conflict("the email address is already verified")
+
+	ErrActivityNotFound       = errs.NotFound("activity not found")
+	ErrEmissionFactorNotFound = errs.NotFound("unknown activity category")
+	ErrFactorVersionNotFound  = errs.NotFound("emission factor version not found")
+	ErrFactorVersionPublished = errs.Conflict("emission factor version is already published")
+
+	ErrSurveyNotFound         = errs.NotFound("survey not found")
+	ErrSurveyResponseNotFound = errs.NotFound("survey not answered yet")
+
+	ErrTaskNotFound           = errs.NotFound("task not found")
+	ErrTaskAssignmentNotFound = errs.NotFound("task assignment not found")
+	ErrTaskAlreadyAssigned    = errs.Conflict("task is already assigned")
+	ErrTaskAlreadyCompleted   = errs.Conflict("task is already completed")
+	ErrTaskRepeatTooSoon      = errs.Conflict("task was already completed and can't be repeated yet")
+
+	ErrGoalNotFound = errs.NotFound("no goal for this week")
+	ErrGoalConflict = errs.Conflict("a goal for this week already exists")
+	ErrGoalClosed   = errs.Conflict("the goal's week is already closed")
+
+	ErrRecommendationNotFound = errs.NotFound("recommendation not found")
+
+	ErrRateLimited = errs.RateLimited("too many requests, try again later", 0)
+
+	ErrStatsNotCached = errors.New("stats not cached")
+
+	ErrRelationNotFound = errs.NotFound("no such friend or request")
+	ErrRelationConflict = errs.Conflict("the friend request was already sent or accepted")
+	ErrFollowNotFound   = errs.NotFound("not following this user")
+
+	ErrTeamNotFound       = errs.NotFound("team not found")
+	ErrTeamConflict       = errs.Conflict("invite code already in use")
+	ErrTeamMemberNotFound = errs.NotFound("not a member of this team")
+	ErrTeamMemberConflict = errs.Conflict("already a member of this team")
+	ErrTeamInviteNotFound = errs.NotFound("team invite not found")
+	ErrChallengeNotFound  = errs.NotFound("challenge not found")
+)
+
+// ConflictError reports which unique user attribute is already taken.
+// It wraps ErrUserConflict, naming the field.
+type ConflictError struct {
+	Field string
+	Value string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%s %q already in use", e.Field, e.Value)
+}
+
+func (e *ConflictError) Unwrap() error {
+	return &errs.Error{
+		Kind:    errs.KindConflict,
+		Message: e.Error(),
+		Fields:  []errs.FieldError{{Field: e.Field, Code: "already_in_use", Message: "already in use"}},
+		Err:     ErrUserConflict,
+	}
+}
+
+// RateLimitError reports when a rate-limited action is allowed again.
+// It wraps ErrRateLimited.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("too many requests, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return &errs.Error{
+		Kind:       errs.KindRateLimited,
+		Message:    e.Error(),
+		RetryAfter: e.RetryAfter,
+		Err:        ErrRateLimited,
+	}
+}
@@ -0,0 +1,128 @@
+// Package errs is the error model shared by every layer. Errors carry a Kind
+// that says what went wrong in terms a client can act on, and the API maps
+// each kind to an HTTP status once, in api/problem, instead of every handler
+// matching errors itself.
+//
+// Sentinels like domain.ErrUserNotFound are *Error values, so errors.Is keeps
+// working on them and on errors wrapping them with fmt.Errorf("%w: ...").
+package errs
+
+import (
+	"errors"
+	"time"
+)
+
+// Kind classifies an error
+type Kind string
+
+// Error kinds. Errors without one are internal.
+const (
+	KindValidation   Kind = "validation"   // the request is malformed or breaks a rule
+	KindUnauthorized Kind = "unauthorized" // the caller isn't signed in or their credentials are wrong
+	KindForbidden    Kind = "forbidden"    // the caller may not do this
+	KindNotFound     Kind = "not_found"    // the target doesn't exist or is hidden from the caller
+	KindConflict     Kind = "conflict"     // the target's current state doesn't allow this
+	KindRateLimited  Kind = "rate_limited" // the caller must wait before trying again
+	KindUpstream     Kind = "upstream"     // a service we depend on failed
+	KindInternal     Kind = "internal"     // a bug or an outage; details aren't shown
+)
+
+// FieldError explains what is wrong with one field of a request. Code names
+// the broken rule, like too_short, for clients that word messages themselves.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error is an error of a Kind. Its message is shown to clients unless the
+// kind is internal.
+type Error struct {
+	Kind       Kind
+	Message    string
+	Fields     []FieldError  // invalid or conflicting fields, if known
+	RetryAfter time.Duration // when to retry, for KindRateLimited
+	Err        error         // the cause, if any
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// New returns an error of kind
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+// Validation returns a validation error, optionally naming the fields at fault
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Message: message, Fields: fields}
+}
+
+// Invalid returns a validation error for a single field
+func Invalid(field, message string) *Error {
+	return Validation(message, FieldError{Field: field, Message: message})
+}
+
+// Unauthorized returns an error for missing or wrong credentials
+func Unauthorized(message string) *Error {
+	return New(KindUnauthorized, message)
+}
+
+// Forbidden returns an error for an action the caller may not take
+func Forbidden(message string) *Error {
+	return New(KindForbidden, message)
+}
+
+// NotFound returns an error for a missing target
+func NotFound(message string) *Error {
+	return New(KindNotFound, message)
+}
+
+// Conflict returns an error for a target whose state doesn't allow an action
+func Conflict(message string) *Error {
+	return New(KindConflict, message)
+}
+
+// RateLimited returns an error telling the caller to retry after retryAfter,
+// or later when it is zero
+func RateLimited(message string, retryAfter time.Duration) *Error {
+	return &Error{Kind: KindRateLimited, Message: message, RetryAfter: retryAfter}
+}
+
+// Upstream returns an error for a failed dependency
+func Upstream(message string) *Error {
+	return New(KindUpstream, message)
+}
+
+// WithKind reclassifies err where the caller knows better than its origin,
+// such as an unknown session being a failed sign-in rather than a missing
+// resource. err stays matchable with errors.Is.
+func WithKind(kind Kind, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Err: err}
+}
+
+// From returns the outermost *Error in err's chain, or an internal error
+// wrapping err if there is none
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return e
+	}
+	return &Error{Kind: KindInternal, Err: err}
+}
+
+// KindOf returns the kind of err, KindInternal for unclassified errors
+func KindOf(err error) Kind {
+	return From(err).Kind
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Domain event types
+const (
+	EventActivityLogged = "activity_logged"
+	EventTaskCompleted  = "task_completed"
+	EventGoalClosed     = "goal_closed"
+)
+
+// Event reports that something happened to a user, after it was stored
+type Event struct {
+	Type   string
+	UserID string
+	At     time.Time
+}
+
+// EventHandler reacts to domain events
+type EventHandler interface {
+	Handle(ctx context.Context, event Event) error
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Weekly goal statuses
+const (
+	GoalActive   = "active"
+	GoalAchieved = "achieved"
+	GoalMissed   = "missed"
+)
+
+// WeeklyGoal is a user's CO2 reduction target for one ISO week in their
+// timezone. Progress is computed live while the goal is active and frozen
+// when the scheduler closes the week.
+type WeeklyGoal struct {
+	ID        string       `json:"id"`
+	UserID    string       `json:"-"`
+	Week      string       `json:"week"` // ISO week, e.g. 2026-W42
+	Timezone  string       `json:"timezone"`
+	StartsAt  time.Time    `json:"starts_at"`
+	EndsAt    time.Time    `json:"ends_at"`    // exclusive
+	TargetCO2 float64      `json:"target_co2"` // kg CO2e to save
+	Proposed  bool         `json:"proposed"`   // set by rollover and not yet confirmed by the user
+	Status    string       `json:"status"`
+	Progress  GoalProgress `json:"progress"`
+	CreatedAt time.Time    `json:"created_at"`
+	ClosedAt  *time.Time   `json:"closed_at,omitempty"`
+}
+
+// GoalProgress measures a week against its goal. Reduction counts the CO2
+// saved by completed tasks plus, when activities were logged that week,
+// how far they stayed below the previous week's.
+type GoalProgress struct {
+	Emitted      float64 `json:"emitted"`        // kg CO2e of logged activities
+	PrevEmitted  float64 `json:"prev_emitted"`   // kg CO2e of activities logged the week before
+	SavedByTasks float64 `json:"saved_by_tasks"` // kg CO2e of completed tasks
+	Reduction    float64 `json:"reduction"`      // kg CO2e counted towards the target
+	Percent      float64 `json:"percent"`        // of the target, capped at 100
+}
+
+// GoalService is the storage contract for weekly goals. A user has at most
+// one goal per week.
+type GoalService interface {
+	// Create fails with ErrGoalConflict if the user has a goal for the week.
+	Create(ctx context.Context, goal *WeeklyGoal) error
+	Get(ctx context.Context, userID, week string) (*WeeklyGoal, error)
+	// List returns the user's goals, most recent week first.
+	List(ctx context.Context, userID string, limit int) ([]*WeeklyGoal, error)
+	Update(ctx context.Context, goal *WeeklyGoal) error
+	// ListDue returns active goals of any user whose week ended by now,
+	// oldest first.
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*WeeklyGoal, error)
+}
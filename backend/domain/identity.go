@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Identity links an account at an external sign-in provider to a user.
+// A user has at most one identity per provider.
+type Identity struct {
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"-"` // the provider's stable user ID
+	UserID    string    `json:"-"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// IdentityService is the storage contract for provider identities.
+type IdentityService interface {
+	// Create stores a new identity. It returns ErrIdentityConflict when the
+	// provider account or the user's slot for that provider is taken.
+	Create(ctx context.Context, identity *Identity) error
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*Identity, error)
+	ListByUser(ctx context.Context, userID string) ([]*Identity, error)
+	Delete(ctx context.Context, userID, provider string) error
+}
+
+// OAuthState is the server-side half of an OAuth authorization request,
+// looked up by the state parameter when the provider redirects back.
+type OAuthState struct {
+	State        string    `json:"state"`
+	Provider     string    `json:"provider"`
+	CodeVerifier string    `json:"code_verifier"`          // PKCE verifier
+	LinkUserID   string    `json:"link_user_id,omitempty"` // set when linking to a signed-in user
+	BindingHash  string    `json:"binding_hash"`           // MAC of the cookie tying the request to the browser that started it
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// OAuthStateService stores pending OAuth authorization requests.
+type OAuthStateService interface {
+	Save(ctx context.Context, state *OAuthState) error
+	// Take returns and deletes a state so it can only be used once. It
+	// returns ErrInvalidOAuthState when the state is unknown or expired.
+	Take(ctx context.Context, state string) (*OAuthState, error)
+}
@@ -0,0 +1,69 @@
+package domain
+
+import "context"
+
+// Leaderboard metrics
+const (
+	MetricPoints   = "points"
+	MetricCO2Saved = "co2_saved"
+)
+
+// Board identifies a leaderboard: users ranked by Metric over all time, or
+// over one ISO week when Week is set
+type Board struct {
+	Metric string
+	Week   string // e.g. 2026-W42, in UTC
+}
+
+// LeaderboardEntry is a user's standing on a board. Rank starts at 1 and is
+// 0 for users who haven't scored yet. Private entries belong to users who
+// don't share their CO2 savings with the viewer: on CO2 boards they are
+// anonymous, without Name and Points, on other boards without CO2Saved.
+type LeaderboardEntry struct {
+	Rank     int64   `json:"rank"`
+	UserID   string  `json:"-"`
+	Name     string  `json:"name,omitempty"`
+	Points   int     `json:"points"`
+	CO2Saved float64 `json:"co2_saved"`
+	Private  bool    `json:"private,omitempty"`
+}
+
+// LeaderboardPage is one page of a board as seen by a user
+type LeaderboardPage struct {
+	Entries []*LeaderboardEntry `json:"entries"`
+	Total   int64               `json:"total"`        // users on the board
+	Me      *LeaderboardEntry   `json:"me,omitempty"` // the viewer's own standing
+}
+
+// UserAward is what a user was awarded over some period
+type UserAward struct {
+	UserID   string
+	Points   int
+	CO2Saved float64
+}
+
+// LeaderboardService is the storage contract for leaderboards. Both metrics
+// of a period are written together.
+type LeaderboardService interface {
+	// Add adds an award to the all-time boards and those of week.
+	Add(ctx context.Context, week string, award UserAward) error
+	// Top returns a page of the board, best first, and how many users it holds.
+	Top(ctx context.Context, board Board, offset, limit int) ([]*LeaderboardEntry, int64, error)
+	// Standings returns the given users' entries on the board, in order.
+	Standings(ctx context.Context, board Board, userIDs []string) ([]*LeaderboardEntry, error)
+	// Replace swaps the boards of week, or the all-time boards when week is
+	// empty, for awards.
+	Replace(ctx context.Context, week string, awards []UserAward) error
+}
+
+// FriendLister lists whose standings a user sees on the friends leaderboard
+type FriendLister interface {
+	FriendIDs(ctx context.Context, userID string) ([]string, error)
+}
+
+// PrivacyReader reads users' friends and privacy settings, to decide whose
+// CO2 savings a viewer sees on leaderboards
+type PrivacyReader interface {
+	FriendLister
+	Privacy(ctx context.Context, userID string) (*PrivacySettings, error)
+}
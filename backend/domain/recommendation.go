@@ -0,0 +1,22 @@
+package domain
+
+import "context"
+
+// Recommendation is an action suggested to a user with its estimated effect
+// and why it was suggested
+type Recommendation struct {
+	ID            string  `json:"id"`
+	Title         string  `json:"title"`
+	Category      string  `json:"category"`
+	Explanation   string  `json:"explanation"`
+	AnnualSavings float64 `json:"annual_savings"` // estimated kg CO2e per year
+	Feasibility   float64 `json:"feasibility"`    // 0 to 1, how easy it is to adopt
+	Score         float64 `json:"score"`          // savings weighted by feasibility, used for ranking
+}
+
+// RecommendationService is the storage contract for recommendations users
+// dismissed, which aren't suggested to them again
+type RecommendationService interface {
+	Dismiss(ctx context.Context, userID, recommendationID string) error
+	Dismissed(ctx context.Context, userID string) ([]string, error)
+}
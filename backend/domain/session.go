@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Session is one signed-in device. Every refresh token issued to the device
+// belongs to the session, so revoking the session revokes the whole token
+// family.
+type Session struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"-"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// RefreshToken is a stored refresh token. Only the hash of the token is
+// kept so a leaked store can't be replayed.
+type RefreshToken struct {
+	Hash      string    `json:"hash"`
+	SessionID string    `json:"session_id"`
+	UserID    string    `json:"user_id"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SessionService is the storage contract for sessions and refresh tokens.
+type SessionService interface {
+	// SaveSession creates or updates the session and stores token as its
+	// newest refresh token.
+	SaveSession(ctx context.Context, session *Session, token *RefreshToken) error
+	// RotateSession updates a live session and stores token as its newest
+	// refresh token in one step. It returns ErrSessionNotFound, storing
+	// nothing, once the session was revoked, so a rotation racing a
+	// revocation can't bring the session back.
+	RotateSession(ctx context.Context, session *Session, token *RefreshToken) error
+	// ConsumeRefreshToken marks a token used and returns it with its session.
+	// A token that was already used returns ErrRefreshTokenReused along with
+	// the token so the caller can revoke its session; unknown or expired
+	// tokens and revoked sessions return ErrSessionNotFound.
+	ConsumeRefreshToken(ctx context.Context, tokenHash string) (*RefreshToken, *Session, error)
+	GetSession(ctx context.Context, sessionID string) (*Session, error)
+	ListSessions(ctx context.Context, userID string) ([]*Session, error)
+	DeleteSession(ctx context.Context, userID, sessionID string) error
+	DeleteUserSessions(ctx context.Context, userID string) error
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Relation statuses
+const (
+	RelationPending = "pending" // UserID asked OtherID to be friends
+	RelationFriends = "friends" // OtherID accepted UserID's request
+	RelationBlocked = "blocked" // UserID blocked OtherID
+)
+
+// Privacy levels, who besides the user may see a kind of their data
+const (
+	PrivacyPublic  = "public"
+	PrivacyFriends = "friends"
+	PrivacyPrivate = "private"
+)
+
+// Relation is a directed edge between two users. Friendship is stored once,
+// in the direction of the request that started it.
+type Relation struct {
+	UserID    string    `json:"-"`
+	OtherID   string    `json:"-"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PrivacySettings control what other users see of a user
+type PrivacySettings struct {
+	CO2      string `json:"co2"`      // footprint, baseline and savings
+	Activity string `json:"activity"` // logged activities
+}
+
+// DefaultPrivacy applies to users who haven't chosen their settings
+var DefaultPrivacy = PrivacySettings{CO2: PrivacyFriends, Activity: PrivacyFriends}
+
+// Contact is another user in one of a user's lists, such as their friends
+// or followers, with when they got there
+type Contact struct {
+	UserID string    `json:"-"`
+	Login  string    `json:"login"`
+	Since  time.Time `json:"since"`
+}
+
+// Profile relations, how a viewer relates to the owner of a profile
+const (
+	ProfileSelf            = "self"
+	ProfileFriends         = RelationFriends
+	ProfileRequestSent     = "request_sent"
+	ProfileRequestReceived = "request_received"
+	ProfileNone            = "none" // includes anonymous viewers
+)
+
+// Profile is a user as seen by another user, an anonymous visitor or
+// themself: the owner's record with the viewer's relation to them and the
+// owner's privacy settings. The API decides from it which fields to show.
+type Profile struct {
+	User             *User
+	Relation         string
+	Following        bool // the viewer follows the owner
+	FollowsYou       bool // the owner follows the viewer
+	Followers        int
+	FollowingCount   int
+	Privacy          PrivacySettings
+	RecentActivities []*Activity // loaded only when Shows(Privacy.Activity)
+}
+
+// Shows reports whether data at a privacy level is visible to the viewer
+func (p *Profile) Shows(level string) bool {
+	return p.Relation == ProfileSelf ||
+		level == PrivacyPublic ||
+		(level == PrivacyFriends && p.Relation == ProfileFriends)
+}
+
+// SocialService is the storage contract for relations between users,
+// follows and privacy settings
+type SocialService interface {
+	// Between returns the relations between a and b in either direction.
+	Between(ctx context.Context, a, b string) ([]*Relation, error)
+	// SaveRelation creates or replaces the relation from UserID to OtherID.
+	SaveRelation(ctx context.Context, relation *Relation) error
+	// DeleteRelation fails with ErrRelationNotFound if there is none.
+	DeleteRelation(ctx context.Context, userID, otherID string) error
+	// Relations returns the user's relations with status in either
+	// direction, most recently updated first.
+	Relations(ctx context.Context, userID, status string) ([]*Relation, error)
+
+	// Follow is a no-op if the user already follows followeeID.
+	Follow(ctx context.Context, followerID, followeeID string) error
+	// Unfollow fails with ErrFollowNotFound if there is nothing to undo.
+	Unfollow(ctx context.Context, followerID, followeeID string) error
+	IsFollowing(ctx context.Context, followerID, followeeID string) (bool, error)
+	// Followers returns who follows the user, most recent first, with
+	// Contact.Login left empty.
+	Followers(ctx context.Context, userID string) ([]*Contact, error)
+	// Following returns whom the user follows, most recent first, with
+	// Contact.Login left empty.
+	Following(ctx context.Context, userID string) ([]*Contact, error)
+
+	// Privacy returns DefaultPrivacy for users without settings.
+	Privacy(ctx context.Context, userID string) (*PrivacySettings, error)
+	SetPrivacy(ctx context.Context, userID string, settings *PrivacySettings) error
+}
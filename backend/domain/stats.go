@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Stats periods, the size of one point of a time series
+const (
+	StatsDay   = "day"
+	StatsWeek  = "week" // ISO weeks, starting on Monday
+	StatsMonth = "month"
+)
+
+// StatsPoint is the CO2 logged in one period of a time series
+type StatsPoint struct {
+	Start      time.Time `json:"start"`
+	CO2        float64   `json:"co2"` // kg CO2e
+	Activities int       `json:"activities"`
+}
+
+// CategoryTotal is the CO2 logged for one activity category
+type CategoryTotal struct {
+	Category   string  `json:"category"`
+	CO2        float64 `json:"co2"`   // kg CO2e
+	Share      float64 `json:"share"` // percent of the period's total
+	Activities int     `json:"activities"`
+}
+
+// StatsComparison relates the CO2 logged over a period to references
+// prorated to the same length. Differences are in percent and negative when
+// the user emitted less.
+type StatsComparison struct {
+	Region            string   `json:"region"` // ISO 3166-1 alpha-2, or WORLD
+	NationalAverage   float64  `json:"national_average"`
+	VsNationalAverage float64  `json:"vs_national_average"`
+	Baseline          *float64 `json:"baseline,omitempty"` // from the onboarding survey, if answered
+	VsBaseline        *float64 `json:"vs_baseline,omitempty"`
+}
+
+// StatsStreak counts consecutive days with at least one logged activity.
+// The current streak survives until the end of the day after the last one.
+type StatsStreak struct {
+	Current    int    `json:"current"`
+	Longest    int    `json:"longest"`
+	LastActive string `json:"last_active,omitempty"` // YYYY-MM-DD
+}
+
+// Stats summarises a user's logged footprint over a period
+type Stats struct {
+	Period     string          `json:"period"`
+	Timezone   string          `json:"timezone"`
+	From       time.Time       `json:"from"`
+	To         time.Time       `json:"to"` // exclusive
+	Total      float64         `json:"total"`
+	Series     []StatsPoint    `json:"series"`
+	Categories []CategoryTotal `json:"categories"`
+	Comparison StatsComparison `json:"comparison"`
+	Streak     StatsStreak     `json:"streak"`
+}
+
+// StatsAggregates are the activity aggregations behind Stats, cached until
+// the user's activities change
+type StatsAggregates struct {
+	Series     []StatsPoint    `json:"series"`
+	Categories []CategoryTotal `json:"categories"`
+	ActiveDays []string        `json:"active_days"`
+}
+
+// StatsService aggregates a user's activities in storage
+type StatsService interface {
+	// Series sums activities in [from, to) into periods starting at
+	// midnight in loc. Empty periods are left out.
+	Series(ctx context.Context, userID, period string, loc *time.Location, from, to time.Time) ([]StatsPoint, error)
+	// Breakdown sums activities in [from, to) by category, largest first.
+	Breakdown(ctx context.Context, userID string, from, to time.Time) ([]CategoryTotal, error)
+	// ActiveDays returns the dates in loc with a logged activity, most
+	// recent first, as YYYY-MM-DD.
+	ActiveDays(ctx context.Context, userID string, loc *time.Location) ([]string, error)
+}
+
+// StatsCacheService caches StatsAggregates per user under a query key
+type StatsCacheService interface {
+	// Get returns ErrStatsNotCached on a miss.
+	Get(ctx context.Context, userID, key string) (*StatsAggregates, error)
+	Set(ctx context.Context, userID, key string, aggregates *StatsAggregates) error
+	// Invalidate drops every cached entry of the user.
+	Invalidate(ctx context.Context, userID string) error
+}
@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Survey question types
+const (
+	QuestionSingleChoice = "single_choice"
+	QuestionMultiChoice  = "multi_choice"
+	QuestionNumeric      = "numeric"
+)
+
+// Survey is a questionnaire that estimates a user's annual footprint.
+// Questions are asked in order; a question with ShowIf is only asked when
+// an earlier answer matches it.
+type Survey struct {
+	ID        string      `json:"id"`
+	Title     string      `json:"title"`
+	Questions []*Question `json:"questions"`
+}
+
+// Question is one survey question. Choice options carry the annual kg CO2e
+// they add; numeric answers are multiplied by Factor, or by the factor of
+// the option chosen for FactorBy.QuestionID.
+type Question struct {
+	ID       string     `json:"id"`
+	Category string     `json:"category"` // footprint breakdown bucket, e.g. transport
+	Text     string     `json:"text"`
+	Type     string     `json:"type"`
+	Options  []*Option  `json:"options,omitempty"`
+	Min      float64    `json:"min,omitempty"`
+	Max      float64    `json:"max,omitempty"`
+	Unit     string     `json:"unit,omitempty"`
+	Optional bool       `json:"optional,omitempty"`
+	ShowIf   *Condition `json:"show_if,omitempty"`
+	Factor   float64    `json:"-"`
+	FactorBy *FactorBy  `json:"-"`
+}
+
+// Option is a possible answer to a choice question
+type Option struct {
+	ID    string  `json:"id"`
+	Label string  `json:"label"`
+	Score float64 `json:"-"` // annual kg CO2e
+}
+
+// Condition holds when a question applies: the earlier question was answered
+// with any of Options.
+type Condition struct {
+	QuestionID string   `json:"question_id"`
+	Options    []string `json:"options"`
+}
+
+// FactorBy picks a numeric question's factor from the option chosen for an
+// earlier single-choice question
+type FactorBy struct {
+	QuestionID string
+	Factors    map[string]float64 // option ID -> annual kg CO2e per unit
+}
+
+// Answer is the answer to one question: option IDs for choice questions,
+// Value for numeric ones.
+type Answer struct {
+	Options []string `json:"options,omitempty"`
+	Value   *float64 `json:"value,omitempty"`
+}
+
+// SurveyResult is the footprint estimated from a survey response
+type SurveyResult struct {
+	AnnualCO2e float64            `json:"annual_co2e"` // kg CO2e per year
+	Breakdown  map[string]float64 `json:"breakdown"`   // by question category
+}
+
+// SurveyResponse is a user's submitted answers and the result they scored
+type SurveyResponse struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"-"`
+	SurveyID  string            `json:"survey_id"`
+	Answers   map[string]Answer `json:"answers"`
+	Result    SurveyResult      `json:"result"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// SurveyResponseService is the storage contract for survey responses.
+type SurveyResponseService interface {
+	// Create stores a response and sets the user's User.CO2Baseline to its
+	// result in the same transaction.
+	Create(ctx context.Context, response *SurveyResponse) error
+	// Latest returns the user's most recent response to a survey, or
+	// ErrSurveyResponseNotFound.
+	Latest(ctx context.Context, userID, surveyID string) (*SurveyResponse, error)
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Task difficulties
+const (
+	TaskEasy   = "easy"
+	TaskMedium = "medium"
+	TaskHard   = "hard"
+)
+
+// Task repeat policies: how often a user can complete the same task
+const (
+	TaskOnce   = "once"
+	TaskDaily  = "daily"
+	TaskWeekly = "weekly"
+)
+
+// Task assignment statuses
+const (
+	TaskAssigned  = "assigned"
+	TaskCompleted = "completed"
+)
+
+// Task is an eco action from the catalogue, such as skipping the car for a
+// day. Inactive tasks stay in place for existing assignments but can no
+// longer be picked.
+type Task struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	CO2Saved    float64   `json:"co2_saved"` // estimated kg CO2e saved by completing it
+	Difficulty  string    `json:"difficulty"`
+	Points      int       `json:"points"`
+	Repeat      string    `json:"repeat"` // TaskOnce, TaskDaily or TaskWeekly
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Period names the repeat period of the task that at falls in, in UTC. A
+// user can complete the task once per period.
+func (t *Task) Period(at time.Time) string {
+	at = at.UTC()
+	switch t.Repeat {
+	case TaskDaily:
+		return at.Format(time.DateOnly)
+	case TaskWeekly:
+		year, week := at.ISOWeek()
+		return fmt.Sprintf("%04d-W%02d", year, week)
+	default:
+		return TaskOnce
+	}
+}
+
+// TaskFilter narrows a catalogue listing. Zero values don't filter.
+type TaskFilter struct {
+	Category        string
+	Difficulty      string
+	IncludeInactive bool
+}
+
+// TaskAssignment is a task a user has taken on. Points and CO2Saved are
+// copied from the task on completion, so later catalogue edits don't change
+// what was awarded.
+type TaskAssignment struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"-"`
+	Task        Task       `json:"task"`
+	Status      string     `json:"status"`
+	Proof       string     `json:"proof,omitempty"` // optional note or photo URL
+	Points      int        `json:"points"`
+	CO2Saved    float64    `json:"co2_saved"`
+	Period      string     `json:"-"` // repeat period it was completed in
+	AssignedAt  time.Time  `json:"assigned_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// TaskService is the storage contract for the task catalogue and per-user
+// assignments. Completing an assignment adds its points and CO2 savings to
+// the user's totals in the same transaction.
+type TaskService interface {
+	CreateTask(ctx context.Context, task *Task) error
+	UpdateTask(ctx context.Context, task *Task) error
+	GetTask(ctx context.Context, id string) (*Task, error)
+	ListTasks(ctx context.Context, filter TaskFilter) ([]*Task, error)
+
+	// Assign fails with ErrTaskAlreadyAssigned while the user has the same
+	// task open; completed tasks can be taken on again.
+	Assign(ctx context.Context, assignment *TaskAssignment) error
+	GetAssignment(ctx context.Context, userID, id string) (*TaskAssignment, error)
+	// ListAssignments returns the user's assignments, most recent first,
+	// optionally only those with the given status.
+	ListAssignments(ctx context.Context, userID, status string) ([]*TaskAssignment, error)
+	// Complete records the repeat period the assignment was completed in and
+	// fails with ErrTaskRepeatTooSoon when the user already completed the
+	// task in that period.
+	Complete(ctx context.Context, userID, id, proof, period string) (*TaskAssignment, error)
+	// Awards sums the points and CO2 savings of assignments completed in
+	// [from, to) per active user; zero times leave that end open.
+	Awards(ctx context.Context, from, to time.Time) ([]UserAward, error)
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Team member roles. Admins invite people and run challenges; the one owner
+// can also change roles and delete the team.
+const (
+	TeamRoleOwner  = "owner"
+	TeamRoleAdmin  = "admin"
+	TeamRoleMember = "member"
+)
+
+// Challenge statuses, from the challenge's time box
+const (
+	ChallengeUpcoming = "upcoming"
+	ChallengeActive   = "active"
+	ChallengeFinished = "finished"
+)
+
+// Team is a group of users, such as an office or a class, who save CO2
+// together. Anyone with the invite code can join.
+type Team struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	InviteCode  string      `json:"invite_code,omitempty"` // shown to admins only
+	CreatedAt   time.Time   `json:"created_at"`
+	Role        string      `json:"role,omitempty"`   // the viewer's role
+	Totals      *TeamTotals `json:"totals,omitempty"` // filled in by the service
+}
+
+// TeamTotals adds up what a team's current members were awarded over all time
+type TeamTotals struct {
+	Members  int     `json:"members"`
+	Points   int     `json:"points"`
+	CO2Saved float64 `json:"co2_saved"`
+}
+
+// TeamMember is a user's membership of a team. Login and the user's totals
+// are filled in by the service; CO2Saved is left out and Private set when
+// the member doesn't share their CO2 savings with the viewer.
+type TeamMember struct {
+	TeamID   string    `json:"-"`
+	UserID   string    `json:"-"`
+	Login    string    `json:"login"`
+	Role     string    `json:"role"`
+	Points   int       `json:"points"`
+	CO2Saved float64   `json:"co2_saved"`
+	Private  bool      `json:"private,omitempty"`
+	JoinedAt time.Time `json:"joined_at"`
+}
+
+// TeamInvite is a pending invitation of a user to a team. TeamName is
+// filled in by the service.
+type TeamInvite struct {
+	TeamID    string    `json:"team_id"`
+	TeamName  string    `json:"team_name"`
+	UserID    string    `json:"-"`
+	InvitedBy string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Challenge is a time-boxed team goal: members' awards for tasks completed
+// in [StartsAt, EndsAt), measured by Metric, add up towards Target
+type Challenge struct {
+	ID          string    `json:"id"`
+	TeamID      string    `json:"-"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Metric      string    `json:"metric"` // MetricPoints or MetricCO2Saved
+	Target      float64   `json:"target"`
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	CreatedBy   string    `json:"-"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	// Filled in by the service
+	Status      string              `json:"status"`
+	Progress    float64             `json:"progress"`
+	Leaderboard []*LeaderboardEntry `json:"leaderboard,omitempty"`
+}
+
+// TeamService is the storage contract for teams, their members, invites and
+// challenges. Deleting a team deletes everything that belongs to it.
+type TeamService interface {
+	// CreateTeam stores a team with its owner; it fails with
+	// ErrTeamConflict when the invite code is taken.
+	CreateTeam(ctx context.Context, team *Team, owner *TeamMember) error
+	GetTeam(ctx context.Context, id string) (*Team, error)
+	TeamByCode(ctx context.Context, code string) (*Team, error)
+	// UpdateTeam saves a team's name, description and invite code.
+	UpdateTeam(ctx context.Context, team *Team) error
+	DeleteTeam(ctx context.Context, id string) error
+	// Teams returns the teams the user is a member of, most recently joined first.
+	Teams(ctx context.Context, userID string) ([]*Team, error)
+
+	// AddMember fails with ErrTeamMemberConflict for existing members and
+	// withdraws any invite of the user to the team.
+	AddMember(ctx context.Context, member *TeamMember) error
+	GetMember(ctx context.Context, teamID, userID string) (*TeamMember, error)
+	// Members returns a team's members in the order they joined.
+	Members(ctx context.Context, teamID string) ([]*TeamMember, error)
+	SetRole(ctx context.Context, teamID, userID, role string) error
+	RemoveMember(ctx context.Context, teamID, userID string) error
+
+	// SaveInvite invites a user to a team; inviting them again is a no-op.
+	SaveInvite(ctx context.Context, invite *TeamInvite) error
+	// Invites returns the user's pending invites, most recent first.
+	Invites(ctx context.Context, userID string) ([]*TeamInvite, error)
+	DeleteInvite(ctx context.Context, teamID, userID string) error
+
+	CreateChallenge(ctx context.Context, challenge *Challenge) error
+	GetChallenge(ctx context.Context, teamID, id string) (*Challenge, error)
+	// Challenges returns a team's challenges, latest start first.
+	Challenges(ctx context.Context, teamID string) ([]*Challenge, error)
+	DeleteChallenge(ctx context.Context, teamID, id string) error
+}
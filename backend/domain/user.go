@@ -1,8 +1,20 @@
+package domain
+
 type User struct {
-    ID          string  `json:"id"`
-    Login       string  `json:"login"`
-    Email       string  `json:"email"`
-    PhoneNumber string  `json:"phone_number"`
-    Password    string  `json:"-"`
-    CO2         float64 `json:"co2"` 
-}
\ No newline at end of file
+    ID            string  `json:"id"`
+    Login         string  `json:"login"`
+    Email         string  `json:"email"`
+    PhoneNumber   string  `json:"phone_number"`
+    EmailVerified bool    `json:"email_verified"` // confirmed with an emailed link; cleared when Email changes
+    Password      string  `json:"-"`
+    CO2           float64 `json:"co2"`          // kg CO2e of logged activities
+    CO2Baseline   float64 `json:"co2_baseline"` // estimated annual kg CO2e from the onboarding survey
+    Points        int     `json:"points"`       // awarded for completed tasks
+    CO2Saved      float64 `json:"co2_saved"`    // estimated kg CO2e saved by completed tasks
+}
+
+// UserUpdate lists the user attributes to change; nil fields stay as they are
+type UserUpdate struct {
+    Email       *string
+    PhoneNumber *string
+}
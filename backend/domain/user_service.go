@@ -1,7 +1,49 @@
-package repository
+package domain
 
+import (
+	"context"
+	"time"
+)
+
+// UserService is the storage contract for users. Lookups never return
+// soft-deleted users.
 type UserService interface {
-	Get(login string) (*User, error)
-	GetByEmail(email string) (*User, error)
-	GetByPhoneNumber(phone_number string) (*User, error)
+	Create(ctx context.Context, user *User) error
+	GetByID(ctx context.Context, id string) (*User, error)
+	Get(ctx context.Context, login string) (*User, error)
+	GetByEmail(ctx context.Context, email string) (*User, error)
+	GetByPhoneNumber(ctx context.Context, phoneNumber string) (*User, error)
+	GetPasswordHash(ctx context.Context, login string) (string, error)
+	// ListIDs returns the IDs of every active user, for batch jobs.
+	ListIDs(ctx context.Context) ([]string, error)
+	UpdateUser(ctx context.Context, user *User) error
+	// SetPassword replaces the bcrypt hash of an active user's password.
+	SetPassword(ctx context.Context, login, passwordHash string) error
+	// SetEmailVerified marks an active user's email verified if it is still
+	// email, and returns ErrUserNotFound otherwise.
+	SetEmailVerified(ctx context.Context, login, email string) error
+	Delete(ctx context.Context, login string) error
+	Restore(ctx context.Context, login string) error
+}
+
+// LoginAttemptService counts failed sign-ins per key within a window so
+// repeated failures can lock an account.
+type LoginAttemptService interface {
+	// RegisterFailure records a failed attempt and returns the number of
+	// failures in the current window, which starts with the first failure.
+	RegisterFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	// Failures returns the number of failures in the current window.
+	Failures(ctx context.Context, key string) (int, error)
+	// Reset forgets all failures for key.
+	Reset(ctx context.Context, key string) error
+}
+
+// OneTimeTokenService tracks the emailed tokens that are still usable. A user
+// has at most one per purpose, so requesting a new link voids the last one.
+type OneTimeTokenService interface {
+	// Save makes id the user's only usable token for purpose until ttl passes.
+	Save(ctx context.Context, purpose, userID, id string, ttl time.Duration) error
+	// Take uses up the token, returning ErrOneTimeTokenInvalid unless id is
+	// the user's usable token for purpose.
+	Take(ctx context.Context, purpose, userID, id string) error
 }
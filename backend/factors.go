@@ -0,0 +1,99 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "log"
+    "os"
+    "path/filepath"
+    "strconv"
+    "strings"
+
+    "github.com/aygoko/EcoMInd/backend/config"
+    "github.com/aygoko/EcoMInd/backend/repository"
+    "github.com/aygoko/EcoMInd/backend/repository/postgres"
+    "github.com/aygoko/EcoMInd/backend/usecases/service"
+)
+
+const factorsUsage = "usage: ecomind factors [flags] import [-note text] [-publish] FILE.csv|FILE.json | publish VERSION | list"
+
+// runFactors implements the factors subcommand, which manages the
+// emission-factor catalogue from the command line
+func runFactors(cfg *config.Config, args []string) error {
+    if cfg.Storage != "postgres" {
+        return fmt.Errorf("factors needs storage postgres, configured storage is %q", cfg.Storage)
+    }
+    if len(args) == 0 {
+        return fmt.Errorf(factorsUsage)
+    }
+
+    ctx := context.Background()
+    pgDB := openPostgres(cfg)
+    defer pgDB.Close()
+    factors := service.NewEmissionFactorService(postgres.NewEmissionFactorRepository(pgDB, repository.DefaultLogger))
+
+    switch args[0] {
+    case "import":
+        fs := flag.NewFlagSet("factors import", flag.ContinueOnError)
+        note := fs.String("note", "", "Description of the new version")
+        publish := fs.Bool("publish", false, "Publish the version right away")
+        if err := fs.Parse(args[1:]); err != nil {
+            return err
+        }
+        if fs.NArg() != 1 {
+            return fmt.Errorf(factorsUsage)
+        }
+        path := fs.Arg(0)
+
+        file, err := os.Open(path)
+        if err != nil {
+            return err
+        }
+        defer file.Close()
+        parsed, err := service.ParseEmissionFactors(file, strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), "."))
+        if err != nil {
+            return err
+        }
+        version, err := factors.Import(ctx, *note, parsed)
+        if err != nil {
+            return err
+        }
+        log.Printf("Imported %d factor(s) as version %d", version.FactorCount, version.Version)
+        if *publish {
+            if err := factors.Publish(ctx, version.Version); err != nil {
+                return err
+            }
+            log.Printf("Published version %d", version.Version)
+        }
+        return nil
+    case "publish":
+        if len(args) != 2 {
+            return fmt.Errorf(factorsUsage)
+        }
+        version, err := strconv.Atoi(args[1])
+        if err != nil {
+            return fmt.Errorf("invalid version %q: %w", args[1], err)
+        }
+        if err := factors.Publish(ctx, version); err != nil {
+            return err
+        }
+        log.Printf("Published version %d", version)
+        return nil
+    case "list":
+        versions, err := factors.ListVersions(ctx)
+        if err != nil {
+            return err
+        }
+        for _, v := range versions {
+            state := "draft"
+            if v.PublishedAt != nil {
+                state = "published " + v.PublishedAt.Format("2006-01-02 15:04:05")
+            }
+            fmt.Printf("%4d  %4d factor(s)  %-28s %s\n", v.Version, v.FactorCount, state, v.Note)
+        }
+        return nil
+    default:
+        return fmt.Errorf(factorsUsage)
+    }
+}
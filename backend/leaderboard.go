@@ -0,0 +1,52 @@
+package main
+
+import (
+    "context"
+    "flag"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/aygoko/EcoMInd/backend/config"
+    "github.com/aygoko/EcoMInd/backend/repository"
+    "github.com/aygoko/EcoMInd/backend/repository/postgres"
+    "github.com/aygoko/EcoMInd/backend/repository/redis_storage"
+    "github.com/aygoko/EcoMInd/backend/usecases/service"
+)
+
+const leaderboardUsage = "usage: ecomind leaderboard [flags] rebuild [-weeks n]"
+
+// runLeaderboard implements the leaderboard subcommand, which rebuilds the
+// Redis leaderboards from the task history in Postgres
+func runLeaderboard(cfg *config.Config, args []string) error {
+    if cfg.Storage != "postgres" {
+        return fmt.Errorf("leaderboard needs storage postgres, configured storage is %q", cfg.Storage)
+    }
+    if len(args) == 0 || args[0] != "rebuild" {
+        return fmt.Errorf(leaderboardUsage)
+    }
+    fs := flag.NewFlagSet("leaderboard rebuild", flag.ContinueOnError)
+    weeks := fs.Int("weeks", 8, "Number of most recent weekly boards to rebuild")
+    if err := fs.Parse(args[1:]); err != nil {
+        return err
+    }
+    if fs.NArg() != 0 || *weeks < 0 {
+        return fmt.Errorf(leaderboardUsage)
+    }
+
+    pgDB := openPostgres(cfg)
+    defer pgDB.Close()
+    redisClient := openRedis(cfg)
+    defer redisClient.Close()
+
+    leaderboard := service.NewLeaderboardService(
+        redis_storage.NewLeaderboardRepository(redisClient, repository.DefaultLogger),
+        postgres.NewUserRepository(pgDB, redisClient, cfg.Redis.CacheTTL, repository.DefaultLogger),
+        postgres.NewTaskRepository(pgDB, redisClient, repository.DefaultLogger),
+    )
+    if err := leaderboard.Rebuild(context.Background(), time.Now(), *weeks); err != nil {
+        return err
+    }
+    log.Printf("Rebuilt the all-time leaderboards and %d weekly one(s)", *weeks)
+    return nil
+}
@@ -1,151 +1,55 @@
 package main
 
 import (
+    "bytes"
     "context"
-    "database/sql"
-    "flag"
-    "fmt"
+    "html/template"
     "log"
     "net/http"
+    "os"
+    "strings"
     "time"
-
-    "github.com/gofiber/fiber/v3"
-    "github.com/gofiber/fiber/v3/middleware/cors"
-    "github.com/gofiber/fiber/v3/middleware/recover"
+    _ "time/tzdata" // goal timezones must resolve on hosts without a zoneinfo database
+
+    "github.com/aygoko/EcoMInd/backend/api/middleware"
+    "github.com/aygoko/EcoMInd/backend/api/problem"
+    achievementhttp "github.com/aygoko/EcoMInd/backend/api/types/achievement"
+    activityhttp "github.com/aygoko/EcoMInd/backend/api/types/activity"
+    coachhttp "github.com/aygoko/EcoMInd/backend/api/types/coach"
+    goalhttp "github.com/aygoko/EcoMInd/backend/api/types/goal"
+    leaderboardhttp "github.com/aygoko/EcoMInd/backend/api/types/leaderboard"
+    recommendationhttp "github.com/aygoko/EcoMInd/backend/api/types/recommendation"
+    socialhttp "github.com/aygoko/EcoMInd/backend/api/types/social"
+    statshttp "github.com/aygoko/EcoMInd/backend/api/types/stats"
+    surveyhttp "github.com/aygoko/EcoMInd/backend/api/types/survey"
+    taskhttp "github.com/aygoko/EcoMInd/backend/api/types/task"
+    teamhttp "github.com/aygoko/EcoMInd/backend/api/types/team"
+    userhttp "github.com/aygoko/EcoMInd/backend/api/types/user"
+    "github.com/aygoko/EcoMInd/backend/config"
+    "github.com/aygoko/EcoMInd/backend/domain"
+    "github.com/aygoko/EcoMInd/backend/repository"
+    "github.com/aygoko/EcoMInd/backend/repository/postgres"
+    "github.com/aygoko/EcoMInd/backend/repository/ram_storage"
+    "github.com/aygoko/EcoMInd/backend/repository/redis_storage"
+    "github.com/aygoko/EcoMInd/backend/usecases/coach"
+    "github.com/aygoko/EcoMInd/backend/usecases/mail"
+    "github.com/aygoko/EcoMInd/backend/usecases/oauth"
+    "github.com/aygoko/EcoMInd/backend/usecases/service"
+    "github.com/aygoko/EcoMInd/backend/usecases/validate"
+    "github.com/gofiber/fiber/v2"
+    "github.com/gofiber/fiber/v2/middleware/cors"
+    "github.com/gofiber/fiber/v2/middleware/recover"
     "github.com/go-redis/redis/v8"
     _ "github.com/lib/pq" // PostgreSQL driver
 )
 
-// domain.User struct (replace with your actual model)
-type User struct {
-    ID          string  `json:"id"`
-    Login       string  `json:"login"`
-    Email       string  `json:"email"`
-    PhoneNumber string  `json:"phone_number"`
-    Password    string  `json:"-"`
-    CO2         float64 `json:"co2"`
-}
-
-// UserService interface
-type UserService interface {
-    Get(login string) (*User, error)
-}
-
-// UserRepository struct
-type UserRepository struct {
-    DB          *sql.DB
-    RedisClient *redis.Client
-    Logger      *log.Logger
-}
-
-// NewUserRepository initializes the repository
-func NewUserRepository(db *sql.DB, redisClient *redis.Client, logger *log.Logger) *UserRepository {
-    return &UserRepository{
-        DB:          db,
-        RedisClient: redisClient,
-        Logger:      logger,
-    }
-}
-
-// Get retrieves a user with Redis cache
-func (r *UserRepository) Get(login string) (*User, error) {
-    key := "user:login:" + login
-    userJSON, err := r.RedisClient.Get(context.Background(), key).Result()
-    if err == nil {
-        var user User
-        if err := json.Unmarshal([]byte(userJSON), &user); err != nil {
-            r.Logger.Printf("Failed to unmarshal cached user: %v", err)
-            return nil, err
-        }
-        return &user, nil
-    } else if err != redis.Nil {
-        return nil, err
-    }
-
-    // Fetch from PostgreSQL
-    row := r.DB.QueryRow("SELECT id, login, email, phone_number, co2 FROM users WHERE login = $1", login)
-    var user User
-    err = row.Scan(&user.ID, &user.Login, &user.Email, &user.PhoneNumber, &user.CO2)
-    if err != nil {
-        if err == sql.ErrNoRows {
-            return nil, fmt.Errorf("user not found")
-        }
-        return nil, err
-    }
-
-    // Cache the result
-    if err := r.cacheUser(&user); err != nil {
-        r.Logger.Printf("Failed to cache user: %v", err)
-    }
-
-    return &user, nil
-}
-
-// cacheUser stores the user in Redis
-func (r *UserRepository) cacheUser(user *User) error {
-    key := "user:login:" + user.Login
-    userJSON, err := json.Marshal(user)
-    if err != nil {
-        return err
-    }
-    return r.RedisClient.Set(context.Background(), key, userJSON, 5*time.Minute).Err()
-}
-
-// UserService implementation
-type UserServiceImpl struct {
-    Repo *UserRepository
-}
-
-// NewUserServiceImpl creates a new user service
-func NewUserServiceImpl(repo *UserRepository) UserService {
-    return &UserServiceImpl{Repo: repo}
-}
-
-func (s *UserServiceImpl) Get(login string) (*User, error) {
-    return s.Repo.Get(login)
-}
-
-// UserHandler struct
-type UserHandler struct {
-    Service UserService
-}
-
-// NewUserHandler creates a new user handler
-func NewUserHandler(service UserService) *UserHandler {
-    return &UserHandler{Service: service}
-}
-
-// RegisterRoutes registers user-related routes
-func (h *UserHandler) RegisterRoutes(app *fiber.App) {
-    api := app.Group("/api/users")
-    api.Get("/:login", h.GetUser)
-}
-
-// GetUser retrieves a user by login
-func (h *UserHandler) GetUser(c *fiber.Ctx) error {
-    login := c.Params("login")
-    user, err := h.Service.Get(login)
-    if err != nil {
-        if err.Error() == "user not found" {
-            return c.Status(404).JSON(fiber.Map{"error": "User not found"})
-        }
-        return c.Status(500).JSON(fiber.Map{"error": "Internal server error"})
-    }
-    return c.JSON(user)
-}
-
 // Form handlers
 type PageData struct {
     AgreementChecked bool
     Message          string
 }
 
-func showForm(c *fiber.Ctx) error {
-    data := PageData{
-        AgreementChecked: false,
-        Message:          c.Query("message", ""),
-    }
-    return c.RenderString(`
+var formTemplate = template.Must(template.New("form").Parse(`
         <!DOCTYPE html>
         <html>
         <head>
@@ -165,7 +69,19 @@ func showForm(c *fiber.Ctx) error {
                 <p>{{.Message}}</p>
             {{end}}
         </body>
-        </html>`, data, "form")
+        </html>`))
+
+func showForm(c *fiber.Ctx) error {
+    data := PageData{
+        AgreementChecked: false,
+        Message:          c.Query("message", ""),
+    }
+    var buf bytes.Buffer
+    if err := formTemplate.Execute(&buf, data); err != nil {
+        return err
+    }
+    c.Type("html")
+    return c.Send(buf.Bytes())
 }
 
 func handleFormSubmission(c *fiber.Ctx) error {
@@ -185,41 +101,260 @@ func handleFormSubmission(c *fiber.Ctx) error {
     return c.Redirect("/?message=Вы%20не%20согласились%20с%20соглашением", http.StatusSeeOther)
 }
 
-func main() {
-    addr := flag.String("addr", ":8080", "HTTP server address")
-    flag.Parse()
-
-    // Initialize PostgreSQL
-    pgDB, err := sql.Open("postgres", "user=youruser password=yourpass dbname=yourdb sslmode=disable")
-    if err != nil {
-        log.Fatalf("Failed to connect to PostgreSQL: %v", err)
-    }
-    defer pgDB.Close()
+// stores groups the repositories selected by cfg.Storage
+type stores struct {
+    users         domain.UserService
+    loginAttempts domain.LoginAttemptService
+    sessions      domain.SessionService
+    identities    domain.IdentityService
+    oauthStates   domain.OAuthStateService
+    oneTimeTokens domain.OneTimeTokenService
+    activities    domain.ActivityService
+    factors       domain.EmissionFactorService
+    surveys       domain.SurveyResponseService
+    tasks         domain.TaskService
+    goals         domain.GoalService
+    leaderboards  domain.LeaderboardService
+    dismissals    domain.RecommendationService
+    coach         domain.CoachService
+    rateLimits    domain.RateLimitService
+    stats         domain.StatsService
+    statsCache    domain.StatsCacheService
+    achievements  domain.AchievementService
+    social        domain.SocialService
+    teams         domain.TeamService
+    close         func()
+}
 
-    // Initialize Redis
+// openRedis connects to and pings the configured Redis server
+func openRedis(cfg *config.Config) *redis.Client {
     redisClient := redis.NewClient(&redis.Options{
-        Addr:     "localhost:6379",
-        Password: "",
-        DB:       0,
+        Addr:     cfg.Redis.Addr,
+        Password: cfg.Redis.Password,
+        DB:       cfg.Redis.DB,
     })
     if _, err := redisClient.Ping(context.Background()).Result(); err != nil {
         log.Fatalf("Failed to connect to Redis: %v", err)
     }
+    return redisClient
+}
 
-    // Initialize repository and service
-    repo := NewUserRepository(pgDB, redisClient, log.Default())
-    userService := NewUserServiceImpl(repo)
-    userHandler := NewUserHandler(userService)
+// openStores builds the repositories selected by cfg.Storage.
+// Its close func releases any connections it opened.
+func openStores(cfg *config.Config) *stores {
+    if cfg.Storage == "memory" {
+        log.Printf("Using in-memory storage; data is lost on restart")
+        users := ram_storage.NewUserStorage()
+        activities := ram_storage.NewActivityStorage(users)
+        return &stores{
+            users:         users,
+            loginAttempts: ram_storage.NewLoginAttemptStorage(),
+            sessions:      ram_storage.NewSessionStorage(),
+            identities:    ram_storage.NewIdentityStorage(),
+            oauthStates:   ram_storage.NewOAuthStateStorage(),
+            oneTimeTokens: ram_storage.NewOneTimeTokenStorage(),
+            activities:    activities,
+            factors:       ram_storage.NewEmissionFactorStorage(),
+            surveys:       ram_storage.NewSurveyResponseStorage(users),
+            tasks:         ram_storage.NewTaskStorage(users),
+            goals:         ram_storage.NewGoalStorage(),
+            leaderboards:  ram_storage.NewLeaderboardStorage(),
+            dismissals:    ram_storage.NewRecommendationStorage(),
+            coach:         ram_storage.NewCoachStorage(),
+            rateLimits:    ram_storage.NewRateLimitStorage(),
+            stats:         ram_storage.NewStatsStorage(activities),
+            statsCache:    ram_storage.NewStatsCacheStorage(cfg.Stats.CacheTTL),
+            achievements:  ram_storage.NewAchievementStorage(),
+            social:        ram_storage.NewSocialStorage(),
+            teams:         ram_storage.NewTeamStorage(),
+            close:         func() {},
+        }
+    }
+
+    // Initialize PostgreSQL
+    pgDB := openPostgres(cfg)
+    if cfg.Postgres.MigrateOnStart {
+        if err := migrateUp(context.Background(), pgDB); err != nil {
+            log.Fatalf("Failed to migrate database: %v", err)
+        }
+    }
+
+    // Initialize Redis
+    redisClient := openRedis(cfg)
+
+    return &stores{
+        users:         postgres.NewUserRepository(pgDB, redisClient, cfg.Redis.CacheTTL, repository.DefaultLogger),
+        loginAttempts: redis_storage.NewLoginAttemptRepository(redisClient, repository.DefaultLogger),
+        sessions:      redis_storage.NewSessionRepository(redisClient, repository.DefaultLogger),
+        identities:    postgres.NewIdentityRepository(pgDB, repository.DefaultLogger),
+        oauthStates:   redis_storage.NewOAuthStateRepository(redisClient, repository.DefaultLogger),
+        oneTimeTokens: redis_storage.NewOneTimeTokenRepository(redisClient, repository.DefaultLogger),
+        activities:    postgres.NewActivityRepository(pgDB, redisClient, repository.DefaultLogger),
+        factors:       postgres.NewEmissionFactorRepository(pgDB, repository.DefaultLogger),
+        surveys:       postgres.NewSurveyResponseRepository(pgDB, redisClient, repository.DefaultLogger),
+        tasks:         postgres.NewTaskRepository(pgDB, redisClient, repository.DefaultLogger),
+        goals:         postgres.NewGoalRepository(pgDB, repository.DefaultLogger),
+        leaderboards:  redis_storage.NewLeaderboardRepository(redisClient, repository.DefaultLogger),
+        dismissals:    postgres.NewRecommendationRepository(pgDB, repository.DefaultLogger),
+        coach:         postgres.NewCoachRepository(pgDB, repository.DefaultLogger),
+        rateLimits:    redis_storage.NewRateLimitRepository(redisClient, repository.DefaultLogger),
+        stats:         postgres.NewStatsRepository(pgDB, repository.DefaultLogger),
+        statsCache:    redis_storage.NewStatsCacheRepository(redisClient, cfg.Stats.CacheTTL, repository.DefaultLogger),
+        achievements:  postgres.NewAchievementRepository(pgDB, repository.DefaultLogger),
+        social:        postgres.NewSocialRepository(pgDB, repository.DefaultLogger),
+        teams:         postgres.NewTeamRepository(pgDB, repository.DefaultLogger),
+        close: func() {
+            redisClient.Close()
+            pgDB.Close()
+        },
+    }
+}
+
+// runGoalRollover closes ended weeks and proposes next week's goals, once at
+// startup and then every interval
+func runGoalRollover(goals *service.GoalService, interval time.Duration) {
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        if closed, err := goals.Rollover(context.Background(), time.Now()); err != nil {
+            log.Printf("Weekly goal rollover failed: %v", err)
+        } else if closed > 0 {
+            log.Printf("Closed %d weekly goal(s)", closed)
+        }
+        <-ticker.C
+    }
+}
+
+func main() {
+    args := os.Args[1:]
+    command := "serve"
+    if len(args) > 0 && (args[0] == "migrate" || args[0] == "factors" || args[0] == "leaderboard" || args[0] == "achievements" || args[0] == "users") {
+        command, args = args[0], args[1:]
+    }
+
+    cfg, rest, err := config.Load(args)
+    if err != nil {
+        log.Fatalf("Failed to load configuration: %v", err)
+    }
+    if !validate.PhoneRegionSupported(cfg.Users.PhoneRegion) {
+        log.Fatalf("Failed to load configuration: users.phone_region must be one of %s, got %q", strings.Join(validate.PhoneRegions(), ", "), cfg.Users.PhoneRegion)
+    }
+
+    switch command {
+    case "migrate":
+        if err := runMigrate(cfg, rest); err != nil {
+            log.Fatalf("Migration failed: %v", err)
+        }
+        return
+    case "factors":
+        if err := runFactors(cfg, rest); err != nil {
+            log.Fatalf("Emission factor command failed: %v", err)
+        }
+        return
+    case "leaderboard":
+        if err := runLeaderboard(cfg, rest); err != nil {
+            log.Fatalf("Leaderboard command failed: %v", err)
+        }
+        return
+    case "achievements":
+        if err := runAchievements(cfg, rest); err != nil {
+            log.Fatalf("Achievements command failed: %v", err)
+        }
+        return
+    case "users":
+        if err := runUsers(cfg, rest); err != nil {
+            log.Fatalf("Users command failed: %v", err)
+        }
+        return
+    }
+    if len(rest) > 0 {
+        log.Fatalf("Unexpected arguments %v; did you mean the migrate, factors, leaderboard, achievements or users subcommand?", rest)
+    }
+
+    // Initialize repositories and services
+    st := openStores(cfg)
+    defer st.close()
+    userService := service.NewUserService(st.users, cfg.Users.PhoneRegion)
+    authService := service.NewAuthService(st.users, st.loginAttempts, cfg.Login.MaxAttempts, cfg.Login.LockoutWindow, cfg.Users.PhoneRegion)
+    tokenService, err := service.NewTokenService(cfg.JWT)
+    if err != nil {
+        log.Fatalf("Failed to initialize token signing: %v", err)
+    }
+    sessionService := service.NewSessionService(st.sessions, cfg.JWT.RefreshTTL)
+    oauthService := service.NewOAuthService(st.users, st.identities, st.oauthStates, cfg.OAuth.StateSecret, cfg.OAuth.StateTTL)
+    providers, err := oauth.NewRegistry(cfg.OAuth.Providers)
+    if err != nil {
+        log.Fatalf("Failed to configure OAuth providers: %v", err)
+    }
+    factorService := service.NewEmissionFactorService(st.factors)
+    if seeded, err := factorService.SeedDefaults(context.Background()); err != nil {
+        log.Fatalf("Failed to seed emission factors: %v", err)
+    } else if seeded {
+        log.Printf("Published the built-in emission factors as the first catalogue version")
+    }
+    statsService := service.NewStatsService(st.stats, st.statsCache)
+    activityService := service.NewActivityService(st.activities, factorService, statsService)
+    surveyService := service.NewSurveyService(st.surveys, service.OnboardingSurvey)
+    socialService := service.NewSocialService(st.social, st.users, st.activities)
+    leaderboardService := service.NewLeaderboardService(st.leaderboards, st.users, st.tasks)
+    leaderboardService.Friends = socialService
+    leaderboardService.Privacy = socialService
+    taskService := service.NewTaskService(st.tasks, leaderboardService)
+    if seeded, err := taskService.SeedDefaults(context.Background()); err != nil {
+        log.Fatalf("Failed to seed the task catalogue: %v", err)
+    } else if seeded {
+        log.Printf("Added the built-in tasks to the empty task catalogue")
+    }
+    goalService := service.NewGoalService(st.goals, st.users, st.activities, st.tasks)
+    achievementService := service.NewAchievementService(st.achievements, st.users, st.tasks, st.goals, st.stats, service.DefaultBadges...)
+    events := service.NewEventBus()
+    events.Subscribe(achievementService)
+    activityService.Events = events
+    taskService.Events = events
+    goalService.Events = events
+    go runGoalRollover(goalService, cfg.Goals.RolloverInterval)
+    teamService := service.NewTeamService(st.teams, st.users, st.tasks, st.social)
+    teamService.Privacy = socialService
+    recommendationService := service.NewRecommendationService(st.dismissals, st.surveys, st.activities)
+    coachProvider, err := coach.New(cfg.Coach)
+    if err != nil {
+        log.Fatalf("Failed to configure the coach: %v", err)
+    }
+    coachService := service.NewCoachService(st.coach, coachProvider, st.rateLimits, st.activities, goalService, recommendationService, cfg.Coach.RateLimit, cfg.Coach.RateWindow)
+    mailer, err := mail.New(cfg.Mail)
+    if err != nil {
+        log.Fatalf("Failed to configure mail: %v", err)
+    }
+    accountService := service.NewAccountService(st.users, tokenService, st.oneTimeTokens, sessionService, authService, st.rateLimits, mailer, cfg.Users)
+    requireAuth := middleware.RequireAuth(tokenService, sessionService, userService)
+    optionalAuth := middleware.OptionalAuth(tokenService, sessionService, userService)
+    requireAdmin := middleware.RequireAdmin(cfg.Admin.Logins)
+    userHandler := userhttp.NewUserHandler(userService, authService, tokenService, sessionService, oauthService, socialService, accountService, providers)
+    activityHandler := activityhttp.NewActivityHandler(activityService, factorService)
+    surveyHandler := surveyhttp.NewSurveyHandler(surveyService)
+    taskHandler := taskhttp.NewTaskHandler(taskService)
+    goalHandler := goalhttp.NewGoalHandler(goalService)
+    leaderboardHandler := leaderboardhttp.NewLeaderboardHandler(leaderboardService)
+    recommendationHandler := recommendationhttp.NewRecommendationHandler(recommendationService)
+    coachHandler := coachhttp.NewCoachHandler(coachService)
+    statsHandler := statshttp.NewStatsHandler(statsService)
+    achievementHandler := achievementhttp.NewAchievementHandler(achievementService)
+    socialHandler := socialhttp.NewSocialHandler(socialService)
+    teamHandler := teamhttp.NewTeamHandler(teamService)
 
     // Initialize Fiber app
     app := fiber.New(fiber.Config{
-        ReadTimeout:  10 * time.Second,
-        WriteTimeout: 10 * time.Second,
+        ReadTimeout:  cfg.ReadTimeout,
+        WriteTimeout: cfg.WriteTimeout,
+        // Params and query values outlive the request in the in-memory stores
+        Immutable: true,
+        // Handlers return errors; they are written as RFC 7807 problem details
+        ErrorHandler: problem.Handler,
     })
 
     // CORS configuration
     app.Use(cors.New(cors.Config{
-        AllowOrigins:     []string{"http://localhost:3000"},
+        AllowOrigins:     strings.Join(cfg.CORS.AllowOrigins, ","),
         AllowMethods:     "GET,POST,PUT,PATCH,DELETE",
         AllowHeaders:     "Content-Type,Authorization",
         AllowCredentials: true,
@@ -227,18 +362,35 @@ func main() {
 
     // Middlewares
     app.Use(recover.New())
+    app.Use(middleware.Language(validate.Languages))
     app.Use(func(c *fiber.Ctx) error {
         log.Printf("%s %s %s", c.IP(), c.Method(), c.Path())
         return c.Next()
     })
 
     // Register routes
-    userHandler.RegisterRoutes(app)
+    userHandler.RegisterRoutes(app, requireAuth, optionalAuth)
+    activityHandler.RegisterRoutes(app, requireAuth, requireAdmin)
+    surveyHandler.RegisterRoutes(app, requireAuth)
+    taskHandler.RegisterRoutes(app, requireAuth, requireAdmin)
+    goalHandler.RegisterRoutes(app, requireAuth)
+    leaderboardHandler.RegisterRoutes(app, requireAuth)
+    recommendationHandler.RegisterRoutes(app, requireAuth)
+    coachHandler.RegisterRoutes(app, requireAuth)
+    statsHandler.RegisterRoutes(app, requireAuth)
+    achievementHandler.RegisterRoutes(app, requireAuth)
+    socialHandler.RegisterRoutes(app, requireAuth)
+    teamHandler.RegisterRoutes(app, requireAuth)
     app.Get("/", showForm)
     app.Post("/submit", handleFormSubmission)
+    if cfg.ExposeConfig {
+        app.Get("/debug/config", requireAuth, requireAdmin, func(c *fiber.Ctx) error {
+            return c.JSON(cfg.Redacted())
+        })
+    }
 
-    log.Printf("Server listening on %s", *addr)
-    if err := app.Listen(*addr); err != nil {
+    log.Printf("Server listening on %s", cfg.Addr)
+    if err := app.Listen(cfg.Addr); err != nil {
         log.Fatalf("Server failed: %v", err)
     }
-}
\ No newline at end of file
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "log"
+    "strconv"
+
+    "github.com/aygoko/EcoMInd/backend/config"
+    "github.com/aygoko/EcoMInd/backend/repository"
+    "github.com/aygoko/EcoMInd/backend/repository/postgres"
+)
+
+const migrateUsage = "usage: ecomind migrate [flags] up | down [steps] | status"
+
+// openPostgres opens and pings the configured Postgres database
+func openPostgres(cfg *config.Config) *sql.DB {
+    pgDB, err := sql.Open("postgres", cfg.Postgres.DSN)
+    if err != nil {
+        log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+    }
+    if err := pgDB.PingContext(context.Background()); err != nil {
+        log.Fatalf("Failed to connect to PostgreSQL: %v", err)
+    }
+    return pgDB
+}
+
+// migrateUp applies pending migrations, used both by the subcommand and at startup
+func migrateUp(ctx context.Context, pgDB *sql.DB) error {
+    migrator, err := postgres.NewMigrator(pgDB, repository.DefaultLogger)
+    if err != nil {
+        return err
+    }
+    count, err := migrator.Up(ctx)
+    if err != nil {
+        return err
+    }
+    log.Printf("Applied %d migration(s)", count)
+    return nil
+}
+
+// runMigrate implements the migrate subcommand
+func runMigrate(cfg *config.Config, args []string) error {
+    if cfg.Storage != "postgres" {
+        return fmt.Errorf("migrate needs storage postgres, configured storage is %q", cfg.Storage)
+    }
+    if len(args) == 0 {
+        return fmt.Errorf(migrateUsage)
+    }
+
+    ctx := context.Background()
+    pgDB := openPostgres(cfg)
+    defer pgDB.Close()
+
+    migrator, err := postgres.NewMigrator(pgDB, repository.DefaultLogger)
+    if err != nil {
+        return err
+    }
+
+    switch args[0] {
+    case "up":
+        return migrateUp(ctx, pgDB)
+    case "down":
+        steps := 1
+        if len(args) > 1 {
+            if steps, err = strconv.Atoi(args[1]); err != nil {
+                return fmt.Errorf("invalid steps %q: %w", args[1], err)
+            }
+        }
+        count, err := migrator.Down(ctx, steps)
+        if err != nil {
+            return err
+        }
+        log.Printf("Reverted %d migration(s)", count)
+        return nil
+    case "status":
+        statuses, err := migrator.Status(ctx)
+        if err != nil {
+            return err
+        }
+        for _, s := range statuses {
+            state := "pending"
+            if s.AppliedAt != nil {
+                state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+            }
+            fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, state)
+        }
+        return nil
+    default:
+        return fmt.Errorf(migrateUsage)
+    }
+}
@@ -1,7 +0,0 @@
-package repository
-
-import "errors"
-
-var (
-	NotFound = errors.New("key not found")
-)
@@ -0,0 +1,23 @@
+package repository
+
+import "log"
+
+// Logger interface for structured logging
+type Logger interface {
+	Errorf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+}
+
+// DefaultLogger writes through the standard library logger
+// (replace with a structured logging library like zap in production)
+var DefaultLogger Logger = &defaultLogger{}
+
+type defaultLogger struct{}
+
+func (l *defaultLogger) Errorf(format string, args ...interface{}) {
+	log.Printf("[ERROR] "+format, args...)
+}
+
+func (l *defaultLogger) Infof(format string, args ...interface{}) {
+	log.Printf("[INFO] "+format, args...)
+}
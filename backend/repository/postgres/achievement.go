@@ -0,0 +1,70 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+)
+
+// AchievementRepositoryDB implements domain.AchievementService on the
+// user_achievements table
+type AchievementRepositoryDB struct {
+	DB     *sql.DB
+	Logger repository.Logger
+}
+
+// NewAchievementRepository creates a new achievement repository instance
+func NewAchievementRepository(db *sql.DB, logger repository.Logger) domain.AchievementService {
+	return &AchievementRepositoryDB{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// Award records a badge unless the user already has it
+func (r *AchievementRepositoryDB) Award(ctx context.Context, userID, badgeID string, at time.Time) (bool, error) {
+	result, err := r.DB.ExecContext(
+		ctx,
+		`INSERT INTO user_achievements (user_id, badge_id, awarded_at) VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id, badge_id) DO NOTHING`,
+		userID,
+		badgeID,
+		at,
+	)
+	if err != nil {
+		r.Logger.Errorf("failed to award badge %s to user %s: %v", badgeID, userID, err)
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n > 0 {
+		r.Logger.Infof("awarded badge %s to user %s", badgeID, userID)
+	}
+	return n > 0, nil
+}
+
+// Awarded returns when the user earned each of their badges
+func (r *AchievementRepositoryDB) Awarded(ctx context.Context, userID string) (map[string]time.Time, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT badge_id, awarded_at FROM user_achievements WHERE user_id = $1", userID)
+	if err != nil {
+		r.Logger.Errorf("database error while listing achievements: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	awarded := make(map[string]time.Time)
+	for rows.Next() {
+		var badgeID string
+		var at time.Time
+		if err := rows.Scan(&badgeID, &at); err != nil {
+			return nil, err
+		}
+		awarded[badgeID] = at
+	}
+	return awarded, rows.Err()
+}
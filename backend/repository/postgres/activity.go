@@ -0,0 +1,214 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+	"github.com/go-redis/redis/v8"
+)
+
+// activityColumns is the column list read by every lookup; keep in sync with scanActivity
+const activityColumns = "id, user_id, category, quantity, unit, region, factor_id, factor_version, factor, factor_source, co2e, occurred_at, created_at"
+
+// rowScanner is satisfied by *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanActivity(row rowScanner, activity *domain.Activity) error {
+	// Activities logged before the factor catalogue have no factor reference
+	var factorID sql.NullString
+	var factorVersion sql.NullInt64
+	if err := row.Scan(
+		&activity.ID,
+		&activity.UserID,
+		&activity.Category,
+		&activity.Quantity,
+		&activity.Unit,
+		&activity.Region,
+		&factorID,
+		&factorVersion,
+		&activity.Factor,
+		&activity.FactorSource,
+		&activity.CO2e,
+		&activity.OccurredAt,
+		&activity.CreatedAt,
+	); err != nil {
+		return err
+	}
+	activity.FactorID = factorID.String
+	activity.FactorVersion = int(factorVersion.Int64)
+	return nil
+}
+
+// ActivityRepositoryDB implements domain.ActivityService on the activities
+// table. It keeps users.co2 equal to the sum of the user's activities.
+type ActivityRepositoryDB struct {
+	DB          *sql.DB
+	RedisClient *redis.Client // to drop cached users whose total changed
+	Logger      repository.Logger
+}
+
+// NewActivityRepository creates a new activity repository instance
+func NewActivityRepository(db *sql.DB, redisClient *redis.Client, logger repository.Logger) domain.ActivityService {
+	return &ActivityRepositoryDB{
+		DB:          db,
+		RedisClient: redisClient,
+		Logger:      logger,
+	}
+}
+
+// updateTotal runs change in a transaction that also recomputes the user's
+// CO2 total, then drops the user's cache entries
+func (r *ActivityRepositoryDB) updateTotal(ctx context.Context, userID string, change func(tx *sql.Tx) error) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// Locking the user row serializes concurrent changes to one user's total
+	var id string
+	err = tx.QueryRowContext(ctx, "SELECT id FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", userID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrUserNotFound
+	} else if err != nil {
+		r.Logger.Errorf("database error while locking user %s: %v", userID, err)
+		return err
+	}
+
+	if err := change(tx); err != nil {
+		return err
+	}
+
+	var login string
+	var email, phoneNumber sql.NullString
+	err = tx.QueryRowContext(
+		ctx,
+		`UPDATE users SET co2 = (SELECT COALESCE(SUM(co2e), 0) FROM activities WHERE user_id = $1)
+		 WHERE id = $1
+		 RETURNING login, email, phone_number`,
+		userID,
+	).Scan(&login, &email, &phoneNumber)
+	if err != nil {
+		r.Logger.Errorf("failed to recompute CO2 total of user %s: %v", userID, err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.Logger.Errorf("failed to commit activity change: %v", err)
+		return err
+	}
+
+	if err := r.RedisClient.Del(ctx, cacheKeys(userID, login, email.String, phoneNumber.String)...).Err(); err != nil {
+		r.Logger.Errorf("failed to invalidate cache after CO2 update: %v", err)
+	}
+	return nil
+}
+
+// Create stores an activity and adds it to the user's CO2 total
+func (r *ActivityRepositoryDB) Create(ctx context.Context, activity *domain.Activity) error {
+	err := r.updateTotal(ctx, activity.UserID, func(tx *sql.Tx) error {
+		return tx.QueryRowContext(
+			ctx,
+			`INSERT INTO activities (id, user_id, category, quantity, unit, region, factor_id, factor_version, factor, factor_source, co2e, occurred_at)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			 RETURNING created_at`,
+			activity.ID,
+			activity.UserID,
+			activity.Category,
+			activity.Quantity,
+			activity.Unit,
+			activity.Region,
+			nullable(activity.FactorID),
+			sql.NullInt64{Int64: int64(activity.FactorVersion), Valid: activity.FactorVersion != 0},
+			activity.Factor,
+			activity.FactorSource,
+			activity.CO2e,
+			activity.OccurredAt,
+		).Scan(&activity.CreatedAt)
+	})
+	if err != nil {
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			r.Logger.Errorf("failed to create activity: %v", err)
+		}
+		return err
+	}
+	r.Logger.Infof("logged %s activity for user %s", activity.Category, activity.UserID)
+	return nil
+}
+
+// Get retrieves one of the user's activities
+func (r *ActivityRepositoryDB) Get(ctx context.Context, userID, id string) (*domain.Activity, error) {
+	var activity domain.Activity
+	row := r.DB.QueryRowContext(ctx, "SELECT "+activityColumns+" FROM activities WHERE id = $1 AND user_id = $2", id, userID)
+	if err := scanActivity(row, &activity); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrActivityNotFound
+		}
+		r.Logger.Errorf("database error while fetching activity: %v", err)
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// List returns the user's activities, most recent first
+func (r *ActivityRepositoryDB) List(ctx context.Context, userID string, filter domain.ActivityFilter) ([]*domain.Activity, error) {
+	query := "SELECT " + activityColumns + " FROM activities WHERE user_id = $1"
+	args := []interface{}{userID}
+	if !filter.From.IsZero() {
+		args = append(args, filter.From)
+		query += " AND occurred_at >= $" + strconv.Itoa(len(args))
+	}
+	if !filter.To.IsZero() {
+		args = append(args, filter.To)
+		query += " AND occurred_at < $" + strconv.Itoa(len(args))
+	}
+	query += " ORDER BY occurred_at DESC, created_at DESC"
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += " LIMIT $" + strconv.Itoa(len(args))
+	}
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.Logger.Errorf("database error while listing activities: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	activities := []*domain.Activity{}
+	for rows.Next() {
+		var activity domain.Activity
+		if err := scanActivity(rows, &activity); err != nil {
+			return nil, err
+		}
+		activities = append(activities, &activity)
+	}
+	return activities, rows.Err()
+}
+
+// Delete removes one of the user's activities and subtracts it from their total
+func (r *ActivityRepositoryDB) Delete(ctx context.Context, userID, id string) error {
+	err := r.updateTotal(ctx, userID, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, "DELETE FROM activities WHERE id = $1 AND user_id = $2", id, userID)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return domain.ErrActivityNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		if !errors.Is(err, domain.ErrActivityNotFound) {
+			r.Logger.Errorf("failed to delete activity: %v", err)
+		}
+		return err
+	}
+	r.Logger.Infof("deleted activity %s of user %s", id, userID)
+	return nil
+}
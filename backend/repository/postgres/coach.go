@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+)
+
+// CoachRepositoryDB implements domain.CoachService on the coach_exchanges table
+type CoachRepositoryDB struct {
+	DB     *sql.DB
+	Logger repository.Logger
+}
+
+// NewCoachRepository creates a new coaching log repository instance
+func NewCoachRepository(db *sql.DB, logger repository.Logger) domain.CoachService {
+	return &CoachRepositoryDB{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// Create logs an exchange with the coach
+func (r *CoachRepositoryDB) Create(ctx context.Context, exchange *domain.CoachExchange) error {
+	err := r.DB.QueryRowContext(
+		ctx,
+		`INSERT INTO coach_exchanges (id, user_id, provider, prompt, message, reply, error, duration_ms)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 RETURNING created_at`,
+		exchange.ID,
+		exchange.UserID,
+		exchange.Provider,
+		exchange.Prompt,
+		exchange.Message,
+		exchange.Reply,
+		exchange.Error,
+		exchange.Duration.Milliseconds(),
+	).Scan(&exchange.CreatedAt)
+	if err != nil {
+		r.Logger.Errorf("failed to log coach exchange: %v", err)
+		return err
+	}
+	return nil
+}
+
+// List returns the user's exchanges, most recent first
+func (r *CoachRepositoryDB) List(ctx context.Context, userID string, limit int) ([]*domain.CoachExchange, error) {
+	rows, err := r.DB.QueryContext(
+		ctx,
+		`SELECT id, user_id, provider, prompt, message, reply, error, duration_ms, created_at
+		 FROM coach_exchanges WHERE user_id = $1
+		 ORDER BY created_at DESC LIMIT $2`,
+		userID,
+		limit,
+	)
+	if err != nil {
+		r.Logger.Errorf("database error while listing coach exchanges: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	exchanges := []*domain.CoachExchange{}
+	for rows.Next() {
+		var exchange domain.CoachExchange
+		var durationMS int64
+		if err := rows.Scan(
+			&exchange.ID,
+			&exchange.UserID,
+			&exchange.Provider,
+			&exchange.Prompt,
+			&exchange.Message,
+			&exchange.Reply,
+			&exchange.Error,
+			&durationMS,
+			&exchange.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		exchange.Duration = time.Duration(durationMS) * time.Millisecond
+		exchanges = append(exchanges, &exchange)
+	}
+	return exchanges, rows.Err()
+}
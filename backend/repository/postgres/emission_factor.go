@@ -0,0 +1,229 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+)
+
+const (
+	// factorColumns is the column list read by every factor lookup; keep in sync with scanFactor
+	factorColumns = "id, version, category, unit, factor, source, region, valid_from, valid_to"
+
+	// versionColumns is the column list read by every version lookup; keep in sync with scanVersion
+	versionColumns = `v.version, v.note, v.created_at, v.published_at,
+		(SELECT count(*) FROM emission_factors f WHERE f.version = v.version)`
+)
+
+func scanFactor(row rowScanner, factor *domain.EmissionFactor) error {
+	var validFrom, validTo sql.NullTime
+	if err := row.Scan(
+		&factor.ID,
+		&factor.Version,
+		&factor.Category,
+		&factor.Unit,
+		&factor.Factor,
+		&factor.Source,
+		&factor.Region,
+		&validFrom,
+		&validTo,
+	); err != nil {
+		return err
+	}
+	if validFrom.Valid {
+		factor.ValidFrom = &validFrom.Time
+	}
+	if validTo.Valid {
+		factor.ValidTo = &validTo.Time
+	}
+	return nil
+}
+
+func scanVersion(row rowScanner, version *domain.EmissionFactorVersion) error {
+	var publishedAt sql.NullTime
+	if err := row.Scan(&version.Version, &version.Note, &version.CreatedAt, &publishedAt, &version.FactorCount); err != nil {
+		return err
+	}
+	if publishedAt.Valid {
+		version.PublishedAt = &publishedAt.Time
+	}
+	return nil
+}
+
+// EmissionFactorRepositoryDB implements domain.EmissionFactorService on the
+// emission_factor_versions and emission_factors tables
+type EmissionFactorRepositoryDB struct {
+	DB     *sql.DB
+	Logger repository.Logger
+}
+
+// NewEmissionFactorRepository creates a new emission factor repository instance
+func NewEmissionFactorRepository(db *sql.DB, logger repository.Logger) domain.EmissionFactorService {
+	return &EmissionFactorRepositoryDB{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// CreateVersion stores a draft version with its factors in one transaction
+func (r *EmissionFactorRepositoryDB) CreateVersion(ctx context.Context, version *domain.EmissionFactorVersion, factors []*domain.EmissionFactor) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(
+		ctx,
+		"INSERT INTO emission_factor_versions (note) VALUES ($1) RETURNING version, created_at",
+		version.Note,
+	).Scan(&version.Version, &version.CreatedAt)
+	if err != nil {
+		r.Logger.Errorf("failed to create emission factor version: %v", err)
+		return err
+	}
+
+	stmt, err := tx.PrepareContext(
+		ctx,
+		`INSERT INTO emission_factors (id, version, category, unit, factor, source, region, valid_from, valid_to)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+	)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for _, factor := range factors {
+		factor.Version = version.Version
+		_, err := stmt.ExecContext(
+			ctx,
+			factor.ID,
+			factor.Version,
+			factor.Category,
+			factor.Unit,
+			factor.Factor,
+			factor.Source,
+			factor.Region,
+			factor.ValidFrom,
+			factor.ValidTo,
+		)
+		if err != nil {
+			r.Logger.Errorf("failed to store emission factor %s: %v", factor.Category, err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.Logger.Errorf("failed to commit emission factor version: %v", err)
+		return err
+	}
+	version.FactorCount = len(factors)
+	r.Logger.Infof("imported emission factor version %d with %d factor(s)", version.Version, len(factors))
+	return nil
+}
+
+// GetVersion retrieves a version by number
+func (r *EmissionFactorRepositoryDB) GetVersion(ctx context.Context, number int) (*domain.EmissionFactorVersion, error) {
+	var version domain.EmissionFactorVersion
+	row := r.DB.QueryRowContext(ctx, "SELECT "+versionColumns+" FROM emission_factor_versions v WHERE v.version = $1", number)
+	if err := scanVersion(row, &version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrFactorVersionNotFound
+		}
+		r.Logger.Errorf("database error while fetching emission factor version: %v", err)
+		return nil, err
+	}
+	return &version, nil
+}
+
+// ListVersions returns every version, newest first
+func (r *EmissionFactorRepositoryDB) ListVersions(ctx context.Context) ([]*domain.EmissionFactorVersion, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT "+versionColumns+" FROM emission_factor_versions v ORDER BY v.version DESC")
+	if err != nil {
+		r.Logger.Errorf("database error while listing emission factor versions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []*domain.EmissionFactorVersion{}
+	for rows.Next() {
+		var version domain.EmissionFactorVersion
+		if err := scanVersion(rows, &version); err != nil {
+			return nil, err
+		}
+		versions = append(versions, &version)
+	}
+	return versions, rows.Err()
+}
+
+// Publish makes a draft the current version
+func (r *EmissionFactorRepositoryDB) Publish(ctx context.Context, number int) error {
+	result, err := r.DB.ExecContext(
+		ctx,
+		"UPDATE emission_factor_versions SET published_at = now() WHERE version = $1 AND published_at IS NULL",
+		number,
+	)
+	if err != nil {
+		r.Logger.Errorf("failed to publish emission factor version %d: %v", number, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		if _, err := r.GetVersion(ctx, number); err != nil {
+			return err
+		}
+		return domain.ErrFactorVersionPublished
+	}
+	r.Logger.Infof("published emission factor version %d", number)
+	return nil
+}
+
+// CurrentVersion returns the most recently published version
+func (r *EmissionFactorRepositoryDB) CurrentVersion(ctx context.Context) (*domain.EmissionFactorVersion, error) {
+	var version domain.EmissionFactorVersion
+	row := r.DB.QueryRowContext(
+		ctx,
+		"SELECT "+versionColumns+` FROM emission_factor_versions v
+		 WHERE v.published_at IS NOT NULL
+		 ORDER BY v.published_at DESC, v.version DESC
+		 LIMIT 1`,
+	)
+	if err := scanVersion(row, &version); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrFactorVersionNotFound
+		}
+		r.Logger.Errorf("database error while fetching current emission factor version: %v", err)
+		return nil, err
+	}
+	return &version, nil
+}
+
+// ListFactors returns the factors of a version ordered by category and region
+func (r *EmissionFactorRepositoryDB) ListFactors(ctx context.Context, version int) ([]*domain.EmissionFactor, error) {
+	return r.queryFactors(ctx, "SELECT "+factorColumns+" FROM emission_factors WHERE version = $1 ORDER BY category, region, valid_from NULLS FIRST", version)
+}
+
+// FindFactors returns the factors of a category in a version
+func (r *EmissionFactorRepositoryDB) FindFactors(ctx context.Context, version int, category string) ([]*domain.EmissionFactor, error) {
+	return r.queryFactors(ctx, "SELECT "+factorColumns+" FROM emission_factors WHERE version = $1 AND category = $2", version, category)
+}
+
+func (r *EmissionFactorRepositoryDB) queryFactors(ctx context.Context, query string, args ...interface{}) ([]*domain.EmissionFactor, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.Logger.Errorf("database error while listing emission factors: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	factors := []*domain.EmissionFactor{}
+	for rows.Next() {
+		var factor domain.EmissionFactor
+		if err := scanFactor(rows, &factor); err != nil {
+			return nil, err
+		}
+		factors = append(factors, &factor)
+	}
+	return factors, rows.Err()
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+	"github.com/lib/pq"
+)
+
+// goalColumns is the column list read by every lookup; keep in sync with scanGoal
+const goalColumns = `id, user_id, week, timezone, starts_at, ends_at, target_co2, proposed, status,
+	emitted, prev_emitted, saved_by_tasks, reduction, percent, created_at, closed_at`
+
+func scanGoal(row rowScanner, goal *domain.WeeklyGoal) error {
+	var closedAt sql.NullTime
+	if err := row.Scan(
+		&goal.ID,
+		&goal.UserID,
+		&goal.Week,
+		&goal.Timezone,
+		&goal.StartsAt,
+		&goal.EndsAt,
+		&goal.TargetCO2,
+		&goal.Proposed,
+		&goal.Status,
+		&goal.Progress.Emitted,
+		&goal.Progress.PrevEmitted,
+		&goal.Progress.SavedByTasks,
+		&goal.Progress.Reduction,
+		&goal.Progress.Percent,
+		&goal.CreatedAt,
+		&closedAt,
+	); err != nil {
+		return err
+	}
+	if closedAt.Valid {
+		goal.ClosedAt = &closedAt.Time
+	}
+	return nil
+}
+
+// GoalRepositoryDB implements domain.GoalService on the weekly_goals table
+type GoalRepositoryDB struct {
+	DB     *sql.DB
+	Logger repository.Logger
+}
+
+// NewGoalRepository creates a new weekly goal repository instance
+func NewGoalRepository(db *sql.DB, logger repository.Logger) domain.GoalService {
+	return &GoalRepositoryDB{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// Create stores a goal for a week the user has none for yet
+func (r *GoalRepositoryDB) Create(ctx context.Context, goal *domain.WeeklyGoal) error {
+	err := r.DB.QueryRowContext(
+		ctx,
+		`INSERT INTO weekly_goals (id, user_id, week, timezone, starts_at, ends_at, target_co2, proposed, status)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 RETURNING created_at`,
+		goal.ID,
+		goal.UserID,
+		goal.Week,
+		goal.Timezone,
+		goal.StartsAt,
+		goal.EndsAt,
+		goal.TargetCO2,
+		goal.Proposed,
+		goal.Status,
+	).Scan(&goal.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation {
+			return domain.ErrGoalConflict
+		}
+		r.Logger.Errorf("failed to create weekly goal: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Get retrieves the user's goal for an ISO week
+func (r *GoalRepositoryDB) Get(ctx context.Context, userID, week string) (*domain.WeeklyGoal, error) {
+	var goal domain.WeeklyGoal
+	row := r.DB.QueryRowContext(ctx, "SELECT "+goalColumns+" FROM weekly_goals WHERE user_id = $1 AND week = $2", userID, week)
+	if err := scanGoal(row, &goal); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrGoalNotFound
+		}
+		r.Logger.Errorf("database error while fetching weekly goal: %v", err)
+		return nil, err
+	}
+	return &goal, nil
+}
+
+// List returns the user's goals, most recent week first
+func (r *GoalRepositoryDB) List(ctx context.Context, userID string, limit int) ([]*domain.WeeklyGoal, error) {
+	return r.query(ctx, "SELECT "+goalColumns+" FROM weekly_goals WHERE user_id = $1 ORDER BY starts_at DESC LIMIT $2", userID, limit)
+}
+
+// ListDue returns active goals whose week ended by now, oldest first
+func (r *GoalRepositoryDB) ListDue(ctx context.Context, now time.Time, limit int) ([]*domain.WeeklyGoal, error) {
+	return r.query(ctx, "SELECT "+goalColumns+" FROM weekly_goals WHERE status = $1 AND ends_at <= $2 ORDER BY ends_at LIMIT $3", domain.GoalActive, now, limit)
+}
+
+func (r *GoalRepositoryDB) query(ctx context.Context, query string, args ...interface{}) ([]*domain.WeeklyGoal, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.Logger.Errorf("database error while listing weekly goals: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	goals := []*domain.WeeklyGoal{}
+	for rows.Next() {
+		var goal domain.WeeklyGoal
+		if err := scanGoal(rows, &goal); err != nil {
+			return nil, err
+		}
+		goals = append(goals, &goal)
+	}
+	return goals, rows.Err()
+}
+
+// Update stores a goal's target, status and recorded progress
+func (r *GoalRepositoryDB) Update(ctx context.Context, goal *domain.WeeklyGoal) error {
+	result, err := r.DB.ExecContext(
+		ctx,
+		`UPDATE weekly_goals
+		 SET target_co2 = $2, proposed = $3, status = $4,
+		     emitted = $5, prev_emitted = $6, saved_by_tasks = $7, reduction = $8, percent = $9, closed_at = $10
+		 WHERE id = $1`,
+		goal.ID,
+		goal.TargetCO2,
+		goal.Proposed,
+		goal.Status,
+		goal.Progress.Emitted,
+		goal.Progress.PrevEmitted,
+		goal.Progress.SavedByTasks,
+		goal.Progress.Reduction,
+		goal.Progress.Percent,
+		goal.ClosedAt,
+	)
+	if err != nil {
+		r.Logger.Errorf("failed to update weekly goal %s: %v", goal.ID, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrGoalNotFound
+	}
+	return nil
+}
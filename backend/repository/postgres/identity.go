@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+	"github.com/lib/pq"
+)
+
+// IdentityRepositoryDB implements domain.IdentityService on the user_identities table
+type IdentityRepositoryDB struct {
+	DB     *sql.DB
+	Logger repository.Logger
+}
+
+// NewIdentityRepository creates a new identity repository instance
+func NewIdentityRepository(db *sql.DB, logger repository.Logger) domain.IdentityService {
+	return &IdentityRepositoryDB{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// Create links a provider account to a user
+func (r *IdentityRepositoryDB) Create(ctx context.Context, identity *domain.Identity) error {
+	err := r.DB.QueryRowContext(
+		ctx,
+		"INSERT INTO user_identities (provider, subject, user_id, email) VALUES ($1, $2, $3, $4) RETURNING created_at",
+		identity.Provider,
+		identity.Subject,
+		identity.UserID,
+		nullable(identity.Email),
+	).Scan(&identity.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation {
+			return domain.ErrIdentityConflict
+		}
+		r.Logger.Errorf("failed to create identity: %v", err)
+		return err
+	}
+	r.Logger.Infof("linked %s identity to user %s", identity.Provider, identity.UserID)
+	return nil
+}
+
+// GetByProviderSubject retrieves the identity of a provider account
+func (r *IdentityRepositoryDB) GetByProviderSubject(ctx context.Context, provider, subject string) (*domain.Identity, error) {
+	var identity domain.Identity
+	var email sql.NullString
+	err := r.DB.QueryRowContext(
+		ctx,
+		"SELECT provider, subject, user_id, email, created_at FROM user_identities WHERE provider = $1 AND subject = $2",
+		provider,
+		subject,
+	).Scan(&identity.Provider, &identity.Subject, &identity.UserID, &email, &identity.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrIdentityNotFound
+		}
+		r.Logger.Errorf("database error while fetching identity: %v", err)
+		return nil, err
+	}
+	identity.Email = email.String
+	return &identity, nil
+}
+
+// ListByUser returns every identity linked to the user
+func (r *IdentityRepositoryDB) ListByUser(ctx context.Context, userID string) ([]*domain.Identity, error) {
+	rows, err := r.DB.QueryContext(
+		ctx,
+		"SELECT provider, subject, user_id, email, created_at FROM user_identities WHERE user_id = $1 ORDER BY provider",
+		userID,
+	)
+	if err != nil {
+		r.Logger.Errorf("database error while listing identities: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []*domain.Identity{}
+	for rows.Next() {
+		var identity domain.Identity
+		var email sql.NullString
+		if err := rows.Scan(&identity.Provider, &identity.Subject, &identity.UserID, &email, &identity.CreatedAt); err != nil {
+			return nil, err
+		}
+		identity.Email = email.String
+		identities = append(identities, &identity)
+	}
+	return identities, rows.Err()
+}
+
+// Delete unlinks the user's identity at a provider
+func (r *IdentityRepositoryDB) Delete(ctx context.Context, userID, provider string) error {
+	result, err := r.DB.ExecContext(
+		ctx,
+		"DELETE FROM user_identities WHERE user_id = $1 AND provider = $2",
+		userID,
+		provider,
+	)
+	if err != nil {
+		r.Logger.Errorf("failed to delete identity: %v", err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrIdentityNotFound
+	}
+	r.Logger.Infof("unlinked %s identity from user %s", provider, userID)
+	return nil
+}
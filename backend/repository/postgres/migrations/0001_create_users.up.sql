@@ -0,0 +1,16 @@
+CREATE TABLE users (
+    id            TEXT PRIMARY KEY,
+    login         TEXT NOT NULL,
+    email         TEXT,
+    phone_number  TEXT,
+    password_hash TEXT NOT NULL DEFAULT '',
+    co2           DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    deleted_at    TIMESTAMPTZ
+);
+
+-- A login stays reserved after a soft delete so Restore is unambiguous;
+-- email and phone number are only unique among active users.
+CREATE UNIQUE INDEX users_login_key ON users (login);
+CREATE UNIQUE INDEX users_email_active_key ON users (email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX users_phone_number_active_key ON users (phone_number) WHERE deleted_at IS NULL;
@@ -0,0 +1,11 @@
+CREATE TABLE user_identities (
+    provider   TEXT NOT NULL,
+    subject    TEXT NOT NULL,
+    user_id    TEXT NOT NULL REFERENCES users (id),
+    email      TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (provider, subject)
+);
+
+-- One identity per provider per user
+CREATE UNIQUE INDEX user_identities_user_provider_key ON user_identities (user_id, provider);
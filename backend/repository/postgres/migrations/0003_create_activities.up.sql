@@ -0,0 +1,14 @@
+CREATE TABLE activities (
+    id            TEXT PRIMARY KEY,
+    user_id       TEXT NOT NULL REFERENCES users (id),
+    category      TEXT NOT NULL,
+    quantity      DOUBLE PRECISION NOT NULL,
+    unit          TEXT NOT NULL,
+    factor        DOUBLE PRECISION NOT NULL,
+    factor_source TEXT NOT NULL,
+    co2e          DOUBLE PRECISION NOT NULL,
+    occurred_at   TIMESTAMPTZ NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX activities_user_occurred_at_idx ON activities (user_id, occurred_at DESC);
@@ -0,0 +1,7 @@
+ALTER TABLE activities
+    DROP COLUMN region,
+    DROP COLUMN factor_id,
+    DROP COLUMN factor_version;
+
+DROP TABLE emission_factors;
+DROP TABLE emission_factor_versions;
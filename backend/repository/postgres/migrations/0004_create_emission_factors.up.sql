@@ -0,0 +1,27 @@
+CREATE TABLE emission_factor_versions (
+    version      SERIAL PRIMARY KEY,
+    note         TEXT NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    published_at TIMESTAMPTZ
+);
+
+-- An empty region is the default for every region without an override
+CREATE TABLE emission_factors (
+    id         TEXT PRIMARY KEY,
+    version    INTEGER NOT NULL REFERENCES emission_factor_versions (version),
+    category   TEXT NOT NULL,
+    unit       TEXT NOT NULL,
+    factor     DOUBLE PRECISION NOT NULL,
+    source     TEXT NOT NULL DEFAULT '',
+    region     TEXT NOT NULL DEFAULT '',
+    valid_from TIMESTAMPTZ,
+    valid_to   TIMESTAMPTZ
+);
+
+CREATE INDEX emission_factors_version_category_idx ON emission_factors (version, category);
+
+-- Activities logged before the catalogue existed have no factor_id
+ALTER TABLE activities
+    ADD COLUMN region         TEXT NOT NULL DEFAULT '',
+    ADD COLUMN factor_id      TEXT REFERENCES emission_factors (id),
+    ADD COLUMN factor_version INTEGER;
@@ -0,0 +1,3 @@
+DROP TABLE survey_responses;
+
+ALTER TABLE users DROP COLUMN co2_baseline;
@@ -0,0 +1,13 @@
+ALTER TABLE users ADD COLUMN co2_baseline DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+CREATE TABLE survey_responses (
+    id          TEXT PRIMARY KEY,
+    user_id     TEXT NOT NULL REFERENCES users (id),
+    survey_id   TEXT NOT NULL,
+    answers     JSONB NOT NULL,
+    annual_co2e DOUBLE PRECISION NOT NULL,
+    breakdown   JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX survey_responses_user_survey_idx ON survey_responses (user_id, survey_id, created_at DESC);
@@ -0,0 +1,4 @@
+DROP TABLE task_assignments;
+DROP TABLE tasks;
+
+ALTER TABLE users DROP COLUMN points, DROP COLUMN co2_saved;
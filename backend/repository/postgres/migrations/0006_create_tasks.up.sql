@@ -0,0 +1,31 @@
+ALTER TABLE users
+    ADD COLUMN points    INTEGER          NOT NULL DEFAULT 0,
+    ADD COLUMN co2_saved DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+CREATE TABLE tasks (
+    id          TEXT PRIMARY KEY,
+    title       TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    category    TEXT NOT NULL,
+    co2_saved   DOUBLE PRECISION NOT NULL,
+    difficulty  TEXT NOT NULL,
+    points      INTEGER NOT NULL,
+    active      BOOLEAN NOT NULL DEFAULT true,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE task_assignments (
+    id           TEXT PRIMARY KEY,
+    user_id      TEXT NOT NULL REFERENCES users (id),
+    task_id      TEXT NOT NULL REFERENCES tasks (id),
+    status       TEXT NOT NULL,
+    proof        TEXT NOT NULL DEFAULT '',
+    points       INTEGER NOT NULL DEFAULT 0,
+    co2_saved    DOUBLE PRECISION NOT NULL DEFAULT 0,
+    assigned_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at TIMESTAMPTZ
+);
+
+-- A user can only have one open assignment per task
+CREATE UNIQUE INDEX task_assignments_open_key ON task_assignments (user_id, task_id) WHERE status = 'assigned';
+CREATE INDEX task_assignments_user_idx ON task_assignments (user_id, assigned_at DESC);
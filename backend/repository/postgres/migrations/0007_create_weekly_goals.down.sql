@@ -0,0 +1 @@
+DROP TABLE weekly_goals;
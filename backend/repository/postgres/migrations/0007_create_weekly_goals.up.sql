@@ -0,0 +1,22 @@
+CREATE TABLE weekly_goals (
+    id             TEXT PRIMARY KEY,
+    user_id        TEXT NOT NULL REFERENCES users (id),
+    week           TEXT NOT NULL,
+    timezone       TEXT NOT NULL,
+    starts_at      TIMESTAMPTZ NOT NULL,
+    ends_at        TIMESTAMPTZ NOT NULL,
+    target_co2     DOUBLE PRECISION NOT NULL,
+    proposed       BOOLEAN NOT NULL DEFAULT false,
+    status         TEXT NOT NULL,
+    emitted        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    prev_emitted   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    saved_by_tasks DOUBLE PRECISION NOT NULL DEFAULT 0,
+    reduction      DOUBLE PRECISION NOT NULL DEFAULT 0,
+    percent        DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    closed_at      TIMESTAMPTZ,
+    UNIQUE (user_id, week)
+);
+
+-- The rollover scheduler looks for active goals whose week has ended
+CREATE INDEX weekly_goals_due_idx ON weekly_goals (ends_at) WHERE status = 'active';
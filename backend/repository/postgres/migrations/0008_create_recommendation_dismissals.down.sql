@@ -0,0 +1 @@
+DROP TABLE recommendation_dismissals;
@@ -0,0 +1,6 @@
+CREATE TABLE recommendation_dismissals (
+    user_id           TEXT NOT NULL REFERENCES users (id),
+    recommendation_id TEXT NOT NULL,
+    dismissed_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, recommendation_id)
+);
@@ -0,0 +1,13 @@
+CREATE TABLE coach_exchanges (
+    id          TEXT PRIMARY KEY,
+    user_id     TEXT NOT NULL REFERENCES users (id),
+    provider    TEXT NOT NULL,
+    prompt      TEXT NOT NULL,
+    message     TEXT NOT NULL,
+    reply       TEXT NOT NULL DEFAULT '',
+    error       TEXT NOT NULL DEFAULT '',
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX coach_exchanges_user_created_idx ON coach_exchanges (user_id, created_at DESC);
@@ -0,0 +1,6 @@
+CREATE TABLE user_achievements (
+    user_id    TEXT NOT NULL REFERENCES users (id),
+    badge_id   TEXT NOT NULL,
+    awarded_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (user_id, badge_id)
+);
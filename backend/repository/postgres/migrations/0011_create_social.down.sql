@@ -0,0 +1,3 @@
+DROP TABLE privacy_settings;
+DROP TABLE user_follows;
+DROP TABLE user_relations;
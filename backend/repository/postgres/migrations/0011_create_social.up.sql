@@ -0,0 +1,28 @@
+CREATE TABLE user_relations (
+    user_id    TEXT NOT NULL REFERENCES users (id),
+    other_id   TEXT NOT NULL REFERENCES users (id),
+    status     TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, other_id),
+    CHECK (user_id <> other_id)
+);
+
+CREATE INDEX user_relations_other_idx ON user_relations (other_id, status);
+
+CREATE TABLE user_follows (
+    follower_id TEXT NOT NULL REFERENCES users (id),
+    followee_id TEXT NOT NULL REFERENCES users (id),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (follower_id, followee_id),
+    CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX user_follows_followee_idx ON user_follows (followee_id, created_at DESC);
+
+CREATE TABLE privacy_settings (
+    user_id    TEXT PRIMARY KEY REFERENCES users (id),
+    co2        TEXT NOT NULL,
+    activity   TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
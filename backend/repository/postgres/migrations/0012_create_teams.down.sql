@@ -0,0 +1,4 @@
+DROP TABLE team_challenges;
+DROP TABLE team_invites;
+DROP TABLE team_members;
+DROP TABLE teams;
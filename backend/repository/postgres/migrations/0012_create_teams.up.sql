@@ -0,0 +1,43 @@
+CREATE TABLE teams (
+    id          TEXT PRIMARY KEY,
+    name        TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    invite_code TEXT NOT NULL UNIQUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE team_members (
+    team_id   TEXT NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    user_id   TEXT NOT NULL REFERENCES users (id),
+    role      TEXT NOT NULL,
+    joined_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX team_members_user_idx ON team_members (user_id, joined_at DESC);
+
+CREATE TABLE team_invites (
+    team_id    TEXT NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    user_id    TEXT NOT NULL REFERENCES users (id),
+    invited_by TEXT NOT NULL REFERENCES users (id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (team_id, user_id)
+);
+
+CREATE INDEX team_invites_user_idx ON team_invites (user_id, created_at DESC);
+
+CREATE TABLE team_challenges (
+    id          TEXT PRIMARY KEY,
+    team_id     TEXT NOT NULL REFERENCES teams (id) ON DELETE CASCADE,
+    title       TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    metric      TEXT NOT NULL,
+    target      DOUBLE PRECISION NOT NULL,
+    starts_at   TIMESTAMPTZ NOT NULL,
+    ends_at     TIMESTAMPTZ NOT NULL,
+    created_by  TEXT NOT NULL REFERENCES users (id),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX team_challenges_team_idx ON team_challenges (team_id, starts_at DESC);
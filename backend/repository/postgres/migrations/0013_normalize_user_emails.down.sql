@@ -0,0 +1,2 @@
+-- The original case of emails is not kept, so there is nothing to restore.
+SELECT 1;
@@ -0,0 +1,9 @@
+-- Emails are stored lower-cased since addresses differing in case belong to
+-- one account. Addresses that would then collide with another active user
+-- are left alone.
+UPDATE users u SET email = lower(u.email)
+WHERE u.email <> lower(u.email)
+  AND NOT EXISTS (
+      SELECT 1 FROM users o
+      WHERE o.id <> u.id AND o.deleted_at IS NULL AND lower(o.email) = lower(u.email)
+  );
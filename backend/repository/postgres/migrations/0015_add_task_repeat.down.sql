@@ -0,0 +1,3 @@
+DROP INDEX task_assignments_period_key;
+ALTER TABLE task_assignments DROP COLUMN period;
+ALTER TABLE tasks DROP COLUMN repeat;
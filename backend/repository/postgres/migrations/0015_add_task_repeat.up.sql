@@ -0,0 +1,21 @@
+ALTER TABLE tasks ADD COLUMN repeat TEXT NOT NULL DEFAULT 'weekly';
+ALTER TABLE task_assignments ADD COLUMN period TEXT;
+
+-- Existing tasks repeat weekly. The first completion of a task in each ISO
+-- week keeps that week as its period; repeats within the week were awarded
+-- already and are left without one.
+UPDATE task_assignments a SET period = c.period
+FROM (
+    SELECT id,
+           to_char(completed_at AT TIME ZONE 'UTC', 'IYYY-"W"IW') AS period,
+           row_number() OVER (
+               PARTITION BY user_id, task_id, to_char(completed_at AT TIME ZONE 'UTC', 'IYYY-"W"IW')
+               ORDER BY completed_at
+           ) AS n
+    FROM task_assignments
+    WHERE status = 'completed'
+) c
+WHERE a.id = c.id AND c.n = 1;
+
+-- A user can complete a task once per period of its repeat policy
+CREATE UNIQUE INDEX task_assignments_period_key ON task_assignments (user_id, task_id, period);
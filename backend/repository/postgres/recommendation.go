@@ -0,0 +1,59 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+)
+
+// RecommendationRepositoryDB implements domain.RecommendationService on the
+// recommendation_dismissals table
+type RecommendationRepositoryDB struct {
+	DB     *sql.DB
+	Logger repository.Logger
+}
+
+// NewRecommendationRepository creates a new recommendation repository instance
+func NewRecommendationRepository(db *sql.DB, logger repository.Logger) domain.RecommendationService {
+	return &RecommendationRepositoryDB{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// Dismiss records that the user doesn't want a recommendation again
+func (r *RecommendationRepositoryDB) Dismiss(ctx context.Context, userID, recommendationID string) error {
+	_, err := r.DB.ExecContext(
+		ctx,
+		`INSERT INTO recommendation_dismissals (user_id, recommendation_id) VALUES ($1, $2)
+		 ON CONFLICT (user_id, recommendation_id) DO NOTHING`,
+		userID,
+		recommendationID,
+	)
+	if err != nil {
+		r.Logger.Errorf("failed to dismiss recommendation %s for user %s: %v", recommendationID, userID, err)
+	}
+	return err
+}
+
+// Dismissed lists the recommendations the user dismissed
+func (r *RecommendationRepositoryDB) Dismissed(ctx context.Context, userID string) ([]string, error) {
+	rows, err := r.DB.QueryContext(ctx, "SELECT recommendation_id FROM recommendation_dismissals WHERE user_id = $1", userID)
+	if err != nil {
+		r.Logger.Errorf("database error while listing dismissed recommendations: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
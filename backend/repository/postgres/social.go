@@ -0,0 +1,213 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+)
+
+// relationColumns is the column list read by every lookup; keep in sync with scanRelation
+const relationColumns = "user_id, other_id, status, created_at, updated_at"
+
+func scanRelation(row rowScanner, relation *domain.Relation) error {
+	return row.Scan(
+		&relation.UserID,
+		&relation.OtherID,
+		&relation.Status,
+		&relation.CreatedAt,
+		&relation.UpdatedAt,
+	)
+}
+
+// SocialRepositoryDB implements domain.SocialService on the user_relations,
+// user_follows and privacy_settings tables
+type SocialRepositoryDB struct {
+	DB     *sql.DB
+	Logger repository.Logger
+}
+
+// NewSocialRepository creates a new social repository instance
+func NewSocialRepository(db *sql.DB, logger repository.Logger) domain.SocialService {
+	return &SocialRepositoryDB{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// queryRelations runs a query selecting relationColumns
+func (r *SocialRepositoryDB) queryRelations(ctx context.Context, query string, args ...interface{}) ([]*domain.Relation, error) {
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.Logger.Errorf("database error while listing relations: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	relations := []*domain.Relation{}
+	for rows.Next() {
+		var relation domain.Relation
+		if err := scanRelation(rows, &relation); err != nil {
+			return nil, err
+		}
+		relations = append(relations, &relation)
+	}
+	return relations, rows.Err()
+}
+
+// Between returns the relations between a and b in either direction
+func (r *SocialRepositoryDB) Between(ctx context.Context, a, b string) ([]*domain.Relation, error) {
+	return r.queryRelations(
+		ctx,
+		"SELECT "+relationColumns+" FROM user_relations WHERE (user_id = $1 AND other_id = $2) OR (user_id = $2 AND other_id = $1)",
+		a,
+		b,
+	)
+}
+
+// SaveRelation creates or replaces the relation from UserID to OtherID
+func (r *SocialRepositoryDB) SaveRelation(ctx context.Context, relation *domain.Relation) error {
+	err := r.DB.QueryRowContext(
+		ctx,
+		`INSERT INTO user_relations (user_id, other_id, status) VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id, other_id) DO UPDATE SET status = EXCLUDED.status, updated_at = now()
+		 RETURNING created_at, updated_at`,
+		relation.UserID,
+		relation.OtherID,
+		relation.Status,
+	).Scan(&relation.CreatedAt, &relation.UpdatedAt)
+	if err != nil {
+		r.Logger.Errorf("failed to save relation: %v", err)
+		return err
+	}
+	r.Logger.Infof("user %s is now %s with user %s", relation.UserID, relation.Status, relation.OtherID)
+	return nil
+}
+
+// DeleteRelation removes the relation from userID to otherID
+func (r *SocialRepositoryDB) DeleteRelation(ctx context.Context, userID, otherID string) error {
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM user_relations WHERE user_id = $1 AND other_id = $2", userID, otherID)
+	if err != nil {
+		r.Logger.Errorf("failed to delete relation: %v", err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrRelationNotFound
+	}
+	return nil
+}
+
+// Relations returns the user's relations with status in either direction
+func (r *SocialRepositoryDB) Relations(ctx context.Context, userID, status string) ([]*domain.Relation, error) {
+	return r.queryRelations(
+		ctx,
+		"SELECT "+relationColumns+" FROM user_relations WHERE (user_id = $1 OR other_id = $1) AND status = $2 ORDER BY updated_at DESC",
+		userID,
+		status,
+	)
+}
+
+// Follow records that followerID follows followeeID
+func (r *SocialRepositoryDB) Follow(ctx context.Context, followerID, followeeID string) error {
+	_, err := r.DB.ExecContext(
+		ctx,
+		`INSERT INTO user_follows (follower_id, followee_id) VALUES ($1, $2)
+		 ON CONFLICT (follower_id, followee_id) DO NOTHING`,
+		followerID,
+		followeeID,
+	)
+	if err != nil {
+		r.Logger.Errorf("failed to follow user %s: %v", followeeID, err)
+	}
+	return err
+}
+
+// Unfollow removes a follow
+func (r *SocialRepositoryDB) Unfollow(ctx context.Context, followerID, followeeID string) error {
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM user_follows WHERE follower_id = $1 AND followee_id = $2", followerID, followeeID)
+	if err != nil {
+		r.Logger.Errorf("failed to unfollow user %s: %v", followeeID, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrFollowNotFound
+	}
+	return nil
+}
+
+// IsFollowing reports whether followerID follows followeeID
+func (r *SocialRepositoryDB) IsFollowing(ctx context.Context, followerID, followeeID string) (bool, error) {
+	var following bool
+	err := r.DB.QueryRowContext(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM user_follows WHERE follower_id = $1 AND followee_id = $2)",
+		followerID,
+		followeeID,
+	).Scan(&following)
+	if err != nil {
+		r.Logger.Errorf("database error while checking follow: %v", err)
+	}
+	return following, err
+}
+
+// queryContacts runs a query selecting a user ID and a timestamp
+func (r *SocialRepositoryDB) queryContacts(ctx context.Context, query, userID string) ([]*domain.Contact, error) {
+	rows, err := r.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		r.Logger.Errorf("database error while listing follows: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	contacts := []*domain.Contact{}
+	for rows.Next() {
+		var contact domain.Contact
+		if err := rows.Scan(&contact.UserID, &contact.Since); err != nil {
+			return nil, err
+		}
+		contacts = append(contacts, &contact)
+	}
+	return contacts, rows.Err()
+}
+
+// Followers returns who follows the user, most recent first
+func (r *SocialRepositoryDB) Followers(ctx context.Context, userID string) ([]*domain.Contact, error) {
+	return r.queryContacts(ctx, "SELECT follower_id, created_at FROM user_follows WHERE followee_id = $1 ORDER BY created_at DESC", userID)
+}
+
+// Following returns whom the user follows, most recent first
+func (r *SocialRepositoryDB) Following(ctx context.Context, userID string) ([]*domain.Contact, error) {
+	return r.queryContacts(ctx, "SELECT followee_id, created_at FROM user_follows WHERE follower_id = $1 ORDER BY created_at DESC", userID)
+}
+
+// Privacy returns the user's privacy settings
+func (r *SocialRepositoryDB) Privacy(ctx context.Context, userID string) (*domain.PrivacySettings, error) {
+	var settings domain.PrivacySettings
+	err := r.DB.QueryRowContext(ctx, "SELECT co2, activity FROM privacy_settings WHERE user_id = $1", userID).Scan(&settings.CO2, &settings.Activity)
+	if errors.Is(err, sql.ErrNoRows) {
+		settings = domain.DefaultPrivacy
+		return &settings, nil
+	} else if err != nil {
+		r.Logger.Errorf("database error while fetching privacy settings: %v", err)
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// SetPrivacy stores the user's privacy settings
+func (r *SocialRepositoryDB) SetPrivacy(ctx context.Context, userID string, settings *domain.PrivacySettings) error {
+	_, err := r.DB.ExecContext(
+		ctx,
+		`INSERT INTO privacy_settings (user_id, co2, activity) VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id) DO UPDATE SET co2 = EXCLUDED.co2, activity = EXCLUDED.activity, updated_at = now()`,
+		userID,
+		settings.CO2,
+		settings.Activity,
+	)
+	if err != nil {
+		r.Logger.Errorf("failed to save privacy settings of user %s: %v", userID, err)
+	}
+	return err
+}
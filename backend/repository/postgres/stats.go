@@ -0,0 +1,115 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+)
+
+// StatsRepositoryDB implements domain.StatsService with aggregations over
+// the activities table
+type StatsRepositoryDB struct {
+	DB     *sql.DB
+	Logger repository.Logger
+}
+
+// NewStatsRepository creates a new stats repository instance
+func NewStatsRepository(db *sql.DB, logger repository.Logger) domain.StatsService {
+	return &StatsRepositoryDB{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// Series sums the user's activities per day, ISO week or month in loc
+func (r *StatsRepositoryDB) Series(ctx context.Context, userID, period string, loc *time.Location, from, to time.Time) ([]domain.StatsPoint, error) {
+	// date_trunc works on local wall time, so periods start at local
+	// midnight and weeks on Monday
+	rows, err := r.DB.QueryContext(
+		ctx,
+		`SELECT date_trunc($2, occurred_at AT TIME ZONE $3) AT TIME ZONE $3 AS start, SUM(co2e), COUNT(*)
+		 FROM activities
+		 WHERE user_id = $1 AND occurred_at >= $4 AND occurred_at < $5
+		 GROUP BY start ORDER BY start`,
+		userID,
+		period,
+		loc.String(),
+		from,
+		to,
+	)
+	if err != nil {
+		r.Logger.Errorf("database error while aggregating activities of user %s: %v", userID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	points := []domain.StatsPoint{}
+	for rows.Next() {
+		var point domain.StatsPoint
+		if err := rows.Scan(&point.Start, &point.CO2, &point.Activities); err != nil {
+			return nil, err
+		}
+		point.Start = point.Start.In(loc)
+		points = append(points, point)
+	}
+	return points, rows.Err()
+}
+
+// Breakdown sums the user's activities by category, largest first
+func (r *StatsRepositoryDB) Breakdown(ctx context.Context, userID string, from, to time.Time) ([]domain.CategoryTotal, error) {
+	rows, err := r.DB.QueryContext(
+		ctx,
+		`SELECT category, SUM(co2e) AS co2, COUNT(*)
+		 FROM activities
+		 WHERE user_id = $1 AND occurred_at >= $2 AND occurred_at < $3
+		 GROUP BY category ORDER BY co2 DESC, category`,
+		userID,
+		from,
+		to,
+	)
+	if err != nil {
+		r.Logger.Errorf("database error while breaking down activities of user %s: %v", userID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	totals := []domain.CategoryTotal{}
+	for rows.Next() {
+		var total domain.CategoryTotal
+		if err := rows.Scan(&total.Category, &total.CO2, &total.Activities); err != nil {
+			return nil, err
+		}
+		totals = append(totals, total)
+	}
+	return totals, rows.Err()
+}
+
+// ActiveDays returns the local dates the user logged activities on, most recent first
+func (r *StatsRepositoryDB) ActiveDays(ctx context.Context, userID string, loc *time.Location) ([]string, error) {
+	rows, err := r.DB.QueryContext(
+		ctx,
+		`SELECT DISTINCT to_char(occurred_at AT TIME ZONE $2, 'YYYY-MM-DD') AS day
+		 FROM activities WHERE user_id = $1
+		 ORDER BY day DESC`,
+		userID,
+		loc.String(),
+	)
+	if err != nil {
+		r.Logger.Errorf("database error while listing active days of user %s: %v", userID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := []string{}
+	for rows.Next() {
+		var day string
+		if err := rows.Scan(&day); err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, rows.Err()
+}
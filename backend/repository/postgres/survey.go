@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+	"github.com/go-redis/redis/v8"
+)
+
+// SurveyResponseRepositoryDB implements domain.SurveyResponseService on the
+// survey_responses table
+type SurveyResponseRepositoryDB struct {
+	DB          *sql.DB
+	RedisClient *redis.Client // to drop cached users whose baseline changed
+	Logger      repository.Logger
+}
+
+// NewSurveyResponseRepository creates a new survey response repository instance
+func NewSurveyResponseRepository(db *sql.DB, redisClient *redis.Client, logger repository.Logger) domain.SurveyResponseService {
+	return &SurveyResponseRepositoryDB{
+		DB:          db,
+		RedisClient: redisClient,
+		Logger:      logger,
+	}
+}
+
+// Create stores a response and makes its result the user's CO2 baseline
+func (r *SurveyResponseRepositoryDB) Create(ctx context.Context, response *domain.SurveyResponse) error {
+	answers, err := json.Marshal(response.Answers)
+	if err != nil {
+		return err
+	}
+	breakdown, err := json.Marshal(response.Result.Breakdown)
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var login string
+	var email, phoneNumber sql.NullString
+	err = tx.QueryRowContext(
+		ctx,
+		"UPDATE users SET co2_baseline = $1 WHERE id = $2 AND deleted_at IS NULL RETURNING login, email, phone_number",
+		response.Result.AnnualCO2e,
+		response.UserID,
+	).Scan(&login, &email, &phoneNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ErrUserNotFound
+	} else if err != nil {
+		r.Logger.Errorf("failed to set CO2 baseline of user %s: %v", response.UserID, err)
+		return err
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		`INSERT INTO survey_responses (id, user_id, survey_id, answers, annual_co2e, breakdown)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING created_at`,
+		response.ID,
+		response.UserID,
+		response.SurveyID,
+		answers,
+		response.Result.AnnualCO2e,
+		breakdown,
+	).Scan(&response.CreatedAt)
+	if err != nil {
+		r.Logger.Errorf("failed to store survey response: %v", err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.Logger.Errorf("failed to commit survey response: %v", err)
+		return err
+	}
+
+	if err := r.RedisClient.Del(ctx, cacheKeys(response.UserID, login, email.String, phoneNumber.String)...).Err(); err != nil {
+		r.Logger.Errorf("failed to invalidate cache after CO2 baseline update: %v", err)
+	}
+	r.Logger.Infof("stored %s survey response of user %s", response.SurveyID, response.UserID)
+	return nil
+}
+
+// Latest returns the user's most recent response to a survey
+func (r *SurveyResponseRepositoryDB) Latest(ctx context.Context, userID, surveyID string) (*domain.SurveyResponse, error) {
+	response := domain.SurveyResponse{UserID: userID, SurveyID: surveyID}
+	var answers, breakdown []byte
+	err := r.DB.QueryRowContext(
+		ctx,
+		`SELECT id, answers, annual_co2e, breakdown, created_at FROM survey_responses
+		 WHERE user_id = $1 AND survey_id = $2
+		 ORDER BY created_at DESC
+		 LIMIT 1`,
+		userID,
+		surveyID,
+	).Scan(&response.ID, &answers, &response.Result.AnnualCO2e, &breakdown, &response.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSurveyResponseNotFound
+		}
+		r.Logger.Errorf("database error while fetching survey response: %v", err)
+		return nil, err
+	}
+	if err := json.Unmarshal(answers, &response.Answers); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(breakdown, &response.Result.Breakdown); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -0,0 +1,365 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+	"github.com/go-redis/redis/v8"
+	"github.com/lib/pq"
+)
+
+const (
+	// taskColumns is the column list read by every task lookup; keep in sync with scanTask
+	taskColumns = "id, title, description, category, co2_saved, difficulty, points, repeat, active, created_at"
+
+	// assignmentColumns is the column list read by every assignment lookup,
+	// joined with its task as t; keep in sync with scanAssignment
+	assignmentColumns = `a.id, a.user_id, a.status, a.proof, a.points, a.co2_saved, a.period, a.assigned_at, a.completed_at,
+		t.id, t.title, t.description, t.category, t.co2_saved, t.difficulty, t.points, t.repeat, t.active, t.created_at`
+)
+
+func scanTask(row rowScanner, task *domain.Task) error {
+	return row.Scan(
+		&task.ID,
+		&task.Title,
+		&task.Description,
+		&task.Category,
+		&task.CO2Saved,
+		&task.Difficulty,
+		&task.Points,
+		&task.Repeat,
+		&task.Active,
+		&task.CreatedAt,
+	)
+}
+
+func scanAssignment(row rowScanner, assignment *domain.TaskAssignment) error {
+	var period sql.NullString
+	var completedAt sql.NullTime
+	task := &assignment.Task
+	if err := row.Scan(
+		&assignment.ID,
+		&assignment.UserID,
+		&assignment.Status,
+		&assignment.Proof,
+		&assignment.Points,
+		&assignment.CO2Saved,
+		&period,
+		&assignment.AssignedAt,
+		&completedAt,
+		&task.ID,
+		&task.Title,
+		&task.Description,
+		&task.Category,
+		&task.CO2Saved,
+		&task.Difficulty,
+		&task.Points,
+		&task.Repeat,
+		&task.Active,
+		&task.CreatedAt,
+	); err != nil {
+		return err
+	}
+	assignment.Period = period.String
+	if completedAt.Valid {
+		assignment.CompletedAt = &completedAt.Time
+	}
+	return nil
+}
+
+// TaskRepositoryDB implements domain.TaskService on the tasks and
+// task_assignments tables. Completing an assignment adds to users.points
+// and users.co2_saved.
+type TaskRepositoryDB struct {
+	DB          *sql.DB
+	RedisClient *redis.Client // to drop cached users whose totals changed
+	Logger      repository.Logger
+}
+
+// NewTaskRepository creates a new task repository instance
+func NewTaskRepository(db *sql.DB, redisClient *redis.Client, logger repository.Logger) domain.TaskService {
+	return &TaskRepositoryDB{
+		DB:          db,
+		RedisClient: redisClient,
+		Logger:      logger,
+	}
+}
+
+// CreateTask adds a task to the catalogue
+func (r *TaskRepositoryDB) CreateTask(ctx context.Context, task *domain.Task) error {
+	err := r.DB.QueryRowContext(
+		ctx,
+		`INSERT INTO tasks (id, title, description, category, co2_saved, difficulty, points, repeat, active)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 RETURNING created_at`,
+		task.ID,
+		task.Title,
+		task.Description,
+		task.Category,
+		task.CO2Saved,
+		task.Difficulty,
+		task.Points,
+		task.Repeat,
+		task.Active,
+	).Scan(&task.CreatedAt)
+	if err != nil {
+		r.Logger.Errorf("failed to create task: %v", err)
+		return err
+	}
+	r.Logger.Infof("added task %s to the catalogue", task.ID)
+	return nil
+}
+
+// UpdateTask replaces a catalogue entry
+func (r *TaskRepositoryDB) UpdateTask(ctx context.Context, task *domain.Task) error {
+	err := r.DB.QueryRowContext(
+		ctx,
+		`UPDATE tasks SET title = $2, description = $3, category = $4, co2_saved = $5, difficulty = $6, points = $7, repeat = $8, active = $9
+		 WHERE id = $1
+		 RETURNING created_at`,
+		task.ID,
+		task.Title,
+		task.Description,
+		task.Category,
+		task.CO2Saved,
+		task.Difficulty,
+		task.Points,
+		task.Repeat,
+		task.Active,
+	).Scan(&task.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ErrTaskNotFound
+		}
+		r.Logger.Errorf("failed to update task %s: %v", task.ID, err)
+		return err
+	}
+	return nil
+}
+
+// GetTask retrieves a catalogue entry
+func (r *TaskRepositoryDB) GetTask(ctx context.Context, id string) (*domain.Task, error) {
+	var task domain.Task
+	row := r.DB.QueryRowContext(ctx, "SELECT "+taskColumns+" FROM tasks WHERE id = $1", id)
+	if err := scanTask(row, &task); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTaskNotFound
+		}
+		r.Logger.Errorf("database error while fetching task: %v", err)
+		return nil, err
+	}
+	return &task, nil
+}
+
+// ListTasks returns catalogue entries ordered by category and title
+func (r *TaskRepositoryDB) ListTasks(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	query := "SELECT " + taskColumns + " FROM tasks WHERE true"
+	args := []interface{}{}
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		query += " AND category = $" + strconv.Itoa(len(args))
+	}
+	if filter.Difficulty != "" {
+		args = append(args, filter.Difficulty)
+		query += " AND difficulty = $" + strconv.Itoa(len(args))
+	}
+	if !filter.IncludeInactive {
+		query += " AND active"
+	}
+	query += " ORDER BY category, title"
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.Logger.Errorf("database error while listing tasks: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []*domain.Task{}
+	for rows.Next() {
+		var task domain.Task
+		if err := scanTask(rows, &task); err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, &task)
+	}
+	return tasks, rows.Err()
+}
+
+// Assign gives a task to a user
+func (r *TaskRepositoryDB) Assign(ctx context.Context, assignment *domain.TaskAssignment) error {
+	assignment.Status = domain.TaskAssigned
+	err := r.DB.QueryRowContext(
+		ctx,
+		`INSERT INTO task_assignments (id, user_id, task_id, status)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING assigned_at`,
+		assignment.ID,
+		assignment.UserID,
+		assignment.Task.ID,
+		assignment.Status,
+	).Scan(&assignment.AssignedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation {
+			return domain.ErrTaskAlreadyAssigned
+		}
+		r.Logger.Errorf("failed to assign task: %v", err)
+		return err
+	}
+	r.Logger.Infof("assigned task %s to user %s", assignment.Task.ID, assignment.UserID)
+	return nil
+}
+
+// GetAssignment retrieves one of the user's assignments
+func (r *TaskRepositoryDB) GetAssignment(ctx context.Context, userID, id string) (*domain.TaskAssignment, error) {
+	var assignment domain.TaskAssignment
+	row := r.DB.QueryRowContext(
+		ctx,
+		"SELECT "+assignmentColumns+" FROM task_assignments a JOIN tasks t ON t.id = a.task_id WHERE a.id = $1 AND a.user_id = $2",
+		id,
+		userID,
+	)
+	if err := scanAssignment(row, &assignment); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrTaskAssignmentNotFound
+		}
+		r.Logger.Errorf("database error while fetching task assignment: %v", err)
+		return nil, err
+	}
+	return &assignment, nil
+}
+
+// ListAssignments returns the user's assignments, most recent first
+func (r *TaskRepositoryDB) ListAssignments(ctx context.Context, userID, status string) ([]*domain.TaskAssignment, error) {
+	query := "SELECT " + assignmentColumns + " FROM task_assignments a JOIN tasks t ON t.id = a.task_id WHERE a.user_id = $1"
+	args := []interface{}{userID}
+	if status != "" {
+		args = append(args, status)
+		query += " AND a.status = $2"
+	}
+	query += " ORDER BY a.assigned_at DESC"
+
+	rows, err := r.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		r.Logger.Errorf("database error while listing task assignments: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []*domain.TaskAssignment{}
+	for rows.Next() {
+		var assignment domain.TaskAssignment
+		if err := scanAssignment(rows, &assignment); err != nil {
+			return nil, err
+		}
+		assignments = append(assignments, &assignment)
+	}
+	return assignments, rows.Err()
+}
+
+// Complete marks an open assignment completed in period and awards the
+// task's points and CO2 savings to the user in one transaction. The unique
+// index on user, task and period rejects a second completion in a period.
+func (r *TaskRepositoryDB) Complete(ctx context.Context, userID, id, proof, period string) (*domain.TaskAssignment, error) {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var points int
+	var co2Saved float64
+	err = tx.QueryRowContext(
+		ctx,
+		`UPDATE task_assignments a
+		 SET status = $3, proof = $4, points = t.points, co2_saved = t.co2_saved, period = $6, completed_at = now()
+		 FROM tasks t
+		 WHERE t.id = a.task_id AND a.id = $1 AND a.user_id = $2 AND a.status = $5
+		 RETURNING a.points, a.co2_saved`,
+		id,
+		userID,
+		domain.TaskCompleted,
+		proof,
+		domain.TaskAssigned,
+		period,
+	).Scan(&points, &co2Saved)
+	var pqErr *pq.Error
+	if errors.Is(err, sql.ErrNoRows) {
+		// Either it doesn't exist or it was completed already
+		if _, err := r.GetAssignment(ctx, userID, id); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrTaskAlreadyCompleted
+	} else if errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation {
+		return nil, domain.ErrTaskRepeatTooSoon
+	} else if err != nil {
+		r.Logger.Errorf("failed to complete task assignment %s: %v", id, err)
+		return nil, err
+	}
+
+	var login string
+	var email, phoneNumber sql.NullString
+	err = tx.QueryRowContext(
+		ctx,
+		`UPDATE users SET points = points + $2, co2_saved = co2_saved + $3
+		 WHERE id = $1 AND deleted_at IS NULL
+		 RETURNING login, email, phone_number`,
+		userID,
+		points,
+		co2Saved,
+	).Scan(&login, &email, &phoneNumber)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrUserNotFound
+	} else if err != nil {
+		r.Logger.Errorf("failed to award task to user %s: %v", userID, err)
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		r.Logger.Errorf("failed to commit task completion: %v", err)
+		return nil, err
+	}
+
+	if err := r.RedisClient.Del(ctx, cacheKeys(userID, login, email.String, phoneNumber.String)...).Err(); err != nil {
+		r.Logger.Errorf("failed to invalidate cache after task completion: %v", err)
+	}
+	r.Logger.Infof("user %s completed task assignment %s for %d points", userID, id, points)
+	return r.GetAssignment(ctx, userID, id)
+}
+
+// Awards sums the points and CO2 savings of assignments completed in
+// [from, to) per active user
+func (r *TaskRepositoryDB) Awards(ctx context.Context, from, to time.Time) ([]domain.UserAward, error) {
+	rows, err := r.DB.QueryContext(
+		ctx,
+		`SELECT a.user_id, SUM(a.points), SUM(a.co2_saved)
+		 FROM task_assignments a JOIN users u ON u.id = a.user_id
+		 WHERE a.status = $1 AND u.deleted_at IS NULL
+		   AND ($2::timestamptz IS NULL OR a.completed_at >= $2)
+		   AND ($3::timestamptz IS NULL OR a.completed_at < $3)
+		 GROUP BY a.user_id`,
+		domain.TaskCompleted,
+		sql.NullTime{Time: from, Valid: !from.IsZero()},
+		sql.NullTime{Time: to, Valid: !to.IsZero()},
+	)
+	if err != nil {
+		r.Logger.Errorf("database error while summing task awards: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	awards := []domain.UserAward{}
+	for rows.Next() {
+		var award domain.UserAward
+		if err := rows.Scan(&award.UserID, &award.Points, &award.CO2Saved); err != nil {
+			return nil, err
+		}
+		awards = append(awards, award)
+	}
+	return awards, rows.Err()
+}
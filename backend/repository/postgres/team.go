@@ -0,0 +1,437 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+
+	"github.com/lib/pq"
+)
+
+// teamColumns and challengeColumns are the column lists read by every
+// lookup; keep them in sync with scanTeam and scanChallenge
+const (
+	teamColumns      = "id, name, description, invite_code, created_at"
+	challengeColumns = "id, team_id, title, description, metric, target, starts_at, ends_at, created_by, created_at"
+)
+
+func scanTeam(row rowScanner, team *domain.Team) error {
+	return row.Scan(
+		&team.ID,
+		&team.Name,
+		&team.Description,
+		&team.InviteCode,
+		&team.CreatedAt,
+	)
+}
+
+func scanChallenge(row rowScanner, challenge *domain.Challenge) error {
+	return row.Scan(
+		&challenge.ID,
+		&challenge.TeamID,
+		&challenge.Title,
+		&challenge.Description,
+		&challenge.Metric,
+		&challenge.Target,
+		&challenge.StartsAt,
+		&challenge.EndsAt,
+		&challenge.CreatedBy,
+		&challenge.CreatedAt,
+	)
+}
+
+// TeamRepositoryDB implements domain.TeamService on the teams, team_members,
+// team_invites and team_challenges tables
+type TeamRepositoryDB struct {
+	DB     *sql.DB
+	Logger repository.Logger
+}
+
+// NewTeamRepository creates a new team repository instance
+func NewTeamRepository(db *sql.DB, logger repository.Logger) domain.TeamService {
+	return &TeamRepositoryDB{
+		DB:     db,
+		Logger: logger,
+	}
+}
+
+// CreateTeam stores a team with its owner
+func (r *TeamRepositoryDB) CreateTeam(ctx context.Context, team *domain.Team, owner *domain.TeamMember) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(
+		ctx,
+		"INSERT INTO teams (id, name, description, invite_code) VALUES ($1, $2, $3, $4) RETURNING created_at",
+		team.ID,
+		team.Name,
+		team.Description,
+		team.InviteCode,
+	).Scan(&team.CreatedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation {
+			return domain.ErrTeamConflict
+		}
+		r.Logger.Errorf("failed to create team: %v", err)
+		return err
+	}
+	err = tx.QueryRowContext(
+		ctx,
+		"INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3) RETURNING joined_at",
+		team.ID,
+		owner.UserID,
+		owner.Role,
+	).Scan(&owner.JoinedAt)
+	if err != nil {
+		r.Logger.Errorf("failed to add owner of team %s: %v", team.ID, err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.Logger.Errorf("failed to commit team: %v", err)
+		return err
+	}
+	r.Logger.Infof("user %s created team %s", owner.UserID, team.ID)
+	return nil
+}
+
+// getTeam runs a query selecting teamColumns of a single team
+func (r *TeamRepositoryDB) getTeam(ctx context.Context, query string, arg string) (*domain.Team, error) {
+	var team domain.Team
+	err := scanTeam(r.DB.QueryRowContext(ctx, query, arg), &team)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrTeamNotFound
+	} else if err != nil {
+		r.Logger.Errorf("database error while getting team: %v", err)
+		return nil, err
+	}
+	return &team, nil
+}
+
+// GetTeam returns a team by ID
+func (r *TeamRepositoryDB) GetTeam(ctx context.Context, id string) (*domain.Team, error) {
+	return r.getTeam(ctx, "SELECT "+teamColumns+" FROM teams WHERE id = $1", id)
+}
+
+// TeamByCode returns the team with an invite code
+func (r *TeamRepositoryDB) TeamByCode(ctx context.Context, code string) (*domain.Team, error) {
+	return r.getTeam(ctx, "SELECT "+teamColumns+" FROM teams WHERE invite_code = $1", code)
+}
+
+// UpdateTeam saves a team's name, description and invite code
+func (r *TeamRepositoryDB) UpdateTeam(ctx context.Context, team *domain.Team) error {
+	result, err := r.DB.ExecContext(
+		ctx,
+		"UPDATE teams SET name = $1, description = $2, invite_code = $3 WHERE id = $4",
+		team.Name,
+		team.Description,
+		team.InviteCode,
+		team.ID,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation {
+			return domain.ErrTeamConflict
+		}
+		r.Logger.Errorf("failed to update team %s: %v", team.ID, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrTeamNotFound
+	}
+	return nil
+}
+
+// DeleteTeam deletes a team with its members, invites and challenges
+func (r *TeamRepositoryDB) DeleteTeam(ctx context.Context, id string) error {
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM teams WHERE id = $1", id)
+	if err != nil {
+		r.Logger.Errorf("failed to delete team %s: %v", id, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrTeamNotFound
+	}
+	r.Logger.Infof("deleted team %s", id)
+	return nil
+}
+
+// Teams returns the teams the user is a member of, most recently joined first
+func (r *TeamRepositoryDB) Teams(ctx context.Context, userID string) ([]*domain.Team, error) {
+	rows, err := r.DB.QueryContext(
+		ctx,
+		`SELECT t.id, t.name, t.description, t.invite_code, t.created_at, m.role
+		 FROM teams t JOIN team_members m ON m.team_id = t.id
+		 WHERE m.user_id = $1
+		 ORDER BY m.joined_at DESC`,
+		userID,
+	)
+	if err != nil {
+		r.Logger.Errorf("database error while listing teams: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []*domain.Team{}
+	for rows.Next() {
+		var team domain.Team
+		if err := rows.Scan(&team.ID, &team.Name, &team.Description, &team.InviteCode, &team.CreatedAt, &team.Role); err != nil {
+			return nil, err
+		}
+		teams = append(teams, &team)
+	}
+	return teams, rows.Err()
+}
+
+// AddMember adds a user to a team and withdraws their invite to it
+func (r *TeamRepositoryDB) AddMember(ctx context.Context, member *domain.TeamMember) error {
+	tx, err := r.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(
+		ctx,
+		"INSERT INTO team_members (team_id, user_id, role) VALUES ($1, $2, $3) RETURNING joined_at",
+		member.TeamID,
+		member.UserID,
+		member.Role,
+	).Scan(&member.JoinedAt)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == pgUniqueViolation {
+			return domain.ErrTeamMemberConflict
+		}
+		r.Logger.Errorf("failed to add member to team %s: %v", member.TeamID, err)
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM team_invites WHERE team_id = $1 AND user_id = $2", member.TeamID, member.UserID); err != nil {
+		r.Logger.Errorf("failed to withdraw team invite: %v", err)
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		r.Logger.Errorf("failed to commit team member: %v", err)
+		return err
+	}
+	r.Logger.Infof("user %s joined team %s", member.UserID, member.TeamID)
+	return nil
+}
+
+// GetMember returns a user's membership of a team
+func (r *TeamRepositoryDB) GetMember(ctx context.Context, teamID, userID string) (*domain.TeamMember, error) {
+	member := domain.TeamMember{TeamID: teamID, UserID: userID}
+	err := r.DB.QueryRowContext(
+		ctx,
+		"SELECT role, joined_at FROM team_members WHERE team_id = $1 AND user_id = $2",
+		teamID,
+		userID,
+	).Scan(&member.Role, &member.JoinedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrTeamMemberNotFound
+	} else if err != nil {
+		r.Logger.Errorf("database error while getting team member: %v", err)
+		return nil, err
+	}
+	return &member, nil
+}
+
+// Members returns a team's members in the order they joined
+func (r *TeamRepositoryDB) Members(ctx context.Context, teamID string) ([]*domain.TeamMember, error) {
+	rows, err := r.DB.QueryContext(
+		ctx,
+		"SELECT user_id, role, joined_at FROM team_members WHERE team_id = $1 ORDER BY joined_at",
+		teamID,
+	)
+	if err != nil {
+		r.Logger.Errorf("database error while listing team members: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []*domain.TeamMember{}
+	for rows.Next() {
+		member := domain.TeamMember{TeamID: teamID}
+		if err := rows.Scan(&member.UserID, &member.Role, &member.JoinedAt); err != nil {
+			return nil, err
+		}
+		members = append(members, &member)
+	}
+	return members, rows.Err()
+}
+
+// SetRole changes a member's role
+func (r *TeamRepositoryDB) SetRole(ctx context.Context, teamID, userID, role string) error {
+	result, err := r.DB.ExecContext(
+		ctx,
+		"UPDATE team_members SET role = $1 WHERE team_id = $2 AND user_id = $3",
+		role,
+		teamID,
+		userID,
+	)
+	if err != nil {
+		r.Logger.Errorf("failed to set role in team %s: %v", teamID, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrTeamMemberNotFound
+	}
+	r.Logger.Infof("user %s is now %s of team %s", userID, role, teamID)
+	return nil
+}
+
+// RemoveMember removes a user from a team
+func (r *TeamRepositoryDB) RemoveMember(ctx context.Context, teamID, userID string) error {
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM team_members WHERE team_id = $1 AND user_id = $2", teamID, userID)
+	if err != nil {
+		r.Logger.Errorf("failed to remove member from team %s: %v", teamID, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrTeamMemberNotFound
+	}
+	r.Logger.Infof("user %s left team %s", userID, teamID)
+	return nil
+}
+
+// SaveInvite invites a user to a team; inviting them again is a no-op
+func (r *TeamRepositoryDB) SaveInvite(ctx context.Context, invite *domain.TeamInvite) error {
+	err := r.DB.QueryRowContext(
+		ctx,
+		`INSERT INTO team_invites (team_id, user_id, invited_by) VALUES ($1, $2, $3)
+		 ON CONFLICT (team_id, user_id) DO UPDATE SET team_id = EXCLUDED.team_id
+		 RETURNING invited_by, created_at`,
+		invite.TeamID,
+		invite.UserID,
+		invite.InvitedBy,
+	).Scan(&invite.InvitedBy, &invite.CreatedAt)
+	if err != nil {
+		r.Logger.Errorf("failed to save team invite: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Invites returns the user's pending invites, most recent first
+func (r *TeamRepositoryDB) Invites(ctx context.Context, userID string) ([]*domain.TeamInvite, error) {
+	rows, err := r.DB.QueryContext(
+		ctx,
+		"SELECT team_id, invited_by, created_at FROM team_invites WHERE user_id = $1 ORDER BY created_at DESC",
+		userID,
+	)
+	if err != nil {
+		r.Logger.Errorf("database error while listing team invites: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []*domain.TeamInvite{}
+	for rows.Next() {
+		invite := domain.TeamInvite{UserID: userID}
+		if err := rows.Scan(&invite.TeamID, &invite.InvitedBy, &invite.CreatedAt); err != nil {
+			return nil, err
+		}
+		invites = append(invites, &invite)
+	}
+	return invites, rows.Err()
+}
+
+// DeleteInvite withdraws or declines an invite
+func (r *TeamRepositoryDB) DeleteInvite(ctx context.Context, teamID, userID string) error {
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM team_invites WHERE team_id = $1 AND user_id = $2", teamID, userID)
+	if err != nil {
+		r.Logger.Errorf("failed to delete team invite: %v", err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrTeamInviteNotFound
+	}
+	return nil
+}
+
+// CreateChallenge stores a new challenge
+func (r *TeamRepositoryDB) CreateChallenge(ctx context.Context, challenge *domain.Challenge) error {
+	err := r.DB.QueryRowContext(
+		ctx,
+		`INSERT INTO team_challenges (id, team_id, title, description, metric, target, starts_at, ends_at, created_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		 RETURNING created_at`,
+		challenge.ID,
+		challenge.TeamID,
+		challenge.Title,
+		challenge.Description,
+		challenge.Metric,
+		challenge.Target,
+		challenge.StartsAt,
+		challenge.EndsAt,
+		challenge.CreatedBy,
+	).Scan(&challenge.CreatedAt)
+	if err != nil {
+		r.Logger.Errorf("failed to create challenge: %v", err)
+		return err
+	}
+	r.Logger.Infof("created challenge %s in team %s", challenge.ID, challenge.TeamID)
+	return nil
+}
+
+// GetChallenge returns a challenge of a team
+func (r *TeamRepositoryDB) GetChallenge(ctx context.Context, teamID, id string) (*domain.Challenge, error) {
+	var challenge domain.Challenge
+	err := scanChallenge(r.DB.QueryRowContext(
+		ctx,
+		"SELECT "+challengeColumns+" FROM team_challenges WHERE team_id = $1 AND id = $2",
+		teamID,
+		id,
+	), &challenge)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrChallengeNotFound
+	} else if err != nil {
+		r.Logger.Errorf("database error while getting challenge: %v", err)
+		return nil, err
+	}
+	return &challenge, nil
+}
+
+// Challenges returns a team's challenges, latest start first
+func (r *TeamRepositoryDB) Challenges(ctx context.Context, teamID string) ([]*domain.Challenge, error) {
+	rows, err := r.DB.QueryContext(
+		ctx,
+		"SELECT "+challengeColumns+" FROM team_challenges WHERE team_id = $1 ORDER BY starts_at DESC",
+		teamID,
+	)
+	if err != nil {
+		r.Logger.Errorf("database error while listing challenges: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	challenges := []*domain.Challenge{}
+	for rows.Next() {
+		var challenge domain.Challenge
+		if err := scanChallenge(rows, &challenge); err != nil {
+			return nil, err
+		}
+		challenges = append(challenges, &challenge)
+	}
+	return challenges, rows.Err()
+}
+
+// DeleteChallenge deletes a challenge of a team
+func (r *TeamRepositoryDB) DeleteChallenge(ctx context.Context, teamID, id string) error {
+	result, err := r.DB.ExecContext(ctx, "DELETE FROM team_challenges WHERE team_id = $1 AND id = $2", teamID, id)
+	if err != nil {
+		r.Logger.Errorf("failed to delete challenge %s: %v", id, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrChallengeNotFound
+	}
+	r.Logger.Infof("deleted challenge %s of team %s", id, teamID)
+	return nil
+}
@@ -0,0 +1,495 @@
+package postgres
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "errors"
+    "time"
+
+    "github.com/aygoko/EcoMInd/backend/domain"
+    "github.com/aygoko/EcoMInd/backend/repository"
+    "github.com/go-redis/redis/v8"
+    "github.com/lib/pq"
+)
+
+const (
+    redisUserIDKeyPrefix    = "user:id:"
+    redisUserKeyPrefix      = "user:login:"
+    redisUserEmailKeyPrefix = "user:email:"
+    redisUserPhoneKeyPrefix = "user:phone:"
+
+    pgUniqueViolation = "23505"
+
+    // userColumns is the column list read by every lookup; keep in sync with scanUserRow
+    userColumns = "id, login, email, phone_number, email_verified, CO2, co2_baseline, points, co2_saved"
+)
+
+// Unique indexes on the users table. Login stays reserved after a soft delete
+// so Restore is unambiguous, while email and phone number are only unique
+// among active users.
+var uniqueIndexFields = map[string]string{
+    "users_login_key":               "login",
+    "users_email_active_key":        "email",
+    "users_phone_number_active_key": "phone_number",
+}
+
+// Helper function to scan a database row into a User struct
+func scanUserRow(row rowScanner, user *domain.User) error {
+    // Email and phone number are NULL for users who signed up without them
+    var email, phoneNumber sql.NullString
+    if err := row.Scan(
+        &user.ID,
+        &user.Login,
+        &email,
+        &phoneNumber,
+        &user.EmailVerified,
+        &user.CO2,
+        &user.CO2Baseline,
+        &user.Points,
+        &user.CO2Saved,
+    ); err != nil {
+        return err
+    }
+    user.Email = email.String
+    user.PhoneNumber = phoneNumber.String
+    return nil
+}
+
+// nullable maps an empty string to SQL NULL so optional unique columns don't collide
+func nullable(s string) sql.NullString {
+    return sql.NullString{String: s, Valid: s != ""}
+}
+
+// UserRepositoryDB implements the UserService interface
+type UserRepositoryDB struct {
+    DB          *sql.DB
+    RedisClient *redis.Client
+    CacheTTL    time.Duration
+    Logger      repository.Logger
+}
+
+// NewUserRepository creates a new user repository instance
+func NewUserRepository(db *sql.DB, redisClient *redis.Client, cacheTTL time.Duration, logger repository.Logger) domain.UserService {
+    if logger == nil {
+        panic("logger must not be nil in production") // Fail fast if no logger
+    }
+    return &UserRepositoryDB{
+        DB:          db,
+        RedisClient: redisClient,
+        CacheTTL:    cacheTTL,
+        Logger:      logger,
+    }
+}
+
+// cacheKeys returns every Redis key a user is cached under
+func cacheKeys(id, login, email, phoneNumber string) []string {
+    keys := []string{redisUserIDKeyPrefix + id, redisUserKeyPrefix + login}
+    if email != "" {
+        keys = append(keys, redisUserEmailKeyPrefix+email)
+    }
+    if phoneNumber != "" {
+        keys = append(keys, redisUserPhoneKeyPrefix+phoneNumber)
+    }
+    return keys
+}
+
+// CacheUser stores the user in Redis cache under its login, email and phone number
+func (r *UserRepositoryDB) cacheUser(ctx context.Context, user *domain.User) error {
+    userJSON, err := json.Marshal(user)
+    if err != nil {
+        r.Logger.Errorf("failed to marshal user for caching: %v", err)
+        return err
+    }
+    _, err = r.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+        for _, key := range cacheKeys(user.ID, user.Login, user.Email, user.PhoneNumber) {
+            pipe.Set(ctx, key, userJSON, r.CacheTTL)
+        }
+        return nil
+    })
+    if err != nil {
+        r.Logger.Errorf("failed to cache user in Redis: %v", err)
+        return err
+    }
+    r.Logger.Infof("cached user with login: %s", user.Login)
+    return nil
+}
+
+// InvalidateCache removes every cache entry for the given id, login, email and phone number
+func (r *UserRepositoryDB) invalidateCache(ctx context.Context, id, login, email, phoneNumber string) error {
+    err := r.RedisClient.Del(ctx, cacheKeys(id, login, email, phoneNumber)...).Err()
+    if err != nil {
+        r.Logger.Errorf("failed to invalidate cache for user: %s, error: %v", login, err)
+        return err
+    }
+    r.Logger.Infof("invalidated cache for user with login: %s", login)
+    return nil
+}
+
+// cachedUser looks a user up in Redis; it returns nil, nil on a cache miss
+func (r *UserRepositoryDB) cachedUser(ctx context.Context, key string) (*domain.User, error) {
+    userJSON, err := r.RedisClient.Get(ctx, key).Result()
+    if errors.Is(err, redis.Nil) {
+        return nil, nil
+    } else if err != nil {
+        r.Logger.Errorf("Redis error while fetching user: %v", err)
+        return nil, err
+    }
+    var user domain.User
+    if err := json.Unmarshal([]byte(userJSON), &user); err != nil {
+        r.Logger.Errorf("failed to unmarshal cached user data: %v", err)
+        return nil, err
+    }
+    return &user, nil
+}
+
+// getBy serves a lookup from cache, falling back to the database on a miss
+func (r *UserRepositoryDB) getBy(ctx context.Context, column, cachePrefix, value string) (*domain.User, error) {
+    cached, err := r.cachedUser(ctx, cachePrefix+value)
+    if err != nil {
+        return nil, err
+    }
+    if cached != nil {
+        r.Logger.Infof("retrieved user from cache with %s: %s", column, value)
+        return cached, nil
+    }
+
+    // Query database if not found in cache
+    row := r.DB.QueryRowContext(
+        ctx,
+        "SELECT "+userColumns+" FROM users WHERE "+column+" = $1 AND deleted_at IS NULL",
+        value,
+    )
+    var user domain.User
+    if err := scanUserRow(row, &user); err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            r.Logger.Infof("user not found in database with %s: %s", column, value)
+            return nil, domain.ErrUserNotFound
+        }
+        r.Logger.Errorf("database error while fetching user by %s: %v", column, err)
+        return nil, err
+    }
+
+    // Cache the result in Redis
+    if err := r.cacheUser(ctx, &user); err != nil {
+        r.Logger.Errorf("failed to cache user after database fetch: %v", err)
+    }
+
+    r.Logger.Infof("retrieved user from database with %s: %s", column, value)
+    return &user, nil
+}
+
+// conflictFromError translates a unique violation into a domain.ConflictError
+func conflictFromError(err error, user *domain.User) error {
+    var pqErr *pq.Error
+    if !errors.As(err, &pqErr) || pqErr.Code != pgUniqueViolation {
+        return err
+    }
+    switch uniqueIndexFields[pqErr.Constraint] {
+    case "login":
+        return &domain.ConflictError{Field: "login", Value: user.Login}
+    case "email":
+        return &domain.ConflictError{Field: "email", Value: user.Email}
+    case "phone_number":
+        return &domain.ConflictError{Field: "phone_number", Value: user.PhoneNumber}
+    }
+    return domain.ErrUserConflict
+}
+
+// findConflict reports which of the user's unique attributes is already taken
+func (r *UserRepositoryDB) findConflict(ctx context.Context, user *domain.User, excludeID string) error {
+    var login, email, phoneNumber sql.NullString
+    err := r.DB.QueryRowContext(
+        ctx,
+        `SELECT login, email, phone_number FROM users
+         WHERE id <> $4 AND (login = $1 OR (deleted_at IS NULL AND (email = $2 OR phone_number = $3)))
+         LIMIT 1`,
+        user.Login,
+        nullable(user.Email),
+        nullable(user.PhoneNumber),
+        excludeID,
+    ).Scan(&login, &email, &phoneNumber)
+    switch {
+    case errors.Is(err, sql.ErrNoRows):
+        return nil
+    case err != nil:
+        return err
+    case login.String == user.Login:
+        return &domain.ConflictError{Field: "login", Value: user.Login}
+    case user.Email != "" && email.String == user.Email:
+        return &domain.ConflictError{Field: "email", Value: user.Email}
+    default:
+        return &domain.ConflictError{Field: "phone_number", Value: user.PhoneNumber}
+    }
+}
+
+// Create inserts a new user. It returns a *domain.ConflictError when the
+// login, email or phone number is already taken.
+func (r *UserRepositoryDB) Create(ctx context.Context, user *domain.User) error {
+    if err := r.findConflict(ctx, user, ""); err != nil {
+        r.Logger.Infof("rejected user creation with login %s: %v", user.Login, err)
+        return err
+    }
+
+    _, err := r.DB.ExecContext(
+        ctx,
+        "INSERT INTO users (id, login, email, phone_number, password_hash, CO2) VALUES ($1, $2, $3, $4, $5, $6)",
+        user.ID,
+        user.Login,
+        nullable(user.Email),
+        nullable(user.PhoneNumber),
+        user.Password,
+        user.CO2,
+    )
+    if err != nil {
+        // The pre-check can race with a concurrent insert; the unique indexes have the final say
+        if conflict := conflictFromError(err, user); conflict != err {
+            return conflict
+        }
+        r.Logger.Errorf("failed to create user in database: %v", err)
+        return err
+    }
+
+    r.Logger.Infof("created user with login: %s", user.Login)
+    return nil
+}
+
+// GetByID retrieves a user by ID with cache check
+func (r *UserRepositoryDB) GetByID(ctx context.Context, id string) (*domain.User, error) {
+    return r.getBy(ctx, "id", redisUserIDKeyPrefix, id)
+}
+
+// Get retrieves a user by login with cache check
+func (r *UserRepositoryDB) Get(ctx context.Context, login string) (*domain.User, error) {
+    return r.getBy(ctx, "login", redisUserKeyPrefix, login)
+}
+
+// GetByEmail retrieves a user by email with cache check
+func (r *UserRepositoryDB) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+    return r.getBy(ctx, "email", redisUserEmailKeyPrefix, email)
+}
+
+// GetByPhoneNumber retrieves a user by phone number with cache check
+func (r *UserRepositoryDB) GetByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.User, error) {
+    return r.getBy(ctx, "phone_number", redisUserPhoneKeyPrefix, phoneNumber)
+}
+
+// GetPasswordHash returns the bcrypt hash of an active user's password. It is
+// never cached so credentials don't end up in Redis.
+func (r *UserRepositoryDB) GetPasswordHash(ctx context.Context, login string) (string, error) {
+    var hash string
+    err := r.DB.QueryRowContext(
+        ctx,
+        "SELECT password_hash FROM users WHERE login = $1 AND deleted_at IS NULL",
+        login,
+    ).Scan(&hash)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return "", domain.ErrUserNotFound
+        }
+        r.Logger.Errorf("database error while fetching password hash: %v", err)
+        return "", err
+    }
+    return hash, nil
+}
+
+// ListIDs returns the IDs of every active user
+func (r *UserRepositoryDB) ListIDs(ctx context.Context) ([]string, error) {
+    rows, err := r.DB.QueryContext(ctx, "SELECT id FROM users WHERE deleted_at IS NULL ORDER BY created_at")
+    if err != nil {
+        r.Logger.Errorf("database error while listing users: %v", err)
+        return nil, err
+    }
+    defer rows.Close()
+
+    ids := []string{}
+    for rows.Next() {
+        var id string
+        if err := rows.Scan(&id); err != nil {
+            return nil, err
+        }
+        ids = append(ids, id)
+    }
+    return ids, rows.Err()
+}
+
+// ListWithPhoneNumber returns every active user who has a phone number,
+// oldest first
+func (r *UserRepositoryDB) ListWithPhoneNumber(ctx context.Context) ([]*domain.User, error) {
+    rows, err := r.DB.QueryContext(
+        ctx,
+        "SELECT "+userColumns+" FROM users WHERE deleted_at IS NULL AND phone_number IS NOT NULL ORDER BY created_at",
+    )
+    if err != nil {
+        r.Logger.Errorf("database error while listing users with phone numbers: %v", err)
+        return nil, err
+    }
+    defer rows.Close()
+
+    users := []*domain.User{}
+    for rows.Next() {
+        var user domain.User
+        if err := scanUserRow(rows, &user); err != nil {
+            return nil, err
+        }
+        users = append(users, &user)
+    }
+    return users, rows.Err()
+}
+
+// UpdateUser updates the user's email and phone number and invalidates the
+// cache for both the old and the new values. A changed email is no longer
+// verified. CO2 is left alone: it is the sum of the user's activities and
+// only changes with them.
+func (r *UserRepositoryDB) UpdateUser(ctx context.Context, user *domain.User) error {
+    var id string
+    var oldEmail, oldPhoneNumber sql.NullString
+    err := r.DB.QueryRowContext(
+        ctx,
+        `UPDATE users u SET email = $1, phone_number = $2,
+             email_verified = u.email_verified AND old.email IS NOT DISTINCT FROM $1
+         FROM (SELECT id, email, phone_number FROM users WHERE login = $3 AND deleted_at IS NULL FOR UPDATE) old
+         WHERE u.id = old.id
+         RETURNING old.id, old.email, old.phone_number`,
+        nullable(user.Email),
+        nullable(user.PhoneNumber),
+        user.Login,
+    ).Scan(&id, &oldEmail, &oldPhoneNumber)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return domain.ErrUserNotFound
+        }
+        if conflict := conflictFromError(err, user); conflict != err {
+            return conflict
+        }
+        r.Logger.Errorf("failed to update user in database: %v", err)
+        return err
+    }
+
+    // Invalidate cache
+    if err := r.invalidateCache(ctx, id, user.Login, oldEmail.String, oldPhoneNumber.String); err != nil {
+        r.Logger.Errorf("failed to invalidate cache after user update: %v", err)
+    }
+    if err := r.invalidateCache(ctx, id, user.Login, user.Email, user.PhoneNumber); err != nil {
+        r.Logger.Errorf("failed to invalidate cache after user update: %v", err)
+    }
+
+    r.Logger.Infof("updated user with login: %s", user.Login)
+    return nil
+}
+
+// SetPassword replaces an active user's password hash. Hashes aren't cached,
+// so the cache stays as it is.
+func (r *UserRepositoryDB) SetPassword(ctx context.Context, login, passwordHash string) error {
+    result, err := r.DB.ExecContext(
+        ctx,
+        "UPDATE users SET password_hash = $1 WHERE login = $2 AND deleted_at IS NULL",
+        passwordHash,
+        login,
+    )
+    if err != nil {
+        r.Logger.Errorf("failed to set password in database: %v", err)
+        return err
+    }
+    if rows, err := result.RowsAffected(); err != nil {
+        return err
+    } else if rows == 0 {
+        return domain.ErrUserNotFound
+    }
+
+    r.Logger.Infof("set password of user with login: %s", login)
+    return nil
+}
+
+// SetEmailVerified marks an active user's email verified if it is still
+// email and invalidates the user's cache entries
+func (r *UserRepositoryDB) SetEmailVerified(ctx context.Context, login, email string) error {
+    var id string
+    var phoneNumber sql.NullString
+    err := r.DB.QueryRowContext(
+        ctx,
+        "UPDATE users SET email_verified = TRUE WHERE login = $1 AND email = $2 AND deleted_at IS NULL RETURNING id, phone_number",
+        login,
+        email,
+    ).Scan(&id, &phoneNumber)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            return domain.ErrUserNotFound
+        }
+        r.Logger.Errorf("failed to verify email in database: %v", err)
+        return err
+    }
+
+    if err := r.invalidateCache(ctx, id, login, email, phoneNumber.String); err != nil {
+        r.Logger.Errorf("failed to invalidate cache after email verification: %v", err)
+    }
+
+    r.Logger.Infof("verified email of user with login: %s", login)
+    return nil
+}
+
+// Delete soft-deletes a user and drops every cache entry pointing at it
+func (r *UserRepositoryDB) Delete(ctx context.Context, login string) error {
+    var id string
+    var email, phoneNumber sql.NullString
+    err := r.DB.QueryRowContext(
+        ctx,
+        "UPDATE users SET deleted_at = now() WHERE login = $1 AND deleted_at IS NULL RETURNING id, email, phone_number",
+        login,
+    ).Scan(&id, &email, &phoneNumber)
+    if err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            r.Logger.Infof("user not found for deletion with login: %s", login)
+            return domain.ErrUserNotFound
+        }
+        r.Logger.Errorf("failed to delete user in database: %v", err)
+        return err
+    }
+
+    if err := r.invalidateCache(ctx, id, login, email.String, phoneNumber.String); err != nil {
+        r.Logger.Errorf("failed to invalidate cache after user deletion: %v", err)
+    }
+
+    r.Logger.Infof("deleted user with login: %s", login)
+    return nil
+}
+
+// Restore reactivates a soft-deleted user. It returns a *domain.ConflictError
+// when the email or phone number has since been taken by another user.
+func (r *UserRepositoryDB) Restore(ctx context.Context, login string) error {
+    var user domain.User
+    row := r.DB.QueryRowContext(
+        ctx,
+        "SELECT "+userColumns+" FROM users WHERE login = $1 AND deleted_at IS NOT NULL",
+        login,
+    )
+    if err := scanUserRow(row, &user); err != nil {
+        if errors.Is(err, sql.ErrNoRows) {
+            r.Logger.Infof("no deleted user to restore with login: %s", login)
+            return domain.ErrUserNotFound
+        }
+        r.Logger.Errorf("database error while fetching deleted user: %v", err)
+        return err
+    }
+
+    if err := r.findConflict(ctx, &user, user.ID); err != nil {
+        r.Logger.Infof("rejected restore of user with login %s: %v", login, err)
+        return err
+    }
+
+    _, err := r.DB.ExecContext(ctx, "UPDATE users SET deleted_at = NULL WHERE id = $1", user.ID)
+    if err != nil {
+        if conflict := conflictFromError(err, &user); conflict != err {
+            return conflict
+        }
+        r.Logger.Errorf("failed to restore user in database: %v", err)
+        return err
+    }
+
+    if err := r.invalidateCache(ctx, user.ID, user.Login, user.Email, user.PhoneNumber); err != nil {
+        r.Logger.Errorf("failed to invalidate cache after user restore: %v", err)
+    }
+
+    r.Logger.Infof("restored user with login: %s", login)
+    return nil
+}
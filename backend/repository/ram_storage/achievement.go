@@ -0,0 +1,48 @@
+package ram_storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// AchievementStorage is a concurrency-safe in-memory implementation of
+// domain.AchievementService
+type AchievementStorage struct {
+	mu      sync.Mutex
+	awarded map[string]map[string]time.Time // user ID -> badge ID -> awarded at
+}
+
+// NewAchievementStorage creates an empty in-memory achievement store
+func NewAchievementStorage() domain.AchievementService {
+	return &AchievementStorage{awarded: make(map[string]map[string]time.Time)}
+}
+
+// Award records a badge unless the user already has it
+func (s *AchievementStorage) Award(_ context.Context, userID, badgeID string, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.awarded[userID][badgeID]; ok {
+		return false, nil
+	}
+	if s.awarded[userID] == nil {
+		s.awarded[userID] = make(map[string]time.Time)
+	}
+	s.awarded[userID][badgeID] = at
+	return true, nil
+}
+
+// Awarded returns when the user earned each of their badges
+func (s *AchievementStorage) Awarded(_ context.Context, userID string) (map[string]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	awarded := make(map[string]time.Time, len(s.awarded[userID]))
+	for badgeID, at := range s.awarded[userID] {
+		awarded[badgeID] = at
+	}
+	return awarded, nil
+}
@@ -0,0 +1,115 @@
+package ram_storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// ActivityStorage is a concurrency-safe in-memory implementation of
+// domain.ActivityService. It keeps User.CO2 in the paired UserStorage equal
+// to the sum of the user's activities.
+type ActivityStorage struct {
+	mu         sync.Mutex
+	users      *UserStorage
+	activities map[string]map[string]*domain.Activity // user ID -> activity ID -> activity
+}
+
+// NewActivityStorage creates an empty in-memory activity store. users must
+// come from NewUserStorage since totals are written into it.
+func NewActivityStorage(users domain.UserService) domain.ActivityService {
+	userStorage, ok := users.(*UserStorage)
+	if !ok {
+		panic("in-memory activity storage needs in-memory user storage")
+	}
+	return &ActivityStorage{
+		users:      userStorage,
+		activities: make(map[string]map[string]*domain.Activity),
+	}
+}
+
+// total sums the user's activities, adding delta. The caller must hold the lock.
+func (s *ActivityStorage) total(userID string, delta float64) float64 {
+	total := delta
+	for _, activity := range s.activities[userID] {
+		total += activity.CO2e
+	}
+	return total
+}
+
+// Create stores an activity and adds it to the user's CO2 total
+func (s *ActivityStorage) Create(_ context.Context, activity *domain.Activity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.users.setCO2(activity.UserID, s.total(activity.UserID, activity.CO2e)); err != nil {
+		return err
+	}
+	activity.CreatedAt = time.Now()
+	stored := *activity
+	if s.activities[activity.UserID] == nil {
+		s.activities[activity.UserID] = make(map[string]*domain.Activity)
+	}
+	s.activities[activity.UserID][activity.ID] = &stored
+	return nil
+}
+
+// Get retrieves one of the user's activities
+func (s *ActivityStorage) Get(_ context.Context, userID, id string) (*domain.Activity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	activity, exists := s.activities[userID][id]
+	if !exists {
+		return nil, domain.ErrActivityNotFound
+	}
+	activityCopy := *activity
+	return &activityCopy, nil
+}
+
+// List returns the user's activities, most recent first
+func (s *ActivityStorage) List(_ context.Context, userID string, filter domain.ActivityFilter) ([]*domain.Activity, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	activities := []*domain.Activity{}
+	for _, activity := range s.activities[userID] {
+		if !filter.From.IsZero() && activity.OccurredAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && !activity.OccurredAt.Before(filter.To) {
+			continue
+		}
+		activityCopy := *activity
+		activities = append(activities, &activityCopy)
+	}
+	sort.Slice(activities, func(i, j int) bool {
+		if !activities[i].OccurredAt.Equal(activities[j].OccurredAt) {
+			return activities[i].OccurredAt.After(activities[j].OccurredAt)
+		}
+		return activities[i].CreatedAt.After(activities[j].CreatedAt)
+	})
+	if filter.Limit > 0 && len(activities) > filter.Limit {
+		activities = activities[:filter.Limit]
+	}
+	return activities, nil
+}
+
+// Delete removes one of the user's activities and subtracts it from their total
+func (s *ActivityStorage) Delete(_ context.Context, userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	activity, exists := s.activities[userID][id]
+	if !exists {
+		return domain.ErrActivityNotFound
+	}
+	if err := s.users.setCO2(userID, s.total(userID, -activity.CO2e)); err != nil {
+		return err
+	}
+	delete(s.activities[userID], id)
+	return nil
+}
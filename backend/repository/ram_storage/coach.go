@@ -0,0 +1,46 @@
+package ram_storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// CoachStorage is a concurrency-safe in-memory implementation of
+// domain.CoachService
+type CoachStorage struct {
+	mu        sync.Mutex
+	exchanges map[string][]*domain.CoachExchange // user ID -> exchanges, oldest first
+}
+
+// NewCoachStorage creates an empty in-memory coaching log
+func NewCoachStorage() domain.CoachService {
+	return &CoachStorage{exchanges: make(map[string][]*domain.CoachExchange)}
+}
+
+// Create logs an exchange with the coach
+func (s *CoachStorage) Create(_ context.Context, exchange *domain.CoachExchange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exchange.CreatedAt = time.Now()
+	stored := *exchange
+	s.exchanges[exchange.UserID] = append(s.exchanges[exchange.UserID], &stored)
+	return nil
+}
+
+// List returns the user's exchanges, most recent first
+func (s *CoachStorage) List(_ context.Context, userID string, limit int) ([]*domain.CoachExchange, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.exchanges[userID]
+	exchanges := []*domain.CoachExchange{}
+	for i := len(stored) - 1; i >= 0 && len(exchanges) < limit; i-- {
+		exchangeCopy := *stored[i]
+		exchanges = append(exchanges, &exchangeCopy)
+	}
+	return exchanges, nil
+}
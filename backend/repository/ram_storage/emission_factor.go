@@ -0,0 +1,148 @@
+package ram_storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// EmissionFactorStorage is a concurrency-safe in-memory implementation of
+// domain.EmissionFactorService
+type EmissionFactorStorage struct {
+	mu       sync.RWMutex
+	versions []*domain.EmissionFactorVersion // index = version - 1
+	factors  map[int][]*domain.EmissionFactor
+}
+
+// NewEmissionFactorStorage creates an empty in-memory emission factor catalogue
+func NewEmissionFactorStorage() domain.EmissionFactorService {
+	return &EmissionFactorStorage{
+		factors: make(map[int][]*domain.EmissionFactor),
+	}
+}
+
+// version returns a version by number. The caller must hold the lock.
+func (s *EmissionFactorStorage) version(number int) (*domain.EmissionFactorVersion, error) {
+	if number < 1 || number > len(s.versions) {
+		return nil, domain.ErrFactorVersionNotFound
+	}
+	return s.versions[number-1], nil
+}
+
+// CreateVersion stores a draft version with its factors
+func (s *EmissionFactorStorage) CreateVersion(_ context.Context, version *domain.EmissionFactorVersion, factors []*domain.EmissionFactor) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	version.Version = len(s.versions) + 1
+	version.CreatedAt = time.Now()
+	version.FactorCount = len(factors)
+	stored := make([]*domain.EmissionFactor, 0, len(factors))
+	for _, factor := range factors {
+		factor.Version = version.Version
+		factorCopy := *factor
+		stored = append(stored, &factorCopy)
+	}
+	versionCopy := *version
+	s.versions = append(s.versions, &versionCopy)
+	s.factors[version.Version] = stored
+	return nil
+}
+
+// GetVersion retrieves a version by number
+func (s *EmissionFactorStorage) GetVersion(_ context.Context, number int) (*domain.EmissionFactorVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	version, err := s.version(number)
+	if err != nil {
+		return nil, err
+	}
+	versionCopy := *version
+	return &versionCopy, nil
+}
+
+// ListVersions returns every version, newest first
+func (s *EmissionFactorStorage) ListVersions(_ context.Context) ([]*domain.EmissionFactorVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	versions := make([]*domain.EmissionFactorVersion, 0, len(s.versions))
+	for i := len(s.versions) - 1; i >= 0; i-- {
+		versionCopy := *s.versions[i]
+		versions = append(versions, &versionCopy)
+	}
+	return versions, nil
+}
+
+// Publish makes a draft the current version
+func (s *EmissionFactorStorage) Publish(_ context.Context, number int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	version, err := s.version(number)
+	if err != nil {
+		return err
+	}
+	if version.PublishedAt != nil {
+		return domain.ErrFactorVersionPublished
+	}
+	now := time.Now()
+	version.PublishedAt = &now
+	return nil
+}
+
+// CurrentVersion returns the most recently published version
+func (s *EmissionFactorStorage) CurrentVersion(_ context.Context) (*domain.EmissionFactorVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var current *domain.EmissionFactorVersion
+	for _, version := range s.versions {
+		if version.PublishedAt != nil && (current == nil || !version.PublishedAt.Before(*current.PublishedAt)) {
+			current = version
+		}
+	}
+	if current == nil {
+		return nil, domain.ErrFactorVersionNotFound
+	}
+	versionCopy := *current
+	return &versionCopy, nil
+}
+
+// ListFactors returns the factors of a version ordered by category and region
+func (s *EmissionFactorStorage) ListFactors(_ context.Context, version int) ([]*domain.EmissionFactor, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	factors := []*domain.EmissionFactor{}
+	for _, factor := range s.factors[version] {
+		factorCopy := *factor
+		factors = append(factors, &factorCopy)
+	}
+	sort.SliceStable(factors, func(i, j int) bool {
+		if factors[i].Category != factors[j].Category {
+			return factors[i].Category < factors[j].Category
+		}
+		return factors[i].Region < factors[j].Region
+	})
+	return factors, nil
+}
+
+// FindFactors returns the factors of a category in a version
+func (s *EmissionFactorStorage) FindFactors(_ context.Context, version int, category string) ([]*domain.EmissionFactor, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	factors := []*domain.EmissionFactor{}
+	for _, factor := range s.factors[version] {
+		if factor.Category == category {
+			factorCopy := *factor
+			factors = append(factors, &factorCopy)
+		}
+	}
+	return factors, nil
+}
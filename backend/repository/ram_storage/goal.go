@@ -0,0 +1,109 @@
+package ram_storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// GoalStorage is a concurrency-safe in-memory implementation of
+// domain.GoalService
+type GoalStorage struct {
+	mu    sync.Mutex
+	goals map[string]map[string]*domain.WeeklyGoal // user ID -> ISO week -> goal
+}
+
+// NewGoalStorage creates an empty in-memory weekly goal store
+func NewGoalStorage() domain.GoalService {
+	return &GoalStorage{goals: make(map[string]map[string]*domain.WeeklyGoal)}
+}
+
+// Create stores a goal for a week the user has none for yet
+func (s *GoalStorage) Create(_ context.Context, goal *domain.WeeklyGoal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.goals[goal.UserID][goal.Week]; exists {
+		return domain.ErrGoalConflict
+	}
+	goal.CreatedAt = time.Now()
+	stored := *goal
+	if s.goals[goal.UserID] == nil {
+		s.goals[goal.UserID] = make(map[string]*domain.WeeklyGoal)
+	}
+	s.goals[goal.UserID][goal.Week] = &stored
+	return nil
+}
+
+// Get retrieves the user's goal for an ISO week
+func (s *GoalStorage) Get(_ context.Context, userID, week string) (*domain.WeeklyGoal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	goal, exists := s.goals[userID][week]
+	if !exists {
+		return nil, domain.ErrGoalNotFound
+	}
+	goalCopy := *goal
+	return &goalCopy, nil
+}
+
+// List returns the user's goals, most recent week first
+func (s *GoalStorage) List(_ context.Context, userID string, limit int) ([]*domain.WeeklyGoal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	goals := []*domain.WeeklyGoal{}
+	for _, goal := range s.goals[userID] {
+		goalCopy := *goal
+		goals = append(goals, &goalCopy)
+	}
+	sort.Slice(goals, func(i, j int) bool {
+		return goals[i].StartsAt.After(goals[j].StartsAt)
+	})
+	if limit > 0 && len(goals) > limit {
+		goals = goals[:limit]
+	}
+	return goals, nil
+}
+
+// Update stores a goal's target, status and recorded progress
+func (s *GoalStorage) Update(_ context.Context, goal *domain.WeeklyGoal) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.goals[goal.UserID][goal.Week]
+	if !exists || existing.ID != goal.ID {
+		return domain.ErrGoalNotFound
+	}
+	stored := *goal
+	stored.CreatedAt = existing.CreatedAt
+	s.goals[goal.UserID][goal.Week] = &stored
+	return nil
+}
+
+// ListDue returns active goals whose week ended by now, oldest first
+func (s *GoalStorage) ListDue(_ context.Context, now time.Time, limit int) ([]*domain.WeeklyGoal, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	goals := []*domain.WeeklyGoal{}
+	for _, weeks := range s.goals {
+		for _, goal := range weeks {
+			if goal.Status == domain.GoalActive && !goal.EndsAt.After(now) {
+				goalCopy := *goal
+				goals = append(goals, &goalCopy)
+			}
+		}
+	}
+	sort.Slice(goals, func(i, j int) bool {
+		return goals[i].EndsAt.Before(goals[j].EndsAt)
+	})
+	if limit > 0 && len(goals) > limit {
+		goals = goals[:limit]
+	}
+	return goals, nil
+}
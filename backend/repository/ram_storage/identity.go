@@ -0,0 +1,91 @@
+package ram_storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// IdentityStorage is a concurrency-safe in-memory implementation of
+// domain.IdentityService
+type IdentityStorage struct {
+	mu         sync.RWMutex
+	identities map[string]*domain.Identity // by provider + "\x00" + subject
+}
+
+// NewIdentityStorage creates an empty in-memory identity store
+func NewIdentityStorage() domain.IdentityService {
+	return &IdentityStorage{
+		identities: make(map[string]*domain.Identity),
+	}
+}
+
+func identityKey(provider, subject string) string {
+	return provider + "\x00" + subject
+}
+
+// Create links a provider account to a user
+func (s *IdentityStorage) Create(_ context.Context, identity *domain.Identity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.identities[identityKey(identity.Provider, identity.Subject)]; exists {
+		return domain.ErrIdentityConflict
+	}
+	for _, existing := range s.identities {
+		if existing.UserID == identity.UserID && existing.Provider == identity.Provider {
+			return domain.ErrIdentityConflict
+		}
+	}
+
+	identity.CreatedAt = time.Now()
+	stored := *identity
+	s.identities[identityKey(identity.Provider, identity.Subject)] = &stored
+	return nil
+}
+
+// GetByProviderSubject retrieves the identity of a provider account
+func (s *IdentityStorage) GetByProviderSubject(_ context.Context, provider, subject string) (*domain.Identity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	identity, exists := s.identities[identityKey(provider, subject)]
+	if !exists {
+		return nil, domain.ErrIdentityNotFound
+	}
+	identityCopy := *identity
+	return &identityCopy, nil
+}
+
+// ListByUser returns every identity linked to the user
+func (s *IdentityStorage) ListByUser(_ context.Context, userID string) ([]*domain.Identity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	identities := []*domain.Identity{}
+	for _, identity := range s.identities {
+		if identity.UserID == userID {
+			identityCopy := *identity
+			identities = append(identities, &identityCopy)
+		}
+	}
+	sort.Slice(identities, func(i, j int) bool { return identities[i].Provider < identities[j].Provider })
+	return identities, nil
+}
+
+// Delete unlinks the user's identity at a provider
+func (s *IdentityStorage) Delete(_ context.Context, userID, provider string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, identity := range s.identities {
+		if identity.UserID == userID && identity.Provider == provider {
+			delete(s.identities, key)
+			return nil
+		}
+	}
+	return domain.ErrIdentityNotFound
+}
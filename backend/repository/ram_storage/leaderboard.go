@@ -0,0 +1,121 @@
+package ram_storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// LeaderboardStorage is a concurrency-safe in-memory implementation of
+// domain.LeaderboardService. Weekly boards are kept for the process lifetime.
+type LeaderboardStorage struct {
+	mu     sync.Mutex
+	boards map[string]map[string]*domain.UserAward // week, empty for all time -> user ID -> totals
+}
+
+// NewLeaderboardStorage creates an empty in-memory leaderboard store
+func NewLeaderboardStorage() domain.LeaderboardService {
+	return &LeaderboardStorage{boards: make(map[string]map[string]*domain.UserAward)}
+}
+
+// Add adds an award to the all-time boards and those of week
+func (s *LeaderboardStorage) Add(_ context.Context, week string, award domain.UserAward) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, w := range []string{"", week} {
+		if s.boards[w] == nil {
+			s.boards[w] = make(map[string]*domain.UserAward)
+		}
+		total := s.boards[w][award.UserID]
+		if total == nil {
+			total = &domain.UserAward{UserID: award.UserID}
+			s.boards[w][award.UserID] = total
+		}
+		total.Points += award.Points
+		total.CO2Saved += award.CO2Saved
+	}
+	return nil
+}
+
+// ranked returns the board's entries, best first. The caller must hold the lock.
+func (s *LeaderboardStorage) ranked(board domain.Board) []*domain.LeaderboardEntry {
+	entries := make([]*domain.LeaderboardEntry, 0, len(s.boards[board.Week]))
+	for _, total := range s.boards[board.Week] {
+		entries = append(entries, &domain.LeaderboardEntry{
+			UserID:   total.UserID,
+			Points:   total.Points,
+			CO2Saved: total.CO2Saved,
+		})
+	}
+	score := func(entry *domain.LeaderboardEntry) float64 {
+		if board.Metric == domain.MetricCO2Saved {
+			return entry.CO2Saved
+		}
+		return float64(entry.Points)
+	}
+	// Ties are broken by user ID like Redis sorted sets do
+	sort.Slice(entries, func(i, j int) bool {
+		if score(entries[i]) != score(entries[j]) {
+			return score(entries[i]) > score(entries[j])
+		}
+		return entries[i].UserID > entries[j].UserID
+	})
+	for i, entry := range entries {
+		entry.Rank = int64(i + 1)
+	}
+	return entries
+}
+
+// Top returns a page of the board, best first, and how many users it holds
+func (s *LeaderboardStorage) Top(_ context.Context, board domain.Board, offset, limit int) ([]*domain.LeaderboardEntry, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := s.ranked(board)
+	total := int64(len(entries))
+	if offset >= len(entries) {
+		return []*domain.LeaderboardEntry{}, total, nil
+	}
+	entries = entries[offset:]
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, total, nil
+}
+
+// Standings returns the given users' entries on the board, in order
+func (s *LeaderboardStorage) Standings(_ context.Context, board domain.Board, userIDs []string) ([]*domain.LeaderboardEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	byUser := make(map[string]*domain.LeaderboardEntry)
+	for _, entry := range s.ranked(board) {
+		byUser[entry.UserID] = entry
+	}
+	entries := make([]*domain.LeaderboardEntry, len(userIDs))
+	for i, userID := range userIDs {
+		entries[i] = byUser[userID]
+		if entries[i] == nil {
+			entries[i] = &domain.LeaderboardEntry{UserID: userID}
+		}
+	}
+	return entries, nil
+}
+
+// Replace swaps the boards of week, or the all-time boards when week is
+// empty, for awards
+func (s *LeaderboardStorage) Replace(_ context.Context, week string, awards []domain.UserAward) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	board := make(map[string]*domain.UserAward, len(awards))
+	for i := range awards {
+		award := awards[i]
+		board[award.UserID] = &award
+	}
+	s.boards[week] = board
+	return nil
+}
@@ -0,0 +1,75 @@
+package ram_storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// attemptWindow is the failure count of one key and when it resets
+type attemptWindow struct {
+	count     int
+	expiresAt time.Time
+}
+
+// LoginAttemptStorage is an in-memory implementation of domain.LoginAttemptService
+type LoginAttemptStorage struct {
+	mu       sync.Mutex
+	attempts map[string]*attemptWindow
+	now      func() time.Time
+}
+
+// NewLoginAttemptStorage creates an empty in-memory attempt counter
+func NewLoginAttemptStorage() domain.LoginAttemptService {
+	return &LoginAttemptStorage{
+		attempts: make(map[string]*attemptWindow),
+		now:      time.Now,
+	}
+}
+
+// window returns the live window for key, dropping it if it expired.
+// The caller must hold the lock.
+func (s *LoginAttemptStorage) window(key string) *attemptWindow {
+	w, ok := s.attempts[key]
+	if ok && !s.now().Before(w.expiresAt) {
+		delete(s.attempts, key)
+		return nil
+	}
+	return w
+}
+
+// RegisterFailure records a failed attempt for key
+func (s *LoginAttemptStorage) RegisterFailure(_ context.Context, key string, window time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	w := s.window(key)
+	if w == nil {
+		w = &attemptWindow{expiresAt: s.now().Add(window)}
+		s.attempts[key] = w
+	}
+	w.count++
+	return w.count, nil
+}
+
+// Failures returns the failures recorded for key in the current window
+func (s *LoginAttemptStorage) Failures(_ context.Context, key string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if w := s.window(key); w != nil {
+		return w.count, nil
+	}
+	return 0, nil
+}
+
+// Reset forgets all failures for key
+func (s *LoginAttemptStorage) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
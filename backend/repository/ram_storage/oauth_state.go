@@ -0,0 +1,56 @@
+package ram_storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// OAuthStateStorage is an in-memory implementation of domain.OAuthStateService
+type OAuthStateStorage struct {
+	mu     sync.Mutex
+	states map[string]*domain.OAuthState
+}
+
+// NewOAuthStateStorage creates an empty in-memory OAuth state store
+func NewOAuthStateStorage() domain.OAuthStateService {
+	return &OAuthStateStorage{
+		states: make(map[string]*domain.OAuthState),
+	}
+}
+
+// Save stores a pending authorization request
+func (s *OAuthStateStorage) Save(_ context.Context, state *domain.OAuthState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop abandoned flows so the map doesn't grow without bound
+	now := time.Now()
+	for key, pending := range s.states {
+		if !now.Before(pending.ExpiresAt) {
+			delete(s.states, key)
+		}
+	}
+
+	stored := *state
+	s.states[state.State] = &stored
+	return nil
+}
+
+// Take returns and deletes a pending authorization request
+func (s *OAuthStateStorage) Take(_ context.Context, state string) (*domain.OAuthState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pending, exists := s.states[state]
+	if !exists {
+		return nil, domain.ErrInvalidOAuthState
+	}
+	delete(s.states, state)
+	if !time.Now().Before(pending.ExpiresAt) {
+		return nil, domain.ErrInvalidOAuthState
+	}
+	return pending, nil
+}
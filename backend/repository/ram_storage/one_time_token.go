@@ -0,0 +1,60 @@
+package ram_storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// oneTimeToken is a usable token ID and when it stops being usable
+type oneTimeToken struct {
+	id        string
+	expiresAt time.Time
+}
+
+// OneTimeTokenStorage is an in-memory implementation of
+// domain.OneTimeTokenService
+type OneTimeTokenStorage struct {
+	mu     sync.Mutex
+	tokens map[string]oneTimeToken // by purpose and user ID
+}
+
+// NewOneTimeTokenStorage creates an empty in-memory one-time token store
+func NewOneTimeTokenStorage() domain.OneTimeTokenService {
+	return &OneTimeTokenStorage{
+		tokens: make(map[string]oneTimeToken),
+	}
+}
+
+// Save makes id the user's only usable token for purpose
+func (s *OneTimeTokenStorage) Save(_ context.Context, purpose, userID, id string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Drop unused tokens so the map doesn't grow without bound
+	now := time.Now()
+	for key, token := range s.tokens {
+		if !now.Before(token.expiresAt) {
+			delete(s.tokens, key)
+		}
+	}
+
+	s.tokens[purpose+":"+userID] = oneTimeToken{id: id, expiresAt: now.Add(ttl)}
+	return nil
+}
+
+// Take uses up the user's token for purpose if it is id
+func (s *OneTimeTokenStorage) Take(_ context.Context, purpose, userID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := purpose + ":" + userID
+	token, exists := s.tokens[key]
+	if !exists || token.id != id || !time.Now().Before(token.expiresAt) {
+		return domain.ErrOneTimeTokenInvalid
+	}
+	delete(s.tokens, key)
+	return nil
+}
@@ -0,0 +1,39 @@
+package ram_storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// RateLimitStorage is an in-memory implementation of domain.RateLimitService
+type RateLimitStorage struct {
+	mu      sync.Mutex
+	windows map[string]*attemptWindow
+	now     func() time.Time
+}
+
+// NewRateLimitStorage creates an empty in-memory rate limiter
+func NewRateLimitStorage() domain.RateLimitService {
+	return &RateLimitStorage{
+		windows: make(map[string]*attemptWindow),
+		now:     time.Now,
+	}
+}
+
+// Hit counts an event for key, starting the window on the first event
+func (s *RateLimitStorage) Hit(_ context.Context, key string, window time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	w, ok := s.windows[key]
+	if !ok || !now.Before(w.expiresAt) {
+		w = &attemptWindow{expiresAt: now.Add(window)}
+		s.windows[key] = w
+	}
+	w.count++
+	return w.count, w.expiresAt, nil
+}
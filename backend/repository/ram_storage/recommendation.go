@@ -0,0 +1,44 @@
+package ram_storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// RecommendationStorage is a concurrency-safe in-memory implementation of
+// domain.RecommendationService
+type RecommendationStorage struct {
+	mu        sync.Mutex
+	dismissed map[string]map[string]bool // user ID -> recommendation ID
+}
+
+// NewRecommendationStorage creates an empty in-memory recommendation store
+func NewRecommendationStorage() domain.RecommendationService {
+	return &RecommendationStorage{dismissed: make(map[string]map[string]bool)}
+}
+
+// Dismiss records that the user doesn't want a recommendation again
+func (s *RecommendationStorage) Dismiss(_ context.Context, userID, recommendationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.dismissed[userID] == nil {
+		s.dismissed[userID] = make(map[string]bool)
+	}
+	s.dismissed[userID][recommendationID] = true
+	return nil
+}
+
+// Dismissed lists the recommendations the user dismissed
+func (s *RecommendationStorage) Dismissed(_ context.Context, userID string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := []string{}
+	for id := range s.dismissed[userID] {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
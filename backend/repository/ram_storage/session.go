@@ -0,0 +1,164 @@
+package ram_storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// tokenRecord is a stored refresh token plus whether it was already rotated
+type tokenRecord struct {
+	token domain.RefreshToken
+	used  bool
+}
+
+// SessionStorage is a concurrency-safe in-memory implementation of
+// domain.SessionService
+type SessionStorage struct {
+	mu           sync.Mutex
+	sessions     map[string]*domain.Session
+	tokens       map[string]*tokenRecord        // by token hash
+	userSessions map[string]map[string]struct{} // user ID -> session IDs
+}
+
+// NewSessionStorage creates an empty in-memory session store
+func NewSessionStorage() domain.SessionService {
+	return &SessionStorage{
+		sessions:     make(map[string]*domain.Session),
+		tokens:       make(map[string]*tokenRecord),
+		userSessions: make(map[string]map[string]struct{}),
+	}
+}
+
+// session returns a live session, dropping it if it expired.
+// The caller must hold the lock.
+func (s *SessionStorage) session(id string) *domain.Session {
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil
+	}
+	if !time.Now().Before(session.ExpiresAt) {
+		s.deleteSession(session.UserID, id)
+		return nil
+	}
+	return session
+}
+
+// deleteSession removes a session and its tokens. The caller must hold the lock.
+func (s *SessionStorage) deleteSession(userID, sessionID string) {
+	delete(s.sessions, sessionID)
+	delete(s.userSessions[userID], sessionID)
+	for hash, record := range s.tokens {
+		if record.token.SessionID == sessionID {
+			delete(s.tokens, hash)
+		}
+	}
+}
+
+// SaveSession creates or updates a session and stores its newest refresh token
+func (s *SessionStorage) SaveSession(_ context.Context, session *domain.Session, token *domain.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *session
+	s.sessions[session.ID] = &stored
+	if s.userSessions[session.UserID] == nil {
+		s.userSessions[session.UserID] = make(map[string]struct{})
+	}
+	s.userSessions[session.UserID][session.ID] = struct{}{}
+	s.tokens[token.Hash] = &tokenRecord{token: *token}
+	return nil
+}
+
+// RotateSession updates a live session and stores its newest refresh token,
+// returning domain.ErrSessionNotFound once the session was revoked
+func (s *SessionStorage) RotateSession(_ context.Context, session *domain.Session, token *domain.RefreshToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.userSessions[session.UserID][session.ID]; !ok || s.session(session.ID) == nil {
+		return domain.ErrSessionNotFound
+	}
+	stored := *session
+	s.sessions[session.ID] = &stored
+	s.tokens[token.Hash] = &tokenRecord{token: *token}
+	return nil
+}
+
+// ConsumeRefreshToken marks a refresh token used and returns it with its session
+func (s *SessionStorage) ConsumeRefreshToken(_ context.Context, tokenHash string) (*domain.RefreshToken, *domain.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, ok := s.tokens[tokenHash]
+	if !ok || !time.Now().Before(record.token.ExpiresAt) {
+		return nil, nil, domain.ErrSessionNotFound
+	}
+	token := record.token
+	if record.used {
+		return &token, nil, domain.ErrRefreshTokenReused
+	}
+	session := s.session(token.SessionID)
+	if session == nil {
+		return nil, nil, domain.ErrSessionNotFound
+	}
+	record.used = true
+	sessionCopy := *session
+	return &token, &sessionCopy, nil
+}
+
+// GetSession retrieves a live session by ID
+func (s *SessionStorage) GetSession(_ context.Context, sessionID string) (*domain.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session := s.session(sessionID)
+	if session == nil {
+		return nil, domain.ErrSessionNotFound
+	}
+	sessionCopy := *session
+	return &sessionCopy, nil
+}
+
+// ListSessions returns the user's live sessions, most recently used first
+func (s *SessionStorage) ListSessions(_ context.Context, userID string) ([]*domain.Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sessions := []*domain.Session{}
+	for id := range s.userSessions[userID] {
+		if session := s.session(id); session != nil {
+			sessionCopy := *session
+			sessions = append(sessions, &sessionCopy)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+// DeleteSession revokes one of the user's sessions
+func (s *SessionStorage) DeleteSession(_ context.Context, userID, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.userSessions[userID][sessionID]; !ok {
+		return domain.ErrSessionNotFound
+	}
+	s.deleteSession(userID, sessionID)
+	return nil
+}
+
+// DeleteUserSessions revokes every session of the user
+func (s *SessionStorage) DeleteUserSessions(_ context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range s.userSessions[userID] {
+		s.deleteSession(userID, id)
+	}
+	delete(s.userSessions, userID)
+	return nil
+}
@@ -0,0 +1,182 @@
+package ram_storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// relationKey identifies a directed relation or follow
+type relationKey struct {
+	from, to string
+}
+
+// SocialStorage is a concurrency-safe in-memory implementation of
+// domain.SocialService
+type SocialStorage struct {
+	mu        sync.Mutex
+	relations map[relationKey]*domain.Relation
+	follows   map[relationKey]time.Time
+	privacy   map[string]domain.PrivacySettings
+}
+
+// NewSocialStorage creates an empty in-memory social store
+func NewSocialStorage() domain.SocialService {
+	return &SocialStorage{
+		relations: make(map[relationKey]*domain.Relation),
+		follows:   make(map[relationKey]time.Time),
+		privacy:   make(map[string]domain.PrivacySettings),
+	}
+}
+
+// Between returns the relations between a and b in either direction
+func (s *SocialStorage) Between(_ context.Context, a, b string) ([]*domain.Relation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	relations := []*domain.Relation{}
+	for _, key := range []relationKey{{a, b}, {b, a}} {
+		if relation, ok := s.relations[key]; ok {
+			relationCopy := *relation
+			relations = append(relations, &relationCopy)
+		}
+	}
+	return relations, nil
+}
+
+// SaveRelation creates or replaces the relation from UserID to OtherID
+func (s *SocialStorage) SaveRelation(_ context.Context, relation *domain.Relation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	key := relationKey{relation.UserID, relation.OtherID}
+	relation.CreatedAt = now
+	if existing, ok := s.relations[key]; ok {
+		relation.CreatedAt = existing.CreatedAt
+	}
+	relation.UpdatedAt = now
+	stored := *relation
+	s.relations[key] = &stored
+	return nil
+}
+
+// DeleteRelation removes the relation from userID to otherID
+func (s *SocialStorage) DeleteRelation(_ context.Context, userID, otherID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := relationKey{userID, otherID}
+	if _, ok := s.relations[key]; !ok {
+		return domain.ErrRelationNotFound
+	}
+	delete(s.relations, key)
+	return nil
+}
+
+// Relations returns the user's relations with status in either direction
+func (s *SocialStorage) Relations(_ context.Context, userID, status string) ([]*domain.Relation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	relations := []*domain.Relation{}
+	for key, relation := range s.relations {
+		if (key.from == userID || key.to == userID) && relation.Status == status {
+			relationCopy := *relation
+			relations = append(relations, &relationCopy)
+		}
+	}
+	sort.Slice(relations, func(i, j int) bool {
+		return relations[i].UpdatedAt.After(relations[j].UpdatedAt)
+	})
+	return relations, nil
+}
+
+// Follow records that followerID follows followeeID
+func (s *SocialStorage) Follow(_ context.Context, followerID, followeeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := relationKey{followerID, followeeID}
+	if _, ok := s.follows[key]; !ok {
+		s.follows[key] = time.Now()
+	}
+	return nil
+}
+
+// Unfollow removes a follow
+func (s *SocialStorage) Unfollow(_ context.Context, followerID, followeeID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := relationKey{followerID, followeeID}
+	if _, ok := s.follows[key]; !ok {
+		return domain.ErrFollowNotFound
+	}
+	delete(s.follows, key)
+	return nil
+}
+
+// IsFollowing reports whether followerID follows followeeID
+func (s *SocialStorage) IsFollowing(_ context.Context, followerID, followeeID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.follows[relationKey{followerID, followeeID}]
+	return ok, nil
+}
+
+// contacts lists the follows matching, with the other side's ID
+func (s *SocialStorage) contacts(other func(key relationKey) (string, bool)) []*domain.Contact {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contacts := []*domain.Contact{}
+	for key, since := range s.follows {
+		if userID, ok := other(key); ok {
+			contacts = append(contacts, &domain.Contact{UserID: userID, Since: since})
+		}
+	}
+	sort.Slice(contacts, func(i, j int) bool {
+		return contacts[i].Since.After(contacts[j].Since)
+	})
+	return contacts
+}
+
+// Followers returns who follows the user, most recent first
+func (s *SocialStorage) Followers(_ context.Context, userID string) ([]*domain.Contact, error) {
+	return s.contacts(func(key relationKey) (string, bool) {
+		return key.from, key.to == userID
+	}), nil
+}
+
+// Following returns whom the user follows, most recent first
+func (s *SocialStorage) Following(_ context.Context, userID string) ([]*domain.Contact, error) {
+	return s.contacts(func(key relationKey) (string, bool) {
+		return key.to, key.from == userID
+	}), nil
+}
+
+// Privacy returns the user's privacy settings
+func (s *SocialStorage) Privacy(_ context.Context, userID string) (*domain.PrivacySettings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings, ok := s.privacy[userID]
+	if !ok {
+		settings = domain.DefaultPrivacy
+	}
+	return &settings, nil
+}
+
+// SetPrivacy stores the user's privacy settings
+func (s *SocialStorage) SetPrivacy(_ context.Context, userID string, settings *domain.PrivacySettings) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.privacy[userID] = *settings
+	return nil
+}
@@ -0,0 +1,120 @@
+package ram_storage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// StatsStorage implements domain.StatsService over the activities of an
+// in-memory ActivityStorage
+type StatsStorage struct {
+	activities *ActivityStorage
+}
+
+// NewStatsStorage creates in-memory aggregations. activities must come from
+// NewActivityStorage.
+func NewStatsStorage(activities domain.ActivityService) domain.StatsService {
+	activityStorage, ok := activities.(*ActivityStorage)
+	if !ok {
+		panic("in-memory stats storage needs in-memory activity storage")
+	}
+	return &StatsStorage{activities: activityStorage}
+}
+
+// periodStart returns the start of the day, ISO week or month containing t in loc
+func periodStart(t time.Time, period string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch period {
+	case domain.StatsWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case domain.StatsMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+// inRange lists the user's activities in [from, to)
+func (s *StatsStorage) inRange(userID string, from, to time.Time) []domain.Activity {
+	s.activities.mu.Lock()
+	defer s.activities.mu.Unlock()
+
+	activities := []domain.Activity{}
+	for _, activity := range s.activities.activities[userID] {
+		if !activity.OccurredAt.Before(from) && activity.OccurredAt.Before(to) {
+			activities = append(activities, *activity)
+		}
+	}
+	return activities
+}
+
+// Series sums the user's activities per day, ISO week or month in loc
+func (s *StatsStorage) Series(_ context.Context, userID, period string, loc *time.Location, from, to time.Time) ([]domain.StatsPoint, error) {
+	byStart := make(map[int64]*domain.StatsPoint)
+	for _, activity := range s.inRange(userID, from, to) {
+		start := periodStart(activity.OccurredAt, period, loc)
+		point, ok := byStart[start.Unix()]
+		if !ok {
+			point = &domain.StatsPoint{Start: start}
+			byStart[start.Unix()] = point
+		}
+		point.CO2 += activity.CO2e
+		point.Activities++
+	}
+
+	points := make([]domain.StatsPoint, 0, len(byStart))
+	for _, point := range byStart {
+		points = append(points, *point)
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].Start.Before(points[j].Start)
+	})
+	return points, nil
+}
+
+// Breakdown sums the user's activities by category, largest first
+func (s *StatsStorage) Breakdown(_ context.Context, userID string, from, to time.Time) ([]domain.CategoryTotal, error) {
+	byCategory := make(map[string]*domain.CategoryTotal)
+	for _, activity := range s.inRange(userID, from, to) {
+		total, ok := byCategory[activity.Category]
+		if !ok {
+			total = &domain.CategoryTotal{Category: activity.Category}
+			byCategory[activity.Category] = total
+		}
+		total.CO2 += activity.CO2e
+		total.Activities++
+	}
+
+	totals := make([]domain.CategoryTotal, 0, len(byCategory))
+	for _, total := range byCategory {
+		totals = append(totals, *total)
+	}
+	sort.Slice(totals, func(i, j int) bool {
+		if totals[i].CO2 != totals[j].CO2 {
+			return totals[i].CO2 > totals[j].CO2
+		}
+		return totals[i].Category < totals[j].Category
+	})
+	return totals, nil
+}
+
+// ActiveDays returns the local dates the user logged activities on, most recent first
+func (s *StatsStorage) ActiveDays(_ context.Context, userID string, loc *time.Location) ([]string, error) {
+	s.activities.mu.Lock()
+	seen := make(map[string]bool)
+	for _, activity := range s.activities.activities[userID] {
+		seen[activity.OccurredAt.In(loc).Format("2006-01-02")] = true
+	}
+	s.activities.mu.Unlock()
+
+	days := make([]string, 0, len(seen))
+	for day := range seen {
+		days = append(days, day)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(days)))
+	return days, nil
+}
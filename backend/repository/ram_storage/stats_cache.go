@@ -0,0 +1,66 @@
+package ram_storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// cachedStats is a cache entry and when it expires
+type cachedStats struct {
+	aggregates domain.StatsAggregates
+	expiresAt  time.Time
+}
+
+// StatsCacheStorage is an in-memory implementation of domain.StatsCacheService
+type StatsCacheStorage struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]map[string]*cachedStats // user ID -> query key -> entry
+	now     func() time.Time
+}
+
+// NewStatsCacheStorage creates an empty in-memory stats cache whose entries live for ttl
+func NewStatsCacheStorage(ttl time.Duration) domain.StatsCacheService {
+	return &StatsCacheStorage{
+		ttl:     ttl,
+		entries: make(map[string]map[string]*cachedStats),
+		now:     time.Now,
+	}
+}
+
+// Get returns the cached aggregates of the user's query
+func (s *StatsCacheStorage) Get(_ context.Context, userID, key string) (*domain.StatsAggregates, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[userID][key]
+	if !ok || !s.now().Before(entry.expiresAt) {
+		return nil, domain.ErrStatsNotCached
+	}
+	aggregates := entry.aggregates
+	return &aggregates, nil
+}
+
+// Set caches the aggregates of the user's query
+func (s *StatsCacheStorage) Set(_ context.Context, userID, key string, aggregates *domain.StatsAggregates) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.entries[userID] == nil {
+		s.entries[userID] = make(map[string]*cachedStats)
+	}
+	s.entries[userID][key] = &cachedStats{aggregates: *aggregates, expiresAt: s.now().Add(s.ttl)}
+	return nil
+}
+
+// Invalidate drops every cached entry of the user
+func (s *StatsCacheStorage) Invalidate(_ context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, userID)
+	return nil
+}
@@ -0,0 +1,60 @@
+package ram_storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// SurveyResponseStorage is a concurrency-safe in-memory implementation of
+// domain.SurveyResponseService. It writes baselines into the paired
+// UserStorage.
+type SurveyResponseStorage struct {
+	mu        sync.Mutex
+	users     *UserStorage
+	responses map[string][]*domain.SurveyResponse // user ID -> responses, oldest first
+}
+
+// NewSurveyResponseStorage creates an empty in-memory survey response store.
+// users must come from NewUserStorage since baselines are written into it.
+func NewSurveyResponseStorage(users domain.UserService) domain.SurveyResponseService {
+	userStorage, ok := users.(*UserStorage)
+	if !ok {
+		panic("in-memory survey response storage needs in-memory user storage")
+	}
+	return &SurveyResponseStorage{
+		users:     userStorage,
+		responses: make(map[string][]*domain.SurveyResponse),
+	}
+}
+
+// Create stores a response and makes its result the user's CO2 baseline
+func (s *SurveyResponseStorage) Create(_ context.Context, response *domain.SurveyResponse) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.users.setCO2Baseline(response.UserID, response.Result.AnnualCO2e); err != nil {
+		return err
+	}
+	response.CreatedAt = time.Now()
+	stored := *response
+	s.responses[response.UserID] = append(s.responses[response.UserID], &stored)
+	return nil
+}
+
+// Latest returns the user's most recent response to a survey
+func (s *SurveyResponseStorage) Latest(_ context.Context, userID, surveyID string) (*domain.SurveyResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	responses := s.responses[userID]
+	for i := len(responses) - 1; i >= 0; i-- {
+		if responses[i].SurveyID == surveyID {
+			responseCopy := *responses[i]
+			return &responseCopy, nil
+		}
+	}
+	return nil, domain.ErrSurveyResponseNotFound
+}
@@ -0,0 +1,223 @@
+package ram_storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// TaskStorage is a concurrency-safe in-memory implementation of
+// domain.TaskService. It writes awarded points and CO2 savings into the
+// paired UserStorage.
+type TaskStorage struct {
+	mu          sync.Mutex
+	users       *UserStorage
+	tasks       map[string]*domain.Task
+	assignments map[string]map[string]*domain.TaskAssignment // user ID -> assignment ID -> assignment
+}
+
+// NewTaskStorage creates an empty in-memory task store. users must come
+// from NewUserStorage since rewards are written into it.
+func NewTaskStorage(users domain.UserService) domain.TaskService {
+	userStorage, ok := users.(*UserStorage)
+	if !ok {
+		panic("in-memory task storage needs in-memory user storage")
+	}
+	return &TaskStorage{
+		users:       userStorage,
+		tasks:       make(map[string]*domain.Task),
+		assignments: make(map[string]map[string]*domain.TaskAssignment),
+	}
+}
+
+// CreateTask adds a task to the catalogue
+func (s *TaskStorage) CreateTask(_ context.Context, task *domain.Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task.CreatedAt = time.Now()
+	stored := *task
+	s.tasks[task.ID] = &stored
+	return nil
+}
+
+// UpdateTask replaces a catalogue entry
+func (s *TaskStorage) UpdateTask(_ context.Context, task *domain.Task) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, exists := s.tasks[task.ID]
+	if !exists {
+		return domain.ErrTaskNotFound
+	}
+	task.CreatedAt = existing.CreatedAt
+	stored := *task
+	s.tasks[task.ID] = &stored
+	return nil
+}
+
+// GetTask retrieves a catalogue entry
+func (s *TaskStorage) GetTask(_ context.Context, id string) (*domain.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	task, exists := s.tasks[id]
+	if !exists {
+		return nil, domain.ErrTaskNotFound
+	}
+	taskCopy := *task
+	return &taskCopy, nil
+}
+
+// ListTasks returns catalogue entries ordered by category and title
+func (s *TaskStorage) ListTasks(_ context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tasks := []*domain.Task{}
+	for _, task := range s.tasks {
+		if filter.Category != "" && task.Category != filter.Category {
+			continue
+		}
+		if filter.Difficulty != "" && task.Difficulty != filter.Difficulty {
+			continue
+		}
+		if !filter.IncludeInactive && !task.Active {
+			continue
+		}
+		taskCopy := *task
+		tasks = append(tasks, &taskCopy)
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].Category != tasks[j].Category {
+			return tasks[i].Category < tasks[j].Category
+		}
+		return tasks[i].Title < tasks[j].Title
+	})
+	return tasks, nil
+}
+
+// assignmentCopy returns a copy of assignment showing the current catalogue
+// entry. The caller must hold the lock.
+func (s *TaskStorage) assignmentCopy(assignment *domain.TaskAssignment) *domain.TaskAssignment {
+	assignmentCopy := *assignment
+	assignmentCopy.Task = *s.tasks[assignment.Task.ID]
+	return &assignmentCopy
+}
+
+// Assign gives a task to a user
+func (s *TaskStorage) Assign(_ context.Context, assignment *domain.TaskAssignment) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.tasks[assignment.Task.ID]; !exists {
+		return domain.ErrTaskNotFound
+	}
+	for _, other := range s.assignments[assignment.UserID] {
+		if other.Task.ID == assignment.Task.ID && other.Status == domain.TaskAssigned {
+			return domain.ErrTaskAlreadyAssigned
+		}
+	}
+	assignment.Status = domain.TaskAssigned
+	assignment.AssignedAt = time.Now()
+	stored := *assignment
+	if s.assignments[assignment.UserID] == nil {
+		s.assignments[assignment.UserID] = make(map[string]*domain.TaskAssignment)
+	}
+	s.assignments[assignment.UserID][assignment.ID] = &stored
+	return nil
+}
+
+// GetAssignment retrieves one of the user's assignments
+func (s *TaskStorage) GetAssignment(_ context.Context, userID, id string) (*domain.TaskAssignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assignment, exists := s.assignments[userID][id]
+	if !exists {
+		return nil, domain.ErrTaskAssignmentNotFound
+	}
+	return s.assignmentCopy(assignment), nil
+}
+
+// ListAssignments returns the user's assignments, most recent first
+func (s *TaskStorage) ListAssignments(_ context.Context, userID, status string) ([]*domain.TaskAssignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assignments := []*domain.TaskAssignment{}
+	for _, assignment := range s.assignments[userID] {
+		if status != "" && assignment.Status != status {
+			continue
+		}
+		assignments = append(assignments, s.assignmentCopy(assignment))
+	}
+	sort.Slice(assignments, func(i, j int) bool {
+		return assignments[i].AssignedAt.After(assignments[j].AssignedAt)
+	})
+	return assignments, nil
+}
+
+// Complete marks an open assignment completed in period and awards the
+// task's points and CO2 savings to the user
+func (s *TaskStorage) Complete(_ context.Context, userID, id, proof, period string) (*domain.TaskAssignment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	assignment, exists := s.assignments[userID][id]
+	if !exists {
+		return nil, domain.ErrTaskAssignmentNotFound
+	}
+	if assignment.Status != domain.TaskAssigned {
+		return nil, domain.ErrTaskAlreadyCompleted
+	}
+	for _, other := range s.assignments[userID] {
+		if other.Task.ID == assignment.Task.ID && other.Status == domain.TaskCompleted && other.Period == period {
+			return nil, domain.ErrTaskRepeatTooSoon
+		}
+	}
+	task := s.tasks[assignment.Task.ID]
+	if err := s.users.addTaskReward(userID, task.Points, task.CO2Saved); err != nil {
+		return nil, err
+	}
+	completedAt := time.Now()
+	assignment.Status = domain.TaskCompleted
+	assignment.Proof = proof
+	assignment.Points = task.Points
+	assignment.CO2Saved = task.CO2Saved
+	assignment.Period = period
+	assignment.CompletedAt = &completedAt
+	return s.assignmentCopy(assignment), nil
+}
+
+// Awards sums the points and CO2 savings of assignments completed in
+// [from, to) per active user
+func (s *TaskStorage) Awards(ctx context.Context, from, to time.Time) ([]domain.UserAward, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	awards := []domain.UserAward{}
+	for userID, assignments := range s.assignments {
+		if _, err := s.users.GetByID(ctx, userID); err != nil {
+			continue
+		}
+		award := domain.UserAward{UserID: userID}
+		for _, assignment := range assignments {
+			if assignment.Status != domain.TaskCompleted {
+				continue
+			}
+			if (!from.IsZero() && assignment.CompletedAt.Before(from)) || (!to.IsZero() && !assignment.CompletedAt.Before(to)) {
+				continue
+			}
+			award.Points += assignment.Points
+			award.CO2Saved += assignment.CO2Saved
+		}
+		if award.Points > 0 || award.CO2Saved > 0 {
+			awards = append(awards, award)
+		}
+	}
+	return awards, nil
+}
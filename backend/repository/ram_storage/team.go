@@ -0,0 +1,337 @@
+package ram_storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// memberKey identifies a user's membership of, or invite to, a team
+type memberKey struct {
+	teamID, userID string
+}
+
+// TeamStorage is a concurrency-safe in-memory implementation of
+// domain.TeamService
+type TeamStorage struct {
+	mu         sync.Mutex
+	teams      map[string]*domain.Team
+	members    map[memberKey]*domain.TeamMember
+	invites    map[memberKey]*domain.TeamInvite
+	challenges map[string]*domain.Challenge
+}
+
+// NewTeamStorage creates an empty in-memory team store
+func NewTeamStorage() domain.TeamService {
+	return &TeamStorage{
+		teams:      make(map[string]*domain.Team),
+		members:    make(map[memberKey]*domain.TeamMember),
+		invites:    make(map[memberKey]*domain.TeamInvite),
+		challenges: make(map[string]*domain.Challenge),
+	}
+}
+
+// codeTaken reports whether another team uses code; callers hold s.mu
+func (s *TeamStorage) codeTaken(code, teamID string) bool {
+	for _, team := range s.teams {
+		if team.InviteCode == code && team.ID != teamID {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateTeam stores a team with its owner
+func (s *TeamStorage) CreateTeam(_ context.Context, team *domain.Team, owner *domain.TeamMember) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.codeTaken(team.InviteCode, team.ID) {
+		return domain.ErrTeamConflict
+	}
+	now := time.Now()
+	team.CreatedAt = now
+	owner.JoinedAt = now
+	teamCopy := *team
+	teamCopy.Role, teamCopy.Totals = "", nil
+	ownerCopy := *owner
+	s.teams[team.ID] = &teamCopy
+	s.members[memberKey{team.ID, owner.UserID}] = &ownerCopy
+	return nil
+}
+
+// GetTeam returns a team by ID
+func (s *TeamStorage) GetTeam(_ context.Context, id string) (*domain.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	team, ok := s.teams[id]
+	if !ok {
+		return nil, domain.ErrTeamNotFound
+	}
+	teamCopy := *team
+	return &teamCopy, nil
+}
+
+// TeamByCode returns the team with an invite code
+func (s *TeamStorage) TeamByCode(_ context.Context, code string) (*domain.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, team := range s.teams {
+		if team.InviteCode == code {
+			teamCopy := *team
+			return &teamCopy, nil
+		}
+	}
+	return nil, domain.ErrTeamNotFound
+}
+
+// UpdateTeam saves a team's name, description and invite code
+func (s *TeamStorage) UpdateTeam(_ context.Context, team *domain.Team) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.teams[team.ID]
+	if !ok {
+		return domain.ErrTeamNotFound
+	}
+	if s.codeTaken(team.InviteCode, team.ID) {
+		return domain.ErrTeamConflict
+	}
+	existing.Name = team.Name
+	existing.Description = team.Description
+	existing.InviteCode = team.InviteCode
+	return nil
+}
+
+// DeleteTeam deletes a team with its members, invites and challenges
+func (s *TeamStorage) DeleteTeam(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.teams[id]; !ok {
+		return domain.ErrTeamNotFound
+	}
+	delete(s.teams, id)
+	for key := range s.members {
+		if key.teamID == id {
+			delete(s.members, key)
+		}
+	}
+	for key := range s.invites {
+		if key.teamID == id {
+			delete(s.invites, key)
+		}
+	}
+	for challengeID, challenge := range s.challenges {
+		if challenge.TeamID == id {
+			delete(s.challenges, challengeID)
+		}
+	}
+	return nil
+}
+
+// Teams returns the teams the user is a member of, most recently joined first
+func (s *TeamStorage) Teams(_ context.Context, userID string) ([]*domain.Team, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var memberships []*domain.TeamMember
+	for key, member := range s.members {
+		if key.userID == userID {
+			memberships = append(memberships, member)
+		}
+	}
+	sort.Slice(memberships, func(i, j int) bool {
+		return memberships[i].JoinedAt.After(memberships[j].JoinedAt)
+	})
+	teams := make([]*domain.Team, 0, len(memberships))
+	for _, member := range memberships {
+		teamCopy := *s.teams[member.TeamID]
+		teamCopy.Role = member.Role
+		teams = append(teams, &teamCopy)
+	}
+	return teams, nil
+}
+
+// AddMember adds a user to a team and withdraws their invite to it
+func (s *TeamStorage) AddMember(_ context.Context, member *domain.TeamMember) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memberKey{member.TeamID, member.UserID}
+	if _, ok := s.teams[member.TeamID]; !ok {
+		return domain.ErrTeamNotFound
+	}
+	if _, ok := s.members[key]; ok {
+		return domain.ErrTeamMemberConflict
+	}
+	member.JoinedAt = time.Now()
+	memberCopy := *member
+	s.members[key] = &memberCopy
+	delete(s.invites, key)
+	return nil
+}
+
+// GetMember returns a user's membership of a team
+func (s *TeamStorage) GetMember(_ context.Context, teamID, userID string) (*domain.TeamMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.members[memberKey{teamID, userID}]
+	if !ok {
+		return nil, domain.ErrTeamMemberNotFound
+	}
+	memberCopy := *member
+	return &memberCopy, nil
+}
+
+// Members returns a team's members in the order they joined
+func (s *TeamStorage) Members(_ context.Context, teamID string) ([]*domain.TeamMember, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	members := []*domain.TeamMember{}
+	for key, member := range s.members {
+		if key.teamID == teamID {
+			memberCopy := *member
+			members = append(members, &memberCopy)
+		}
+	}
+	sort.Slice(members, func(i, j int) bool {
+		return members[i].JoinedAt.Before(members[j].JoinedAt)
+	})
+	return members, nil
+}
+
+// SetRole changes a member's role
+func (s *TeamStorage) SetRole(_ context.Context, teamID, userID, role string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	member, ok := s.members[memberKey{teamID, userID}]
+	if !ok {
+		return domain.ErrTeamMemberNotFound
+	}
+	member.Role = role
+	return nil
+}
+
+// RemoveMember removes a user from a team
+func (s *TeamStorage) RemoveMember(_ context.Context, teamID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memberKey{teamID, userID}
+	if _, ok := s.members[key]; !ok {
+		return domain.ErrTeamMemberNotFound
+	}
+	delete(s.members, key)
+	return nil
+}
+
+// SaveInvite invites a user to a team; inviting them again is a no-op
+func (s *TeamStorage) SaveInvite(_ context.Context, invite *domain.TeamInvite) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memberKey{invite.TeamID, invite.UserID}
+	if existing, ok := s.invites[key]; ok {
+		invite.InvitedBy, invite.CreatedAt = existing.InvitedBy, existing.CreatedAt
+		return nil
+	}
+	invite.CreatedAt = time.Now()
+	inviteCopy := *invite
+	s.invites[key] = &inviteCopy
+	return nil
+}
+
+// Invites returns the user's pending invites, most recent first
+func (s *TeamStorage) Invites(_ context.Context, userID string) ([]*domain.TeamInvite, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	invites := []*domain.TeamInvite{}
+	for key, invite := range s.invites {
+		if key.userID == userID {
+			inviteCopy := *invite
+			invites = append(invites, &inviteCopy)
+		}
+	}
+	sort.Slice(invites, func(i, j int) bool {
+		return invites[i].CreatedAt.After(invites[j].CreatedAt)
+	})
+	return invites, nil
+}
+
+// DeleteInvite withdraws or declines an invite
+func (s *TeamStorage) DeleteInvite(_ context.Context, teamID, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := memberKey{teamID, userID}
+	if _, ok := s.invites[key]; !ok {
+		return domain.ErrTeamInviteNotFound
+	}
+	delete(s.invites, key)
+	return nil
+}
+
+// CreateChallenge stores a new challenge
+func (s *TeamStorage) CreateChallenge(_ context.Context, challenge *domain.Challenge) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	challenge.CreatedAt = time.Now()
+	challengeCopy := *challenge
+	s.challenges[challenge.ID] = &challengeCopy
+	return nil
+}
+
+// GetChallenge returns a challenge of a team
+func (s *TeamStorage) GetChallenge(_ context.Context, teamID, id string) (*domain.Challenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	challenge, ok := s.challenges[id]
+	if !ok || challenge.TeamID != teamID {
+		return nil, domain.ErrChallengeNotFound
+	}
+	challengeCopy := *challenge
+	return &challengeCopy, nil
+}
+
+// Challenges returns a team's challenges, latest start first
+func (s *TeamStorage) Challenges(_ context.Context, teamID string) ([]*domain.Challenge, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	challenges := []*domain.Challenge{}
+	for _, challenge := range s.challenges {
+		if challenge.TeamID == teamID {
+			challengeCopy := *challenge
+			challenges = append(challenges, &challengeCopy)
+		}
+	}
+	sort.Slice(challenges, func(i, j int) bool {
+		return challenges[i].StartsAt.After(challenges[j].StartsAt)
+	})
+	return challenges, nil
+}
+
+// DeleteChallenge deletes a challenge of a team
+func (s *TeamStorage) DeleteChallenge(_ context.Context, teamID, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	challenge, ok := s.challenges[id]
+	if !ok || challenge.TeamID != teamID {
+		return domain.ErrChallengeNotFound
+	}
+	delete(s.challenges, id)
+	return nil
+}
@@ -1,218 +1,287 @@
-package repository
+package ram_storage
 
 import (
-    "context"
-    "encoding/json"
-    "errors"
-    "log"
-    "time"
-
-    "github.com/aygoko/EcoMInd/backend/domain"
-    "github.com/go-redis/redis/v8"
-    "database/sql"
-)
+	"context"
+	"sync"
 
-const (
-    redisUserKeyPrefix = "user:login:"
-    cacheTTL           = 5 * time.Minute
+	"github.com/aygoko/EcoMInd/backend/domain"
 )
 
-// Logger interface for structured logging
-type Logger interface {
-    Errorf(format string, args ...interface{})
-    Infof(format string, args ...interface{})
-}
-
-// Default logger (replace with a structured logging library like zap in production)
-var logger Logger = &defaultLogger{}
-
-type defaultLogger struct{}
-
-func (l *defaultLogger) Errorf(format string, args ...interface{}) {
-    log.Printf("[ERROR] "+format, args...)
-}
-
-func (l *defaultLogger) Infof(format string, args ...interface{}) {
-    log.Printf("[INFO] "+format, args...)
-}
-
-// Helper function to scan a database row into a User struct
-func scanUserRow(row *sql.Row, user *domain.User) error {
-    // Ensure all fields are included (ID, Login, Email, PhoneNumber, CO2)
-    return row.Scan(
-        &user.ID,
-        &user.Login,
-        &user.Email,
-        &user.PhoneNumber,
-        &user.CO2, // Added CO2
-    )
-}
-
-// UserRepositoryDB implements the UserService interface
-type UserRepositoryDB struct {
-    DB          *sql.DB
-    RedisClient *redis.Client
-    Logger      Logger
-}
-
-// NewUserRepository creates a new user repository instance
-func NewUserRepository(db *sql.DB, redisClient *redis.Client, logger Logger) domain.UserService {
-    if logger == nil {
-        panic("logger must not be nil in production") // Fail fast if no logger
-    }
-    return &UserRepositoryDB{
-        DB:          db,
-        RedisClient: redisClient,
-        Logger:      logger,
-    }
-}
-
-// CacheUser stores the user in Redis cache
-func (r *UserRepositoryDB) cacheUser(ctx context.Context, user *domain.User) error {
-    key := redisUserKeyPrefix + user.Login
-    userJSON, err := json.Marshal(user)
-    if err != nil {
-        r.Logger.Errorf("failed to marshal user for caching: %v", err)
-        return err
-    }
-    err = r.RedisClient.Set(ctx, key, userJSON, cacheTTL).Err()
-    if err != nil {
-        r.Logger.Errorf("failed to cache user in Redis: %v", err)
-        return err
-    }
-    r.Logger.Infof("cached user with login: %s", user.Login)
-    return nil
-}
-
-// InvalidateCache removes a user from the Redis cache
-func (r *UserRepositoryDB) invalidateCache(ctx context.Context, login string) error {
-    key := redisUserKeyPrefix + login
-    err := r.RedisClient.Del(ctx, key).Err()
-    if err != nil {
-        r.Logger.Errorf("failed to invalidate cache for user: %s, error: %v", login, err)
-        return err
-    }
-    r.Logger.Infof("invalidated cache for user with login: %s", login)
-    return nil
-}
-
-// Get retrieves a user by login with cache check
-func (r *UserRepositoryDB) Get(ctx context.Context, login string) (*domain.User, error) {
-    key := redisUserKeyPrefix + login
-
-    // Check Redis cache first
-    userJSON, err := r.RedisClient.Get(ctx, key).Result()
-    if err == nil {
-        var user domain.User
-        if err := json.Unmarshal([]byte(userJSON), &user); err != nil {
-            r.Logger.Errorf("failed to unmarshal cached user data: %v", err)
-            return nil, err
-        }
-        r.Logger.Infof("retrieved user from cache with login: %s", login)
-        return &user, nil
-    } else if !errors.Is(err, redis.Nil) {
-        r.Logger.Errorf("Redis error while fetching user: %v", err)
-        return nil, err
-    }
-
-    // Query database if not found in cache
-    row := r.DB.QueryRowContext(
-        ctx,
-        "SELECT id, login, email, phone_number, CO2 FROM users WHERE login = $1", // Include ID and CO2
-        login,
-    )
-    var user domain.User
-    if err := scanUserRow(row, &user); err != nil {
-        if errors.Is(err, sql.ErrNoRows) {
-            r.Logger.Infof("user not found in database with login: %s", login)
-            return nil, domain.ErrUserNotFound
-        }
-        r.Logger.Errorf("database error while fetching user: %v", err)
-        return nil, err
-    }
-
-    // Cache the result in Redis
-    if err := r.cacheUser(ctx, &user); err != nil {
-        r.Logger.Errorf("failed to cache user after database fetch: %v", err)
-    }
-
-    r.Logger.Infof("retrieved user from database with login: %s", login)
-    return &user, nil
-}
-
-// GetByEmail retrieves a user by email
-func (r *UserRepositoryDB) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-    row := r.DB.QueryRowContext(
-        ctx,
-        "SELECT id, login, email, phone_number, CO2 FROM users WHERE email = $1", // Include ID and CO2
-        email,
-    )
-    var user domain.User
-    if err := scanUserRow(row, &user); err != nil {
-        if errors.Is(err, sql.ErrNoRows) {
-            r.Logger.Infof("user not found in database with email: %s", email)
-            return nil, domain.ErrUserNotFound
-        }
-        r.Logger.Errorf("database error while fetching user by email: %v", err)
-        return nil, err
-    }
-
-    // Cache the user in Redis
-    if err := r.cacheUser(ctx, &user); err != nil {
-        r.Logger.Errorf("failed to cache user after database fetch: %v", err)
-    }
-
-    r.Logger.Infof("retrieved user from database with email: %s", email)
-    return &user, nil
-}
-
-// GetByPhoneNumber retrieves a user by phone number
-func (r *UserRepositoryDB) GetByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.User, error) {
-    row := r.DB.QueryRowContext(
-        ctx,
-        "SELECT id, login, email, phone_number, CO2 FROM users WHERE phone_number = $1", // Include ID and CO2
-        phoneNumber,
-    )
-    var user domain.User
-    if err := scanUserRow(row, &user); err != nil {
-        if errors.Is(err, sql.ErrNoRows) {
-            r.Logger.Infof("user not found in database with phone number: %s", phoneNumber)
-            return nil, domain.ErrUserNotFound
-        }
-        r.Logger.Errorf("database error while fetching user by phone number: %v", err)
-        return nil, err
-    }
-
-    // Cache the user in Redis
-    if err := r.cacheUser(ctx, &user); err != nil {
-        r.Logger.Errorf("failed to cache user after database fetch: %v", err)
-    }
-
-    r.Logger.Infof("retrieved user from database with phone number: %s", phoneNumber)
-    return &user, nil
-}
-
-// UpdateUser updates user data and invalidates the cache
-func (r *UserRepositoryDB) UpdateUser(ctx context.Context, user *domain.User) error {
-    // Include all fields to update (e.g., CO2)
-    _, err := r.DB.ExecContext(
-        ctx,
-        "UPDATE users SET email = $1, phone_number = $2, CO2 = $3 WHERE login = $4", // Include CO2
-        user.Email,
-        user.PhoneNumber,
-        user.CO2, // Added CO2
-        user.Login,
-    )
-    if err != nil {
-        r.Logger.Errorf("failed to update user in database: %v", err)
-        return err
-    }
-
-    // Invalidate cache
-    if err := r.invalidateCache(ctx, user.Login); err != nil {
-        r.Logger.Errorf("failed to invalidate cache after user update: %v", err)
-    }
-
-    r.Logger.Infof("updated user with login: %s", user.Login)
-    return nil
-}
\ No newline at end of file
+// userRecord is a stored user plus its soft-delete flag
+type userRecord struct {
+	user    domain.User
+	deleted bool
+}
+
+// UserStorage is a concurrency-safe in-memory implementation of
+// domain.UserService. It mirrors the Postgres uniqueness rules: a login stays
+// reserved after a soft delete, email and phone number are only unique among
+// active users.
+type UserStorage struct {
+	mu           sync.RWMutex
+	users        map[string]*userRecord // by login
+	ids          map[string]string      // id -> login
+	emails       map[string]string      // email -> login, active users only
+	phoneNumbers map[string]string      // phone number -> login, active users only
+}
+
+// NewUserStorage creates an empty in-memory user store
+func NewUserStorage() domain.UserService {
+	return &UserStorage{
+		users:        make(map[string]*userRecord),
+		ids:          make(map[string]string),
+		emails:       make(map[string]string),
+		phoneNumbers: make(map[string]string),
+	}
+}
+
+// conflict reports which unique attribute of user is taken by another active user.
+// The caller must hold the lock.
+func (s *UserStorage) conflict(user *domain.User) error {
+	if login, ok := s.emails[user.Email]; ok && user.Email != "" && login != user.Login {
+		return &domain.ConflictError{Field: "email", Value: user.Email}
+	}
+	if login, ok := s.phoneNumbers[user.PhoneNumber]; ok && user.PhoneNumber != "" && login != user.Login {
+		return &domain.ConflictError{Field: "phone_number", Value: user.PhoneNumber}
+	}
+	return nil
+}
+
+// index adds the user's email and phone number to the lookup maps.
+// The caller must hold the lock.
+func (s *UserStorage) index(user *domain.User) {
+	if user.Email != "" {
+		s.emails[user.Email] = user.Login
+	}
+	if user.PhoneNumber != "" {
+		s.phoneNumbers[user.PhoneNumber] = user.Login
+	}
+}
+
+// unindex removes the user's email and phone number from the lookup maps.
+// The caller must hold the lock.
+func (s *UserStorage) unindex(user *domain.User) {
+	delete(s.emails, user.Email)
+	delete(s.phoneNumbers, user.PhoneNumber)
+}
+
+// Create stores a new user. It returns a *domain.ConflictError when the
+// login, email or phone number is already taken.
+func (s *UserStorage) Create(_ context.Context, user *domain.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.users[user.Login]; exists {
+		return &domain.ConflictError{Field: "login", Value: user.Login}
+	}
+	if err := s.conflict(user); err != nil {
+		return err
+	}
+
+	s.users[user.Login] = &userRecord{user: *user}
+	s.ids[user.ID] = user.Login
+	s.index(user)
+	return nil
+}
+
+// GetByID retrieves an active user by ID
+func (s *UserStorage) GetByID(_ context.Context, id string) (*domain.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	login, exists := s.ids[id]
+	if !exists {
+		return nil, domain.ErrUserNotFound
+	}
+	return s.get(login)
+}
+
+// ListIDs returns the IDs of every active user
+func (s *UserStorage) ListIDs(_ context.Context) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ids := []string{}
+	for _, record := range s.users {
+		if !record.deleted {
+			ids = append(ids, record.user.ID)
+		}
+	}
+	return ids, nil
+}
+
+// Get retrieves an active user by login
+func (s *UserStorage) Get(_ context.Context, login string) (*domain.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.get(login)
+}
+
+// GetByEmail retrieves an active user by email
+func (s *UserStorage) GetByEmail(_ context.Context, email string) (*domain.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	login, exists := s.emails[email]
+	if !exists {
+		return nil, domain.ErrUserNotFound
+	}
+	return s.get(login)
+}
+
+// GetByPhoneNumber retrieves an active user by phone number
+func (s *UserStorage) GetByPhoneNumber(_ context.Context, phoneNumber string) (*domain.User, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	login, exists := s.phoneNumbers[phoneNumber]
+	if !exists {
+		return nil, domain.ErrUserNotFound
+	}
+	return s.get(login)
+}
+
+// get returns a copy of an active user. The caller must hold the lock.
+func (s *UserStorage) get(login string) (*domain.User, error) {
+	record, exists := s.users[login]
+	if !exists || record.deleted {
+		return nil, domain.ErrUserNotFound
+	}
+	user := record.user
+	return &user, nil
+}
+
+// GetPasswordHash returns the bcrypt hash of an active user's password
+func (s *UserStorage) GetPasswordHash(_ context.Context, login string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, err := s.get(login)
+	if err != nil {
+		return "", err
+	}
+	return user.Password, nil
+}
+
+// UpdateUser replaces the email and phone number of an active user. A
+// changed email is no longer verified. CO2 is only changed through activities.
+func (s *UserStorage) UpdateUser(_ context.Context, user *domain.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.users[user.Login]
+	if !exists || record.deleted {
+		return domain.ErrUserNotFound
+	}
+	if err := s.conflict(user); err != nil {
+		return err
+	}
+
+	s.unindex(&record.user)
+	if record.user.Email != user.Email {
+		record.user.EmailVerified = false
+	}
+	record.user.Email = user.Email
+	record.user.PhoneNumber = user.PhoneNumber
+	s.index(&record.user)
+	return nil
+}
+
+// SetPassword replaces an active user's password hash
+func (s *UserStorage) SetPassword(_ context.Context, login, passwordHash string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.users[login]
+	if !exists || record.deleted {
+		return domain.ErrUserNotFound
+	}
+	record.user.Password = passwordHash
+	return nil
+}
+
+// SetEmailVerified marks an active user's email verified if it is still email
+func (s *UserStorage) SetEmailVerified(_ context.Context, login, email string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.users[login]
+	if !exists || record.deleted || record.user.Email != email {
+		return domain.ErrUserNotFound
+	}
+	record.user.EmailVerified = true
+	return nil
+}
+
+// Delete soft-deletes a user, releasing its email and phone number
+func (s *UserStorage) Delete(_ context.Context, login string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.users[login]
+	if !exists || record.deleted {
+		return domain.ErrUserNotFound
+	}
+	record.deleted = true
+	s.unindex(&record.user)
+	return nil
+}
+
+// Restore reactivates a soft-deleted user. It returns a *domain.ConflictError
+// when the email or phone number has since been taken by another user.
+func (s *UserStorage) Restore(_ context.Context, login string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.users[login]
+	if !exists || !record.deleted {
+		return domain.ErrUserNotFound
+	}
+	if err := s.conflict(&record.user); err != nil {
+		return err
+	}
+	record.deleted = false
+	s.index(&record.user)
+	return nil
+}
+
+// setCO2 replaces the CO2 total of an active user; ActivityStorage keeps it
+// equal to the sum of the user's activities
+func (s *UserStorage) setCO2(id string, total float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.users[s.ids[id]]
+	if !exists || record.deleted {
+		return domain.ErrUserNotFound
+	}
+	record.user.CO2 = total
+	return nil
+}
+
+// setCO2Baseline replaces the survey-estimated footprint of an active user
+func (s *UserStorage) setCO2Baseline(id string, baseline float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.users[s.ids[id]]
+	if !exists || record.deleted {
+		return domain.ErrUserNotFound
+	}
+	record.user.CO2Baseline = baseline
+	return nil
+}
+
+// addTaskReward adds a completed task's points and CO2 savings to an active user
+func (s *UserStorage) addTaskReward(id string, points int, co2Saved float64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record, exists := s.users[s.ids[id]]
+	if !exists || record.deleted {
+		return domain.ErrUserNotFound
+	}
+	record.user.Points += points
+	record.user.CO2Saved += co2Saved
+	return nil
+}
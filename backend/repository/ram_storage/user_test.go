@@ -0,0 +1,257 @@
+package ram_storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// newTestUser returns a user with every unique attribute set
+func newTestUser(login, email, phoneNumber string) *domain.User {
+	return &domain.User{ID: "id-" + login, Login: login, Email: email, PhoneNumber: phoneNumber}
+}
+
+// conflictField returns the field of a *domain.ConflictError, "" for nil and
+// "other" for any other error
+func conflictField(err error) string {
+	var conflict *domain.ConflictError
+	switch {
+	case err == nil:
+		return ""
+	case errors.As(err, &conflict):
+		return conflict.Field
+	default:
+		return "other"
+	}
+}
+
+func TestUserStorageCreateConflicts(t *testing.T) {
+	tests := []struct {
+		name      string
+		user      *domain.User
+		wantField string
+	}{
+		{"unique", newTestUser("bob", "bob@example.com", "+79001234568"), ""},
+		{"login taken", newTestUser("alice", "other@example.com", "+79001234568"), "login"},
+		{"login of deleted user", newTestUser("carol", "other@example.com", "+79001234568"), "login"},
+		{"email taken", newTestUser("bob", "alice@example.com", "+79001234568"), "email"},
+		{"phone number taken", newTestUser("bob", "bob@example.com", "+79001234567"), "phone_number"},
+		{"email of deleted user", newTestUser("bob", "carol@example.com", ""), ""},
+		{"phone number of deleted user", newTestUser("bob", "", "+79007654321"), ""},
+		{"no email or phone number", newTestUser("bob", "", ""), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := NewUserStorage()
+			for _, user := range []*domain.User{
+				newTestUser("alice", "alice@example.com", "+79001234567"),
+				newTestUser("carol", "carol@example.com", "+79007654321"),
+				newTestUser("dave", "", ""),
+			} {
+				if err := s.Create(ctx, user); err != nil {
+					t.Fatalf("Create(%s): %v", user.Login, err)
+				}
+			}
+			if err := s.Delete(ctx, "carol"); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+
+			err := s.Create(ctx, tt.user)
+			if got := conflictField(err); got != tt.wantField {
+				t.Fatalf("Create error = %v, want conflict on %q", err, tt.wantField)
+			}
+			if err != nil {
+				return
+			}
+			got, err := s.Get(ctx, tt.user.Login)
+			if err != nil {
+				t.Fatalf("Get: %v", err)
+			}
+			if *got != *tt.user {
+				t.Errorf("Get = %+v, want %+v", got, tt.user)
+			}
+		})
+	}
+}
+
+func TestUserStorageUpdateConflicts(t *testing.T) {
+	tests := []struct {
+		name        string
+		email       string
+		phoneNumber string
+		wantField   string
+	}{
+		{"unchanged", "bob@example.com", "+79001234568", ""},
+		{"new values", "robert@example.com", "+79001234569", ""},
+		{"email taken", "alice@example.com", "+79001234568", "email"},
+		{"phone number taken", "bob@example.com", "+79001234567", "phone_number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := NewUserStorage()
+			for _, user := range []*domain.User{
+				newTestUser("alice", "alice@example.com", "+79001234567"),
+				newTestUser("bob", "bob@example.com", "+79001234568"),
+			} {
+				if err := s.Create(ctx, user); err != nil {
+					t.Fatalf("Create(%s): %v", user.Login, err)
+				}
+			}
+
+			err := s.UpdateUser(ctx, newTestUser("bob", tt.email, tt.phoneNumber))
+			if got := conflictField(err); got != tt.wantField {
+				t.Fatalf("UpdateUser error = %v, want conflict on %q", err, tt.wantField)
+			}
+			// A rejected update leaves both users findable as before
+			wantEmail, wantPhoneNumber := tt.email, tt.phoneNumber
+			if err != nil {
+				wantEmail, wantPhoneNumber = "bob@example.com", "+79001234568"
+			}
+			if user, err := s.GetByEmail(ctx, wantEmail); err != nil || user.Login != "bob" {
+				t.Errorf("GetByEmail(%s) = %v, %v, want bob", wantEmail, user, err)
+			}
+			if user, err := s.GetByPhoneNumber(ctx, wantPhoneNumber); err != nil || user.Login != "bob" {
+				t.Errorf("GetByPhoneNumber(%s) = %v, %v, want bob", wantPhoneNumber, user, err)
+			}
+			if user, err := s.GetByEmail(ctx, "alice@example.com"); err != nil || user.Login != "alice" {
+				t.Errorf("GetByEmail(alice@example.com) = %v, %v, want alice", user, err)
+			}
+		})
+	}
+}
+
+func TestUserStorageDeleteAndRestore(t *testing.T) {
+	tests := []struct {
+		name      string
+		taken     *domain.User // created while alice is deleted, if set
+		wantField string
+	}{
+		{"nothing taken", nil, ""},
+		{"other email and phone number", newTestUser("bob", "bob@example.com", "+79001234568"), ""},
+		{"email taken", newTestUser("bob", "alice@example.com", ""), "email"},
+		{"phone number taken", newTestUser("bob", "", "+79001234567"), "phone_number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := NewUserStorage()
+			alice := newTestUser("alice", "alice@example.com", "+79001234567")
+			if err := s.Create(ctx, alice); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if err := s.Delete(ctx, "alice"); err != nil {
+				t.Fatalf("Delete: %v", err)
+			}
+
+			// A deleted user is found by nothing and can't be deleted again
+			if _, err := s.Get(ctx, "alice"); !errors.Is(err, domain.ErrUserNotFound) {
+				t.Errorf("Get after Delete error = %v, want ErrUserNotFound", err)
+			}
+			if _, err := s.GetByID(ctx, alice.ID); !errors.Is(err, domain.ErrUserNotFound) {
+				t.Errorf("GetByID after Delete error = %v, want ErrUserNotFound", err)
+			}
+			if _, err := s.GetByEmail(ctx, alice.Email); !errors.Is(err, domain.ErrUserNotFound) {
+				t.Errorf("GetByEmail after Delete error = %v, want ErrUserNotFound", err)
+			}
+			if _, err := s.GetByPhoneNumber(ctx, alice.PhoneNumber); !errors.Is(err, domain.ErrUserNotFound) {
+				t.Errorf("GetByPhoneNumber after Delete error = %v, want ErrUserNotFound", err)
+			}
+			if err := s.Delete(ctx, "alice"); !errors.Is(err, domain.ErrUserNotFound) {
+				t.Errorf("second Delete error = %v, want ErrUserNotFound", err)
+			}
+
+			if tt.taken != nil {
+				if err := s.Create(ctx, tt.taken); err != nil {
+					t.Fatalf("Create(%s): %v", tt.taken.Login, err)
+				}
+			}
+			err := s.Restore(ctx, "alice")
+			if got := conflictField(err); got != tt.wantField {
+				t.Fatalf("Restore error = %v, want conflict on %q", err, tt.wantField)
+			}
+			if err != nil {
+				if _, err := s.Get(ctx, "alice"); !errors.Is(err, domain.ErrUserNotFound) {
+					t.Errorf("Get after failed Restore error = %v, want ErrUserNotFound", err)
+				}
+				return
+			}
+
+			for lookup, get := range map[string]func() (*domain.User, error){
+				"Get":              func() (*domain.User, error) { return s.Get(ctx, "alice") },
+				"GetByID":          func() (*domain.User, error) { return s.GetByID(ctx, alice.ID) },
+				"GetByEmail":       func() (*domain.User, error) { return s.GetByEmail(ctx, alice.Email) },
+				"GetByPhoneNumber": func() (*domain.User, error) { return s.GetByPhoneNumber(ctx, alice.PhoneNumber) },
+			} {
+				if user, err := get(); err != nil || user.Login != "alice" {
+					t.Errorf("%s after Restore = %v, %v, want alice", lookup, user, err)
+				}
+			}
+			if err := s.Restore(ctx, "alice"); !errors.Is(err, domain.ErrUserNotFound) {
+				t.Errorf("Restore of an active user error = %v, want ErrUserNotFound", err)
+			}
+		})
+	}
+}
+
+func TestUserStorageConcurrentCreate(t *testing.T) {
+	const workers = 50
+	tests := []struct {
+		name      string
+		user      func(i int) *domain.User
+		wantField string
+	}{
+		{"same login", func(i int) *domain.User {
+			return newTestUser("alice", fmt.Sprintf("alice%d@example.com", i), fmt.Sprintf("+7900%07d", i))
+		}, "login"},
+		{"same email", func(i int) *domain.User {
+			return newTestUser(fmt.Sprintf("user%d", i), "alice@example.com", fmt.Sprintf("+7900%07d", i))
+		}, "email"},
+		{"same phone number", func(i int) *domain.User {
+			return newTestUser(fmt.Sprintf("user%d", i), fmt.Sprintf("user%d@example.com", i), "+79001234567")
+		}, "phone_number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := NewUserStorage()
+
+			errs := make([]error, workers)
+			var wg sync.WaitGroup
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					errs[i] = s.Create(ctx, tt.user(i))
+				}(i)
+			}
+			wg.Wait()
+
+			created := 0
+			for i, err := range errs {
+				switch conflictField(err) {
+				case "":
+					created++
+				case tt.wantField:
+				default:
+					t.Errorf("Create %d error = %v, want conflict on %q", i, err, tt.wantField)
+				}
+			}
+			if created != 1 {
+				t.Errorf("%d of %d concurrent creates succeeded, want 1", created, workers)
+			}
+			ids, err := s.ListIDs(ctx)
+			if err != nil {
+				t.Fatalf("ListIDs: %v", err)
+			}
+			if len(ids) != 1 {
+				t.Errorf("ListIDs returned %d users, want 1", len(ids))
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package redis_storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	redisLeaderboardKeyPrefix = "leaderboard:"
+
+	// weeklyBoardTTL keeps a few past weeks around after they end
+	weeklyBoardTTL = 8 * 7 * 24 * time.Hour
+)
+
+// LeaderboardRepository keeps leaderboards in Redis sorted sets scored by
+// the metric
+//
+//	leaderboard:<metric>:all          all-time board
+//	leaderboard:<metric>:week:<week>  board of one ISO week, expires after weeklyBoardTTL
+type LeaderboardRepository struct {
+	RedisClient *redis.Client
+	Logger      repository.Logger
+}
+
+// NewLeaderboardRepository creates a Redis-backed leaderboard store
+func NewLeaderboardRepository(redisClient *redis.Client, logger repository.Logger) domain.LeaderboardService {
+	return &LeaderboardRepository{
+		RedisClient: redisClient,
+		Logger:      logger,
+	}
+}
+
+func leaderboardKey(board domain.Board) string {
+	if board.Week == "" {
+		return redisLeaderboardKeyPrefix + board.Metric + ":all"
+	}
+	return redisLeaderboardKeyPrefix + board.Metric + ":week:" + board.Week
+}
+
+// Add adds an award to the all-time boards and those of week
+func (r *LeaderboardRepository) Add(ctx context.Context, week string, award domain.UserAward) error {
+	_, err := r.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, w := range []string{"", week} {
+			pointsKey := leaderboardKey(domain.Board{Metric: domain.MetricPoints, Week: w})
+			co2Key := leaderboardKey(domain.Board{Metric: domain.MetricCO2Saved, Week: w})
+			pipe.ZIncrBy(ctx, pointsKey, float64(award.Points), award.UserID)
+			pipe.ZIncrBy(ctx, co2Key, award.CO2Saved, award.UserID)
+			if w != "" {
+				pipe.Expire(ctx, pointsKey, weeklyBoardTTL)
+				pipe.Expire(ctx, co2Key, weeklyBoardTTL)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.Logger.Errorf("failed to add award of user %s to leaderboards: %v", award.UserID, err)
+	}
+	return err
+}
+
+// Top returns a page of the board, best first, and how many users it holds
+func (r *LeaderboardRepository) Top(ctx context.Context, board domain.Board, offset, limit int) ([]*domain.LeaderboardEntry, int64, error) {
+	key := leaderboardKey(board)
+	pipe := r.RedisClient.Pipeline()
+	rangeCmd := pipe.ZRevRangeWithScores(ctx, key, int64(offset), int64(offset+limit-1))
+	countCmd := pipe.ZCard(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		r.Logger.Errorf("Redis error while reading leaderboard %s: %v", key, err)
+		return nil, 0, err
+	}
+
+	members := rangeCmd.Val()
+	userIDs := make([]string, len(members))
+	for i, member := range members {
+		userIDs[i] = member.Member.(string)
+	}
+	entries, err := r.Standings(ctx, board, userIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+	// Ties share a score but not a rank, so number the page in order
+	for i, entry := range entries {
+		entry.Rank = int64(offset + i + 1)
+	}
+	return entries, countCmd.Val(), nil
+}
+
+// Standings returns the given users' entries on the board, in order
+func (r *LeaderboardRepository) Standings(ctx context.Context, board domain.Board, userIDs []string) ([]*domain.LeaderboardEntry, error) {
+	key := leaderboardKey(board)
+	pointsKey := leaderboardKey(domain.Board{Metric: domain.MetricPoints, Week: board.Week})
+	co2Key := leaderboardKey(domain.Board{Metric: domain.MetricCO2Saved, Week: board.Week})
+
+	pipe := r.RedisClient.Pipeline()
+	ranks := make([]*redis.IntCmd, len(userIDs))
+	points := make([]*redis.FloatCmd, len(userIDs))
+	co2Saved := make([]*redis.FloatCmd, len(userIDs))
+	for i, userID := range userIDs {
+		ranks[i] = pipe.ZRevRank(ctx, key, userID)
+		points[i] = pipe.ZScore(ctx, pointsKey, userID)
+		co2Saved[i] = pipe.ZScore(ctx, co2Key, userID)
+	}
+	// Users missing from the board answer redis.Nil
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		r.Logger.Errorf("Redis error while reading leaderboard %s: %v", key, err)
+		return nil, err
+	}
+
+	entries := make([]*domain.LeaderboardEntry, len(userIDs))
+	for i, userID := range userIDs {
+		entries[i] = &domain.LeaderboardEntry{
+			UserID:   userID,
+			Points:   int(points[i].Val()),
+			CO2Saved: co2Saved[i].Val(),
+		}
+		if rank, err := ranks[i].Result(); err == nil {
+			entries[i].Rank = rank + 1
+		}
+	}
+	return entries, nil
+}
+
+// Replace swaps the boards of week, or the all-time boards when week is
+// empty, for awards. The new boards are built under temporary keys and
+// renamed into place so readers never see a partial board.
+func (r *LeaderboardRepository) Replace(ctx context.Context, week string, awards []domain.UserAward) error {
+	for _, metric := range []string{domain.MetricPoints, domain.MetricCO2Saved} {
+		key := leaderboardKey(domain.Board{Metric: metric, Week: week})
+		members := make([]*redis.Z, 0, len(awards))
+		for _, award := range awards {
+			score := float64(award.Points)
+			if metric == domain.MetricCO2Saved {
+				score = award.CO2Saved
+			}
+			members = append(members, &redis.Z{Score: score, Member: award.UserID})
+		}
+
+		_, err := r.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if len(members) == 0 {
+				pipe.Del(ctx, key)
+				return nil
+			}
+			tmpKey := key + ":rebuild"
+			pipe.Del(ctx, tmpKey)
+			pipe.ZAdd(ctx, tmpKey, members...)
+			if week != "" {
+				pipe.Expire(ctx, tmpKey, weeklyBoardTTL)
+			}
+			pipe.Rename(ctx, tmpKey, key)
+			return nil
+		})
+		if err != nil {
+			r.Logger.Errorf("failed to replace leaderboard %s: %v", key, err)
+			return err
+		}
+	}
+	return nil
+}
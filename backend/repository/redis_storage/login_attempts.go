@@ -0,0 +1,74 @@
+package redis_storage
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+	"github.com/go-redis/redis/v8"
+)
+
+const redisLoginAttemptKeyPrefix = "login:failures:"
+
+// incrWithExpiry increments a counter and starts its expiry on the first
+// increment only, so the window doesn't slide with every failure
+var incrWithExpiry = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+    redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n
+`)
+
+// LoginAttemptRepository keeps failed sign-in counters in Redis so every
+// backend instance sees the same lockout state
+type LoginAttemptRepository struct {
+	RedisClient *redis.Client
+	Logger      repository.Logger
+}
+
+// NewLoginAttemptRepository creates a Redis-backed attempt counter
+func NewLoginAttemptRepository(redisClient *redis.Client, logger repository.Logger) domain.LoginAttemptService {
+	return &LoginAttemptRepository{
+		RedisClient: redisClient,
+		Logger:      logger,
+	}
+}
+
+// RegisterFailure increments the counter for key, starting the window on the first failure
+func (r *LoginAttemptRepository) RegisterFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	count, err := incrWithExpiry.Run(
+		ctx,
+		r.RedisClient,
+		[]string{redisLoginAttemptKeyPrefix + key},
+		window.Milliseconds(),
+	).Int()
+	if err != nil {
+		r.Logger.Errorf("failed to record login failure: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// Failures returns the failures recorded for key in the current window
+func (r *LoginAttemptRepository) Failures(ctx context.Context, key string) (int, error) {
+	count, err := r.RedisClient.Get(ctx, redisLoginAttemptKeyPrefix+key).Int()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	} else if err != nil {
+		r.Logger.Errorf("failed to read login failures: %v", err)
+		return 0, err
+	}
+	return count, nil
+}
+
+// Reset forgets all failures for key
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	if err := r.RedisClient.Del(ctx, redisLoginAttemptKeyPrefix+key).Err(); err != nil {
+		r.Logger.Errorf("failed to reset login failures: %v", err)
+		return err
+	}
+	return nil
+}
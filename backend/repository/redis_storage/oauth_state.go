@@ -0,0 +1,59 @@
+package redis_storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+	"github.com/go-redis/redis/v8"
+)
+
+const redisOAuthStateKeyPrefix = "oauth:state:"
+
+// OAuthStateRepository keeps pending OAuth authorization requests in Redis
+// until they expire or are used
+type OAuthStateRepository struct {
+	RedisClient *redis.Client
+	Logger      repository.Logger
+}
+
+// NewOAuthStateRepository creates a Redis-backed OAuth state store
+func NewOAuthStateRepository(redisClient *redis.Client, logger repository.Logger) domain.OAuthStateService {
+	return &OAuthStateRepository{
+		RedisClient: redisClient,
+		Logger:      logger,
+	}
+}
+
+// Save stores a pending authorization request until it expires
+func (r *OAuthStateRepository) Save(ctx context.Context, state *domain.OAuthState) error {
+	stateJSON, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	err = r.RedisClient.Set(ctx, redisOAuthStateKeyPrefix+state.State, stateJSON, time.Until(state.ExpiresAt)).Err()
+	if err != nil {
+		r.Logger.Errorf("failed to save OAuth state: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Take returns and deletes a pending authorization request
+func (r *OAuthStateRepository) Take(ctx context.Context, state string) (*domain.OAuthState, error) {
+	stateJSON, err := r.RedisClient.GetDel(ctx, redisOAuthStateKeyPrefix+state).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, domain.ErrInvalidOAuthState
+	} else if err != nil {
+		r.Logger.Errorf("Redis error while fetching OAuth state: %v", err)
+		return nil, err
+	}
+	var pending domain.OAuthState
+	if err := json.Unmarshal(stateJSON, &pending); err != nil {
+		return nil, err
+	}
+	return &pending, nil
+}
@@ -0,0 +1,59 @@
+package redis_storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+	"github.com/go-redis/redis/v8"
+)
+
+const redisOneTimeTokenKeyPrefix = "onetime:"
+
+// deleteIfEquals deletes KEYS[1] only if it holds ARGV[1], so checking and
+// using up a token is one step
+var deleteIfEquals = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+    return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// OneTimeTokenRepository keeps the IDs of usable one-time tokens in Redis,
+// expiring with the tokens
+type OneTimeTokenRepository struct {
+	RedisClient *redis.Client
+	Logger      repository.Logger
+}
+
+// NewOneTimeTokenRepository creates a Redis-backed one-time token store
+func NewOneTimeTokenRepository(redisClient *redis.Client, logger repository.Logger) domain.OneTimeTokenService {
+	return &OneTimeTokenRepository{
+		RedisClient: redisClient,
+		Logger:      logger,
+	}
+}
+
+// Save makes id the user's only usable token for purpose until ttl passes
+func (r *OneTimeTokenRepository) Save(ctx context.Context, purpose, userID, id string, ttl time.Duration) error {
+	err := r.RedisClient.Set(ctx, redisOneTimeTokenKeyPrefix+purpose+":"+userID, id, ttl).Err()
+	if err != nil {
+		r.Logger.Errorf("failed to save one-time token: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Take uses up the user's token for purpose if it is id
+func (r *OneTimeTokenRepository) Take(ctx context.Context, purpose, userID, id string) error {
+	deleted, err := deleteIfEquals.Run(ctx, r.RedisClient, []string{redisOneTimeTokenKeyPrefix + purpose + ":" + userID}, id).Int()
+	if err != nil {
+		r.Logger.Errorf("Redis error while taking one-time token: %v", err)
+		return err
+	}
+	if deleted == 0 {
+		return domain.ErrOneTimeTokenInvalid
+	}
+	return nil
+}
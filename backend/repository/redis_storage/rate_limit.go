@@ -0,0 +1,56 @@
+package redis_storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+	"github.com/go-redis/redis/v8"
+)
+
+const redisRateLimitKeyPrefix = "ratelimit:"
+
+// incrWithTTL is incrWithExpiry that also returns the remaining window in
+// milliseconds, so callers can tell clients when to retry
+var incrWithTTL = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if n == 1 then
+    redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return {n, redis.call("PTTL", KEYS[1])}
+`)
+
+// RateLimitRepository keeps rate limit counters in Redis so the limit holds
+// across backend instances
+type RateLimitRepository struct {
+	RedisClient *redis.Client
+	Logger      repository.Logger
+}
+
+// NewRateLimitRepository creates a Redis-backed rate limiter
+func NewRateLimitRepository(redisClient *redis.Client, logger repository.Logger) domain.RateLimitService {
+	return &RateLimitRepository{
+		RedisClient: redisClient,
+		Logger:      logger,
+	}
+}
+
+// Hit counts an event for key, starting the window on the first event
+func (r *RateLimitRepository) Hit(ctx context.Context, key string, window time.Duration) (int, time.Time, error) {
+	result, err := incrWithTTL.Run(
+		ctx,
+		r.RedisClient,
+		[]string{redisRateLimitKeyPrefix + key},
+		window.Milliseconds(),
+	).Int64Slice()
+	if err != nil {
+		r.Logger.Errorf("failed to count rate limited event: %v", err)
+		return 0, time.Time{}, err
+	}
+	ttl := time.Duration(result[1]) * time.Millisecond
+	if ttl < 0 {
+		ttl = window
+	}
+	return int(result[0]), time.Now().Add(ttl), nil
+}
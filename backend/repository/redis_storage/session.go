@@ -0,0 +1,226 @@
+package redis_storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	redisSessionKeyPrefix      = "session:"
+	redisUserSessionsKeyPrefix = "session:user:"
+	redisRefreshTokenKeyPrefix = "refresh:token:"
+	redisRefreshUsedKeyPrefix  = "refresh:used:"
+)
+
+// rotateSession updates session KEYS[1] and stores refresh token KEYS[3] only
+// while the session exists and is still in the user's set KEYS[2]
+var rotateSession = redis.NewScript(`
+if redis.call("EXISTS", KEYS[1]) == 0 or redis.call("SISMEMBER", KEYS[2], ARGV[1]) == 0 then
+    return 0
+end
+redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+redis.call("SET", KEYS[3], ARGV[4], "PX", ARGV[5])
+return 1
+`)
+
+// SessionRepository keeps sessions and refresh tokens in Redis. Keys expire
+// with the session, so nothing has to be swept.
+//
+//	session:<id>           session JSON
+//	session:user:<userID>  set of the user's session IDs
+//	refresh:token:<hash>   refresh token JSON
+//	refresh:used:<hash>    present once the token was rotated
+type SessionRepository struct {
+	RedisClient *redis.Client
+	Logger      repository.Logger
+}
+
+// NewSessionRepository creates a Redis-backed session store
+func NewSessionRepository(redisClient *redis.Client, logger repository.Logger) domain.SessionService {
+	return &SessionRepository{
+		RedisClient: redisClient,
+		Logger:      logger,
+	}
+}
+
+// SaveSession creates or updates a session and stores its newest refresh token
+func (r *SessionRepository) SaveSession(ctx context.Context, session *domain.Session, token *domain.RefreshToken) error {
+	sessionJSON, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	tokenJSON, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	sessionTTL := time.Until(session.ExpiresAt)
+	_, err = r.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, redisSessionKeyPrefix+session.ID, sessionJSON, sessionTTL)
+		pipe.SAdd(ctx, redisUserSessionsKeyPrefix+session.UserID, session.ID)
+		pipe.Set(ctx, redisRefreshTokenKeyPrefix+token.Hash, tokenJSON, time.Until(token.ExpiresAt))
+		return nil
+	})
+	if err != nil {
+		r.Logger.Errorf("failed to save session %s: %v", session.ID, err)
+		return err
+	}
+	return nil
+}
+
+// RotateSession updates a live session and stores its newest refresh token,
+// returning domain.ErrSessionNotFound once the session was revoked
+func (r *SessionRepository) RotateSession(ctx context.Context, session *domain.Session, token *domain.RefreshToken) error {
+	sessionJSON, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	tokenJSON, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+
+	keys := []string{
+		redisSessionKeyPrefix + session.ID,
+		redisUserSessionsKeyPrefix + session.UserID,
+		redisRefreshTokenKeyPrefix + token.Hash,
+	}
+	rotated, err := rotateSession.Run(ctx, r.RedisClient, keys,
+		session.ID,
+		sessionJSON, time.Until(session.ExpiresAt).Milliseconds(),
+		tokenJSON, time.Until(token.ExpiresAt).Milliseconds(),
+	).Int()
+	if err != nil {
+		r.Logger.Errorf("failed to rotate session %s: %v", session.ID, err)
+		return err
+	}
+	if rotated == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+// ConsumeRefreshToken marks a refresh token used and returns it with its session
+func (r *SessionRepository) ConsumeRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, *domain.Session, error) {
+	tokenJSON, err := r.RedisClient.Get(ctx, redisRefreshTokenKeyPrefix+tokenHash).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil, domain.ErrSessionNotFound
+	} else if err != nil {
+		r.Logger.Errorf("Redis error while fetching refresh token: %v", err)
+		return nil, nil, err
+	}
+	var token domain.RefreshToken
+	if err := json.Unmarshal(tokenJSON, &token); err != nil {
+		return nil, nil, err
+	}
+
+	// SETNX makes the first consumer win; anyone after it is replaying the token
+	first, err := r.RedisClient.SetNX(ctx, redisRefreshUsedKeyPrefix+tokenHash, 1, time.Until(token.ExpiresAt)).Result()
+	if err != nil {
+		r.Logger.Errorf("failed to mark refresh token used: %v", err)
+		return nil, nil, err
+	}
+	if !first {
+		return &token, nil, domain.ErrRefreshTokenReused
+	}
+
+	session, err := r.GetSession(ctx, token.SessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &token, session, nil
+}
+
+// GetSession retrieves a live session by ID
+func (r *SessionRepository) GetSession(ctx context.Context, sessionID string) (*domain.Session, error) {
+	sessionJSON, err := r.RedisClient.Get(ctx, redisSessionKeyPrefix+sessionID).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, domain.ErrSessionNotFound
+	} else if err != nil {
+		r.Logger.Errorf("Redis error while fetching session: %v", err)
+		return nil, err
+	}
+	var session domain.Session
+	if err := json.Unmarshal(sessionJSON, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListSessions returns the user's live sessions, most recently used first
+func (r *SessionRepository) ListSessions(ctx context.Context, userID string) ([]*domain.Session, error) {
+	ids, err := r.RedisClient.SMembers(ctx, redisUserSessionsKeyPrefix+userID).Result()
+	if err != nil {
+		r.Logger.Errorf("Redis error while listing sessions: %v", err)
+		return nil, err
+	}
+
+	sessions := []*domain.Session{}
+	var expired []interface{}
+	for _, id := range ids {
+		session, err := r.GetSession(ctx, id)
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			expired = append(expired, id)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	// Drop IDs of sessions that expired on their own
+	if len(expired) > 0 {
+		if err := r.RedisClient.SRem(ctx, redisUserSessionsKeyPrefix+userID, expired...).Err(); err != nil {
+			r.Logger.Errorf("failed to prune expired sessions: %v", err)
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt) })
+	return sessions, nil
+}
+
+// DeleteSession revokes one of the user's sessions. Its refresh tokens stay
+// until they expire but are rejected because the session is gone.
+func (r *SessionRepository) DeleteSession(ctx context.Context, userID, sessionID string) error {
+	removed, err := r.RedisClient.SRem(ctx, redisUserSessionsKeyPrefix+userID, sessionID).Result()
+	if err != nil {
+		r.Logger.Errorf("failed to revoke session %s: %v", sessionID, err)
+		return err
+	}
+	if removed == 0 {
+		return domain.ErrSessionNotFound
+	}
+	if err := r.RedisClient.Del(ctx, redisSessionKeyPrefix+sessionID).Err(); err != nil {
+		r.Logger.Errorf("failed to revoke session %s: %v", sessionID, err)
+		return err
+	}
+	r.Logger.Infof("revoked session %s", sessionID)
+	return nil
+}
+
+// DeleteUserSessions revokes every session of the user
+func (r *SessionRepository) DeleteUserSessions(ctx context.Context, userID string) error {
+	ids, err := r.RedisClient.SMembers(ctx, redisUserSessionsKeyPrefix+userID).Result()
+	if err != nil {
+		r.Logger.Errorf("Redis error while listing sessions: %v", err)
+		return err
+	}
+
+	keys := []string{redisUserSessionsKeyPrefix + userID}
+	for _, id := range ids {
+		keys = append(keys, redisSessionKeyPrefix+id)
+	}
+	if err := r.RedisClient.Del(ctx, keys...).Err(); err != nil {
+		r.Logger.Errorf("failed to revoke sessions of user %s: %v", userID, err)
+		return err
+	}
+	r.Logger.Infof("revoked %d session(s) of user %s", len(ids), userID)
+	return nil
+}
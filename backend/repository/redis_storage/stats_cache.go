@@ -0,0 +1,77 @@
+package redis_storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository"
+	"github.com/go-redis/redis/v8"
+)
+
+const redisStatsKeyPrefix = "stats:"
+
+// StatsCacheRepository caches stats aggregates in Redis. Each user's
+// entries share one hash, so they're invalidated with a single DEL.
+//
+//	stats:<userID>  hash of query key -> aggregates JSON
+type StatsCacheRepository struct {
+	RedisClient *redis.Client
+	TTL         time.Duration
+	Logger      repository.Logger
+}
+
+// NewStatsCacheRepository creates a Redis-backed stats cache whose entries live for ttl
+func NewStatsCacheRepository(redisClient *redis.Client, ttl time.Duration, logger repository.Logger) domain.StatsCacheService {
+	return &StatsCacheRepository{
+		RedisClient: redisClient,
+		TTL:         ttl,
+		Logger:      logger,
+	}
+}
+
+// Get returns the cached aggregates of the user's query
+func (r *StatsCacheRepository) Get(ctx context.Context, userID, key string) (*domain.StatsAggregates, error) {
+	data, err := r.RedisClient.HGet(ctx, redisStatsKeyPrefix+userID, key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, domain.ErrStatsNotCached
+	} else if err != nil {
+		r.Logger.Errorf("failed to read cached stats: %v", err)
+		return nil, err
+	}
+	var aggregates domain.StatsAggregates
+	if err := json.Unmarshal(data, &aggregates); err != nil {
+		r.Logger.Errorf("failed to decode cached stats: %v", err)
+		return nil, domain.ErrStatsNotCached
+	}
+	return &aggregates, nil
+}
+
+// Set caches the aggregates of the user's query. The TTL restarts with every
+// entry, so a user's hash lives ttl past their latest stats query.
+func (r *StatsCacheRepository) Set(ctx context.Context, userID, key string, aggregates *domain.StatsAggregates) error {
+	data, err := json.Marshal(aggregates)
+	if err != nil {
+		return err
+	}
+	_, err = r.RedisClient.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, redisStatsKeyPrefix+userID, key, data)
+		pipe.Expire(ctx, redisStatsKeyPrefix+userID, r.TTL)
+		return nil
+	})
+	if err != nil {
+		r.Logger.Errorf("failed to cache stats: %v", err)
+	}
+	return err
+}
+
+// Invalidate drops every cached entry of the user
+func (r *StatsCacheRepository) Invalidate(ctx context.Context, userID string) error {
+	if err := r.RedisClient.Del(ctx, redisStatsKeyPrefix+userID).Err(); err != nil {
+		r.Logger.Errorf("failed to invalidate cached stats: %v", err)
+		return err
+	}
+	return nil
+}
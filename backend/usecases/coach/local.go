@@ -0,0 +1,144 @@
+package coach
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// topicKeywords route a message to a reply template. Topics other than goal
+// match the recommendation categories.
+var topicKeywords = map[string][]string{
+	"transport":   {"car", "cars", "drive", "driving", "commute", "commuting", "flight", "flights", "fly", "flying", "plane", "train", "bus", "bike", "cycling", "travel", "transport"},
+	"food":        {"food", "eat", "eating", "meat", "beef", "diet", "meal", "meals", "vegan", "vegetarian", "waste"},
+	"home":        {"home", "heat", "heating", "electricity", "energy", "gas", "thermostat", "house", "flat", "apartment"},
+	"consumption": {"buy", "buying", "shopping", "shop", "clothes", "stuff", "repair", "second-hand"},
+	"goal":        {"goal", "goals", "target", "week", "weekly", "progress"},
+}
+
+// topicOrder breaks ties between topics deterministically
+var topicOrder = []string{"goal", "transport", "food", "home", "consumption"}
+
+// genericTips answer a topic the user has no recommendation for
+var genericTips = map[string]string{
+	"transport":   "Walking, cycling or taking the bus or train for short trips is the quickest way to cut transport emissions.",
+	"food":        "Swapping a beef meal for a vegetarian one saves almost 7 kg CO2e each time, and planning meals keeps food out of the bin.",
+	"home":        "Turning the heating down by a degree and switching to a renewable electricity tariff are easy wins at home.",
+	"consumption": "Buying second-hand and repairing what you own avoids the emissions of making new things.",
+}
+
+var localTemplates = template.Must(template.New("coach").Funcs(template.FuncMap{
+	"kg":    func(v float64) string { return fmt.Sprintf("%.0f kg CO2e", v) },
+	"label": func(category string) string { return strings.ReplaceAll(category, "_", " ") },
+}).Parse(`
+{{- define "overview" -}}
+Hi {{.Summary.Name}}!
+{{- if .Summary.AnnualBaseline}} Your onboarding survey puts your footprint at about {{kg .Summary.AnnualBaseline}} a year.
+{{- else}} Take the onboarding survey so I can estimate your yearly footprint.{{end}}
+{{- if .Recent}} In the last 30 days you logged {{kg .Recent}}, most of it from {{label .Largest}}.{{end}}
+{{- with .Top}} The most effective next step for you: {{.Title}}, saving about {{kg .AnnualSavings}} a year. {{.Explanation}}
+{{- else}} Log a few activities and I can point you to your biggest savings.{{end}}
+{{- if .Summary.CO2Saved}} Your completed tasks have already saved {{kg .Summary.CO2Saved}}, keep it up!{{end}}
+{{- end}}
+
+{{- define "topic" -}}
+{{with .Top}}For {{$.Topic}}, my top suggestion is: {{.Title}}. It could save about {{kg .AnnualSavings}} a year. {{.Explanation}}
+{{- else}}{{.Tip}}{{end}}
+{{- end}}
+
+{{- define "goal" -}}
+{{with .Summary.Goal}}This week ({{.Week}}) you aim to save {{kg .TargetCO2}} and have saved {{kg .Progress.Reduction}} so far, {{printf "%.0f" .Progress.Percent}}% of your target.
+{{- if lt .Progress.Percent 100.0}}{{with $.Top}} To close the gap, try this: {{.Title}}.{{end}}{{else}} Target reached, well done!{{end}}
+{{- else}}You have no goal for this week yet. Set one and I'll track your progress with you.{{end}}
+{{- end}}
+`))
+
+// localProvider answers from templates, choosing one by the keywords of the
+// message. It never calls out, so the same request always gets the same
+// reply.
+type localProvider struct{}
+
+func newLocalProvider() *localProvider {
+	return &localProvider{}
+}
+
+// localReply is the data the templates render
+type localReply struct {
+	Summary *domain.FootprintSummary
+	Topic   string
+	Top     *domain.Recommendation // best recommendation for the topic, if any
+	Tip     string
+	Recent  float64 // kg CO2e logged in the last 30 days
+	Largest string  // category with the largest share of Recent
+}
+
+func (p *localProvider) Name() string {
+	return "local"
+}
+
+func (p *localProvider) Advise(_ context.Context, req *Request) (*Reply, error) {
+	prompt, err := SystemPrompt(req.Summary)
+	if err != nil {
+		return nil, err
+	}
+
+	data := localReply{Summary: req.Summary, Topic: topicOf(req.Message)}
+	for _, recommendation := range req.Summary.Recommendations {
+		// Recommendations come ranked, so the first match is the best
+		if data.Topic == "overview" || data.Topic == "goal" || recommendation.Category == data.Topic {
+			data.Top = recommendation
+			break
+		}
+	}
+	data.Tip = genericTips[data.Topic]
+	categories := make([]string, 0, len(req.Summary.RecentByType))
+	for category := range req.Summary.RecentByType {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	for _, category := range categories {
+		kg := req.Summary.RecentByType[category]
+		data.Recent += kg
+		if data.Largest == "" || kg > req.Summary.RecentByType[data.Largest] {
+			data.Largest = category
+		}
+	}
+
+	name := data.Topic
+	if name != "overview" && name != "goal" {
+		name = "topic"
+	}
+	var text strings.Builder
+	if err := localTemplates.ExecuteTemplate(&text, name, data); err != nil {
+		return nil, fmt.Errorf("local: %w", err)
+	}
+	return &Reply{Text: text.String(), Prompt: prompt}, nil
+}
+
+// topicOf returns the topic whose keywords the message mentions most, or
+// overview when it mentions none
+func topicOf(message string) string {
+	words := strings.FieldsFunc(strings.ToLower(message), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '-'
+	})
+	best, bestCount := "overview", 0
+	for _, topic := range topicOrder {
+		count := 0
+		for _, word := range words {
+			for _, keyword := range topicKeywords[topic] {
+				if word == keyword {
+					count++
+				}
+			}
+		}
+		if count > bestCount {
+			best, bestCount = topic, count
+		}
+	}
+	return best
+}
@@ -0,0 +1,102 @@
+package coach
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/aygoko/EcoMInd/backend/config"
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// maxErrorBody bounds how much of a failed response is read for its message
+const maxErrorBody = 4 << 10
+
+// openAIProvider asks an OpenAI-compatible chat completions API
+type openAIProvider struct {
+	client    *http.Client
+	url       string
+	apiKey    string
+	model     string
+	maxTokens int
+}
+
+func newOpenAIProvider(cfg config.CoachConfig, client *http.Client) *openAIProvider {
+	return &openAIProvider{
+		client:    client,
+		url:       strings.TrimSuffix(cfg.BaseURL, "/") + "/chat/completions",
+		apiKey:    cfg.APIKey,
+		model:     cfg.Model,
+		maxTokens: cfg.MaxTokens,
+	}
+}
+
+type chatCompletionRequest struct {
+	Model     string                `json:"model"`
+	Messages  []domain.CoachMessage `json:"messages"`
+	MaxTokens int                   `json:"max_tokens"`
+}
+
+type chatCompletionResponse struct {
+	Choices []struct {
+		Message domain.CoachMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (p *openAIProvider) Name() string {
+	return "openai"
+}
+
+func (p *openAIProvider) Advise(ctx context.Context, req *Request) (*Reply, error) {
+	prompt, err := SystemPrompt(req.Summary)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]domain.CoachMessage, 0, len(req.History)+2)
+	messages = append(messages, domain.CoachMessage{Role: "system", Content: prompt})
+	messages = append(messages, req.History...)
+	messages = append(messages, domain.CoachMessage{Role: domain.CoachRoleUser, Content: req.Message})
+
+	body, err := json.Marshal(chatCompletionRequest{Model: p.model, Messages: messages, MaxTokens: p.maxTokens})
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("openai: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var failure chatCompletionResponse
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		if json.Unmarshal(data, &failure) == nil && failure.Error != nil {
+			return nil, fmt.Errorf("openai: %s: %s", resp.Status, failure.Error.Message)
+		}
+		return nil, fmt.Errorf("openai: %s", resp.Status)
+	}
+
+	var completion chatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&completion); err != nil {
+		return nil, fmt.Errorf("openai: decode response: %w", err)
+	}
+	if len(completion.Choices) == 0 || strings.TrimSpace(completion.Choices[0].Message.Content) == "" {
+		return nil, fmt.Errorf("openai: empty completion")
+	}
+	return &Reply{Text: strings.TrimSpace(completion.Choices[0].Message.Content), Prompt: prompt}, nil
+}
@@ -0,0 +1,60 @@
+// Package coach turns a structured summary of a user's footprint into
+// sustainability advice. Providers are pluggable: a deterministic local
+// provider answers from templates, offline and in tests, and an HTTP adapter
+// talks to any OpenAI-compatible chat completions API.
+package coach
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/aygoko/EcoMInd/backend/config"
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// Request is one question to the coach with the context it answers in
+type Request struct {
+	Summary *domain.FootprintSummary
+	History []domain.CoachMessage // earlier turns, oldest first
+	Message string
+}
+
+// Reply is the coach's answer and the system prompt it was produced from,
+// kept for the coaching log
+type Reply struct {
+	Text   string
+	Prompt string
+}
+
+// Provider produces advice for a coaching request
+type Provider interface {
+	Name() string
+	Advise(ctx context.Context, req *Request) (*Reply, error)
+}
+
+// New returns the provider selected by cfg
+func New(cfg config.CoachConfig) (Provider, error) {
+	switch cfg.Provider {
+	case "local":
+		return newLocalProvider(), nil
+	case "openai":
+		return newOpenAIProvider(cfg, &http.Client{Timeout: cfg.Timeout}), nil
+	default:
+		return nil, fmt.Errorf("coach: unknown provider %q", cfg.Provider)
+	}
+}
+
+// systemInstructions frame every conversation with a language model
+const systemInstructions = `You are EcoMind's sustainability coach. Help the user lower their carbon footprint with concrete, encouraging advice tailored to the footprint summary below. Quantities are kg CO2e. Prefer the listed recommendations, mention their estimated savings, keep answers under 150 words and don't invent numbers that aren't in the summary.`
+
+// SystemPrompt renders the instructions and the user's footprint summary
+// as the system message of a conversation
+func SystemPrompt(summary *domain.FootprintSummary) (string, error) {
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("coach: encode summary: %w", err)
+	}
+	return systemInstructions + "\n\nFootprint summary:\n" + string(data), nil
+}
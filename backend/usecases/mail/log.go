@@ -0,0 +1,42 @@
+package mail
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// logMailer writes mails to a file, or the log while file is empty, instead
+// of sending them. Links in the mails can be opened from there.
+type logMailer struct {
+	from string
+	file string
+	mu   sync.Mutex // serializes appends to file
+}
+
+func newLogMailer(from, file string) *logMailer {
+	return &logMailer{from: from, file: file}
+}
+
+// Send writes msg
+func (m *logMailer) Send(_ context.Context, msg *Message) error {
+	data := render(m.from, msg, time.Now())
+	if m.file == "" {
+		log.Printf("mail to %s:\n%s", msg.To, data)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	f, err := os.OpenFile(m.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, "\r\n\r\n"...)); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,55 @@
+// Package mail sends the emails of account flows like email verification
+// and password reset. Mailers are pluggable: an SMTP mailer for production
+// and a log mailer that writes mails to a file or the log, for local
+// development and tests.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/config"
+)
+
+// Message is a plain-text email to one recipient
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer sends messages
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// New returns the mailer selected by cfg
+func New(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Provider {
+	case "log":
+		return newLogMailer(cfg.From, cfg.File), nil
+	case "smtp":
+		return newSMTPMailer(cfg), nil
+	default:
+		return nil, fmt.Errorf("mail: unknown provider %q", cfg.Provider)
+	}
+}
+
+// render writes msg as an RFC 5322 message from sender. Header values are
+// stripped of line breaks so user input can't add headers.
+func render(from string, msg *Message, now time.Time) []byte {
+	header := strings.NewReplacer("\r", "", "\n", "")
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", header.Replace(from))
+	fmt.Fprintf(&b, "To: %s\r\n", header.Replace(msg.To))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", header.Replace(msg.Subject)))
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(msg.Body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
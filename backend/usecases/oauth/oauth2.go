@@ -0,0 +1,115 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aygoko/EcoMInd/backend/config"
+	"golang.org/x/oauth2"
+)
+
+// oauth2Provider signs in with a provider that doesn't speak OIDC. The
+// account is read from its userinfo endpoint.
+type oauth2Provider struct {
+	name          string
+	config        *oauth2.Config
+	client        *http.Client
+	userInfoURL   string
+	subjectField  string
+	emailField    string
+	verifiedField string
+}
+
+func newOAuth2Provider(name string, cfg *config.OAuthProviderConfig, client *http.Client) *oauth2Provider {
+	return &oauth2Provider{
+		name: name,
+		config: &oauth2.Config{
+			ClientID:     cfg.ClientID,
+			ClientSecret: cfg.ClientSecret,
+			RedirectURL:  cfg.RedirectURL,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:  cfg.AuthURL,
+				TokenURL: cfg.TokenURL,
+			},
+			Scopes: cfg.Scopes,
+		},
+		client:        client,
+		userInfoURL:   cfg.UserInfoURL,
+		subjectField:  cfg.SubjectField,
+		emailField:    cfg.EmailField,
+		verifiedField: cfg.EmailVerifiedField,
+	}
+}
+
+func (p *oauth2Provider) Name() string {
+	return p.name
+}
+
+func (p *oauth2Provider) AuthCodeURL(_ context.Context, state, codeVerifier string) (string, error) {
+	return p.config.AuthCodeURL(state, oauth2.AccessTypeOnline, oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+func (p *oauth2Provider) Exchange(ctx context.Context, code, codeVerifier string) (*Profile, error) {
+	ctx = withClient(ctx, p.client)
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("%s: exchange code: %w", p.name, err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.userInfoURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.config.Client(ctx, token).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: userinfo: %w", p.name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: userinfo: %s", p.name, resp.Status)
+	}
+
+	var info interface{}
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber() // keep numeric IDs exact
+	if err := decoder.Decode(&info); err != nil {
+		return nil, fmt.Errorf("%s: userinfo: %w", p.name, err)
+	}
+
+	profile := &Profile{Subject: lookup(info, p.subjectField)}
+	if profile.Subject == "" {
+		return nil, fmt.Errorf("%s: userinfo has no %s", p.name, p.subjectField)
+	}
+	// Only take an email the provider says it verified, or anyone could sign
+	// up with someone else's address there
+	if p.emailField != "" && p.verifiedField != "" && lookup(info, p.verifiedField) == "true" {
+		profile.Email = lookup(info, p.emailField)
+	}
+	return profile, nil
+}
+
+// lookup returns the string, number or boolean at a dotted path of a decoded
+// JSON document, or "" when there is none
+func lookup(doc interface{}, path string) string {
+	for _, name := range strings.Split(path, ".") {
+		object, ok := doc.(map[string]interface{})
+		if !ok {
+			return ""
+		}
+		doc = object[name]
+	}
+	switch v := doc.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		return ""
+	}
+}
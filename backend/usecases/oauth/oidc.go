@@ -0,0 +1,170 @@
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/aygoko/EcoMInd/backend/config"
+	"github.com/golang-jwt/jwt/v4"
+	"golang.org/x/oauth2"
+)
+
+// idTokenMethods are the signing algorithms accepted on ID tokens. HMAC is
+// excluded: it would make the client secret a signing key.
+var idTokenMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// discoveryDocument is the part of an OpenID provider's metadata we use
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// idTokenClaims are the ID token claims we read. email_verified is a
+// boolean, but some providers send it as a string.
+type idTokenClaims struct {
+	Email         string      `json:"email"`
+	EmailVerified interface{} `json:"email_verified"`
+	jwt.RegisteredClaims
+}
+
+// oidcProvider signs in with an OpenID Connect provider. Endpoints come from
+// the issuer's discovery document and the account from the verified ID token.
+type oidcProvider struct {
+	name     string
+	issuer   string
+	settings *config.OAuthProviderConfig
+	client   *http.Client
+
+	mu     sync.Mutex
+	config *oauth2.Config // nil until discovery succeeded
+	keys   *keySet
+}
+
+func newOIDCProvider(name string, cfg *config.OAuthProviderConfig, client *http.Client) *oidcProvider {
+	return &oidcProvider{
+		name:     name,
+		issuer:   strings.TrimSuffix(cfg.Issuer, "/"),
+		settings: cfg,
+		client:   client,
+	}
+}
+
+func (p *oidcProvider) Name() string {
+	return p.name
+}
+
+// discover fetches the issuer's metadata once. Failures aren't cached, so
+// the next request tries again.
+func (p *oidcProvider) discover(ctx context.Context) (*oauth2.Config, *keySet, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.config != nil {
+		return p.config, p.keys, nil
+	}
+
+	var doc discoveryDocument
+	if err := getJSON(ctx, p.client, p.issuer+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, nil, fmt.Errorf("%s: discovery: %w", p.name, err)
+	}
+	// The issuer must vouch for itself, or a redirect could swap in another
+	if strings.TrimSuffix(doc.Issuer, "/") != p.issuer {
+		return nil, nil, fmt.Errorf("%s: discovery: issuer %q does not match %q", p.name, doc.Issuer, p.issuer)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, nil, fmt.Errorf("%s: discovery: incomplete provider metadata", p.name)
+	}
+
+	scopes := p.settings.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"openid", "email"}
+	}
+	p.config = &oauth2.Config{
+		ClientID:     p.settings.ClientID,
+		ClientSecret: p.settings.ClientSecret,
+		RedirectURL:  p.settings.RedirectURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  doc.AuthorizationEndpoint,
+			TokenURL: doc.TokenEndpoint,
+		},
+		Scopes: scopes,
+	}
+	p.keys = newKeySet(doc.JWKSURI, p.client)
+	return p.config, p.keys, nil
+}
+
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, state, codeVerifier string) (string, error) {
+	cfg, _, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	return cfg.AuthCodeURL(state, oauth2.AccessTypeOnline, oauth2.S256ChallengeOption(codeVerifier)), nil
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, code, codeVerifier string) (*Profile, error) {
+	cfg, keys, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	token, err := cfg.Exchange(withClient(ctx, p.client), code, oauth2.VerifierOption(codeVerifier))
+	if err != nil {
+		return nil, fmt.Errorf("%s: exchange code: %w", p.name, err)
+	}
+	rawIDToken, _ := token.Extra("id_token").(string)
+	if rawIDToken == "" {
+		return nil, fmt.Errorf("%s: token response has no id_token", p.name)
+	}
+	return p.verify(ctx, keys, rawIDToken)
+}
+
+// verify checks the ID token's signature against the issuer's keys and its
+// issuer, audience and lifetime
+func (p *oidcProvider) verify(ctx context.Context, keys *keySet, rawIDToken string) (*Profile, error) {
+	var claims idTokenClaims
+	parser := jwt.NewParser(jwt.WithValidMethods(idTokenMethods))
+	_, err := parser.ParseWithClaims(rawIDToken, &claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		return keys.key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: id_token: %w", p.name, err)
+	}
+	if strings.TrimSuffix(claims.Issuer, "/") != p.issuer {
+		return nil, fmt.Errorf("%s: id_token: unexpected issuer %q", p.name, claims.Issuer)
+	}
+	if !claims.VerifyAudience(p.settings.ClientID, true) {
+		return nil, fmt.Errorf("%s: id_token: not issued for this client", p.name)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%s: id_token: missing sub", p.name)
+	}
+
+	profile := &Profile{Subject: claims.Subject}
+	if verified, _ := claims.EmailVerified.(bool); verified || claims.EmailVerified == "true" {
+		profile.Email = claims.Email
+	}
+	return profile, nil
+}
+
+// getJSON decodes the JSON document at url into v
+func getJSON(ctx context.Context, client *http.Client, url string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return errors.New(resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
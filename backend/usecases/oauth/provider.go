@@ -0,0 +1,84 @@
+// Package oauth signs users in with external OAuth providers. OIDC
+// providers are configured by issuer alone and verified through their
+// published signing keys; plain OAuth2 providers read the account from a
+// userinfo endpoint.
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/config"
+	"golang.org/x/oauth2"
+)
+
+// Profile is the provider account behind a completed authorization
+type Profile struct {
+	Subject string // the provider's stable account ID
+	Email   string // empty unless the provider vouches for it
+}
+
+// Provider is a configured sign-in provider
+type Provider interface {
+	Name() string
+	// AuthCodeURL returns the consent page URL for state, protected by the
+	// PKCE challenge of codeVerifier.
+	AuthCodeURL(ctx context.Context, state, codeVerifier string) (string, error)
+	// Exchange redeems an authorization code and returns the account it was
+	// issued for.
+	Exchange(ctx context.Context, code, codeVerifier string) (*Profile, error)
+}
+
+// Registry holds the enabled providers by name
+type Registry struct {
+	providers map[string]Provider
+}
+
+// httpTimeout bounds every call to a provider
+const httpTimeout = 10 * time.Second
+
+// NewRegistry builds a provider for every enabled entry of cfg. OIDC
+// discovery happens on first use so that a provider being down doesn't keep
+// the server from starting.
+func NewRegistry(cfg map[string]*config.OAuthProviderConfig) (*Registry, error) {
+	client := &http.Client{Timeout: httpTimeout}
+	r := &Registry{providers: make(map[string]Provider)}
+	for name, p := range cfg {
+		if !p.Enabled() {
+			continue
+		}
+		switch p.Type {
+		case "oidc":
+			r.providers[name] = newOIDCProvider(name, p, client)
+		case "oauth2":
+			r.providers[name] = newOAuth2Provider(name, p, client)
+		default:
+			return nil, fmt.Errorf("oauth provider %s: unknown type %q", name, p.Type)
+		}
+	}
+	return r, nil
+}
+
+// Get returns the enabled provider with the given name
+func (r *Registry) Get(name string) (Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+// Names lists the enabled providers in name order
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withClient makes the oauth2 package use client for token requests
+func withClient(ctx context.Context, client *http.Client) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, client)
+}
@@ -0,0 +1,289 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/config"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/aygoko/EcoMInd/backend/usecases/mail"
+	"github.com/aygoko/EcoMInd/backend/usecases/validate"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrMailUnavailable is returned when an account email can't be sent
+var ErrMailUnavailable = errs.Upstream("the email could not be sent, try again later")
+
+// accountMail is the text of an account email in one language. Body takes
+// the login, the link and how long the link is valid.
+type accountMail struct {
+	Subject string
+	Body    string
+}
+
+// accountMails holds the email of each one-time token type per language
+var accountMails = map[string]map[string]accountMail{
+	TokenTypeEmailVerification: {
+		"en": {
+			Subject: "Confirm your EcoMind email address",
+			Body:    "Hi %s,\n\nopen the link below to confirm that this is your email address:\n\n%s\n\nThe link works once and is valid for %s. If you didn't sign up for EcoMind, ignore this email.\n",
+		},
+		"ru": {
+			Subject: "Подтвердите адрес почты в EcoMind",
+			Body:    "Здравствуйте, %s!\n\nОткройте ссылку ниже, чтобы подтвердить, что это ваш адрес почты:\n\n%s\n\nСсылка одноразовая и действует %s. Если вы не регистрировались в EcoMind, просто проигнорируйте это письмо.\n",
+		},
+	},
+	TokenTypePasswordReset: {
+		"en": {
+			Subject: "Reset your EcoMind password",
+			Body:    "Hi %s,\n\nsomeone, hopefully you, asked to reset your EcoMind password. Open the link below to choose a new one:\n\n%s\n\nThe link works once and is valid for %s. If you didn't ask for this, ignore this email and your password stays as it is.\n",
+		},
+		"ru": {
+			Subject: "Сброс пароля EcoMind",
+			Body:    "Здравствуйте, %s!\n\nКто-то, надеемся, что вы, запросил сброс пароля EcoMind. Откройте ссылку ниже, чтобы задать новый пароль:\n\n%s\n\nСсылка одноразовая и действует %s. Если вы не запрашивали сброс, проигнорируйте это письмо, и пароль останется прежним.\n",
+		},
+	},
+}
+
+// accountPages are the app pages emailed links open, under LinkBaseURL
+var accountPages = map[string]string{
+	TokenTypeEmailVerification: "/verify-email",
+	TokenTypePasswordReset:     "/reset-password",
+}
+
+// AccountService emails single-use links that verify a user's email address
+// or reset their password, and redeems them. A link carries a signed token
+// whose ID is tracked in OneTime, so it works once and a newer link of the
+// same kind voids older ones.
+type AccountService struct {
+	Users    domain.UserService
+	Tokens   *TokenService
+	OneTime  domain.OneTimeTokenService
+	Sessions *SessionService
+	Auth     *AuthService
+	Limiter  domain.RateLimitService
+	Mailer   mail.Mailer
+	Config   config.UsersConfig
+}
+
+// NewAccountService creates a new account service instance.
+// Panics if a dependency is nil.
+func NewAccountService(
+	users domain.UserService,
+	tokens *TokenService,
+	oneTime domain.OneTimeTokenService,
+	sessions *SessionService,
+	auth *AuthService,
+	limiter domain.RateLimitService,
+	mailer mail.Mailer,
+	cfg config.UsersConfig,
+) *AccountService {
+	if users == nil || tokens == nil || oneTime == nil || sessions == nil || auth == nil || limiter == nil || mailer == nil {
+		panic("dependencies must not be nil")
+	}
+	return &AccountService{
+		Users:    users,
+		Tokens:   tokens,
+		OneTime:  oneTime,
+		Sessions: sessions,
+		Auth:     auth,
+		Limiter:  limiter,
+		Mailer:   mailer,
+		Config:   cfg,
+	}
+}
+
+// RequestVerification emails the user a link confirming their email
+// address. It returns a *domain.RateLimitError once the user was sent
+// Config.MailLimit emails in the window.
+func (s *AccountService) RequestVerification(ctx context.Context, user *domain.User) error {
+	switch {
+	case user.Email == "":
+		return domain.ErrEmailMissing
+	case user.EmailVerified:
+		return domain.ErrEmailAlreadyVerified
+	}
+	return s.send(ctx, user, TokenTypeEmailVerification, s.Config.VerificationTTL)
+}
+
+// VerifyEmail redeems an email verification link and returns the user with
+// their email verified. Links sent to an address the user has since
+// changed are rejected.
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) (*domain.User, error) {
+	user, claims, err := s.redeem(ctx, token, TokenTypeEmailVerification)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Users.SetEmailVerified(ctx, user.Login, claims.Email); errors.Is(err, domain.ErrUserNotFound) {
+		return nil, domain.ErrOneTimeTokenInvalid
+	} else if err != nil {
+		return nil, err
+	}
+	user.EmailVerified = true
+	return user, nil
+}
+
+// RequestPasswordReset emails a password reset link to the user with the
+// address. The email is sent in the background and its failures are only
+// logged, so unknown addresses, users over their email limit and a mail
+// server that is down all look like success, equally quickly, and the
+// endpoint doesn't reveal who has an account.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.Users.GetByEmail(ctx, validate.NormalizeEmail(email))
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	// Keep the request's values, such as the language, past its end
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		err := s.send(ctx, user, TokenTypePasswordReset, s.Config.ResetTTL)
+		var rateLimitErr *domain.RateLimitError
+		if err != nil && !errors.As(err, &rateLimitErr) && !errors.Is(err, ErrMailUnavailable) {
+			log.Printf("Failed to send %s mail to user %s: %v", TokenTypePasswordReset, user.ID, err)
+		}
+	}()
+	return nil
+}
+
+// ResetPassword redeems a password reset link, setting the new password,
+// signing the user out everywhere and lifting a sign-in lockout. Receiving
+// the link also proves the user owns their email address.
+func (s *AccountService) ResetPassword(ctx context.Context, token, password string) error {
+	user, claims, err := s.parse(ctx, token, TokenTypePasswordReset)
+	if err != nil {
+		return err
+	}
+	// Check the password before using up the link so the user can retry
+	v := validate.New(ctx)
+	v.Password("password", password, user.Login, emailName(user.Email))
+	if err := v.Err(); err != nil {
+		return err
+	}
+	if err := s.OneTime.Take(ctx, TokenTypePasswordReset, user.ID, claims.ID); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.Users.SetPassword(ctx, user.Login, string(hash)); err != nil {
+		return err
+	}
+	if err := s.Sessions.RevokeAll(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := s.Auth.Unlock(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := s.Users.SetEmailVerified(ctx, user.Login, claims.Email); err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return err
+	}
+	return nil
+}
+
+// send emails the user a new one-time link of tokenType, voiding earlier ones
+func (s *AccountService) send(ctx context.Context, user *domain.User, tokenType string, ttl time.Duration) error {
+	now := time.Now()
+	count, resetAt, err := s.Limiter.Hit(ctx, "mail:"+tokenType+":"+user.ID, s.Config.MailWindow)
+	if err != nil {
+		return err
+	}
+	if count > s.Config.MailLimit {
+		return &domain.RateLimitError{RetryAfter: resetAt.Sub(now)}
+	}
+
+	id := uuid.NewString()
+	token, err := s.Tokens.IssueOneTime(user.ID, tokenType, id, user.Email, ttl)
+	if err != nil {
+		return err
+	}
+	if err := s.OneTime.Save(ctx, tokenType, user.ID, id, ttl); err != nil {
+		return err
+	}
+
+	language := domain.LanguageFromContext(ctx)
+	text, ok := accountMails[tokenType][language]
+	if !ok {
+		text = accountMails[tokenType][validate.Languages[0]]
+	}
+	link := strings.TrimSuffix(s.Config.LinkBaseURL, "/") + accountPages[tokenType] + "?token=" + url.QueryEscape(token)
+	msg := &mail.Message{
+		To:      user.Email,
+		Subject: text.Subject,
+		Body:    fmt.Sprintf(text.Body, user.Login, link, validFor(language, ttl)),
+	}
+	if err := s.Mailer.Send(ctx, msg); err != nil {
+		log.Printf("Failed to send %s mail to user %s: %v", tokenType, user.ID, err)
+		return ErrMailUnavailable
+	}
+	return nil
+}
+
+// parse checks a one-time token's signature and claims and returns its user
+// without using it up
+func (s *AccountService) parse(ctx context.Context, token, tokenType string) (*domain.User, *Claims, error) {
+	claims, err := s.Tokens.Parse(token, tokenType)
+	if err != nil || claims.ID == "" {
+		return nil, nil, domain.ErrOneTimeTokenInvalid
+	}
+	user, err := s.Users.GetByID(ctx, claims.UserID)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return nil, nil, domain.ErrOneTimeTokenInvalid
+	} else if err != nil {
+		return nil, nil, err
+	}
+	return user, claims, nil
+}
+
+// redeem parses a one-time token and uses it up
+func (s *AccountService) redeem(ctx context.Context, token, tokenType string) (*domain.User, *Claims, error) {
+	user, claims, err := s.parse(ctx, token, tokenType)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.OneTime.Take(ctx, tokenType, user.ID, claims.ID); err != nil {
+		return nil, nil, err
+	}
+	return user, claims, nil
+}
+
+// validFor words how long a link is valid, in whole hours or minutes
+func validFor(language string, ttl time.Duration) string {
+	n, hours := int(ttl/time.Minute), false
+	if ttl%time.Hour == 0 {
+		n, hours = int(ttl/time.Hour), true
+	}
+	if language == "ru" {
+		forms := [3]string{"минуту", "минуты", "минут"}
+		if hours {
+			forms = [3]string{"час", "часа", "часов"}
+		}
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return fmt.Sprintf("%d %s", n, forms[0])
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return fmt.Sprintf("%d %s", n, forms[1])
+		default:
+			return fmt.Sprintf("%d %s", n, forms[2])
+		}
+	}
+	unit := "minute"
+	if hours {
+		unit = "hour"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", n, unit)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// maxCountedGoals bounds the goal history read for MetricGoalsAchieved,
+// about ten years of weeks
+const maxCountedGoals = 520
+
+// metricFunc computes an achievement metric of a user
+type metricFunc func(ctx context.Context, s *AchievementService, user *domain.User, now time.Time) (float64, error)
+
+var achievementMetrics = map[string]metricFunc{
+	MetricActivitiesLogged: func(ctx context.Context, s *AchievementService, user *domain.User, now time.Time) (float64, error) {
+		// Activities may be dated slightly ahead to allow for clock skew
+		categories, err := s.Stats.Breakdown(ctx, user.ID, time.Time{}, now.Add(time.Hour))
+		if err != nil {
+			return 0, err
+		}
+		count := 0
+		for _, category := range categories {
+			count += category.Activities
+		}
+		return float64(count), nil
+	},
+	MetricActivityStreak: func(ctx context.Context, s *AchievementService, user *domain.User, now time.Time) (float64, error) {
+		days, err := s.Stats.ActiveDays(ctx, user.ID, time.UTC)
+		if err != nil {
+			return 0, err
+		}
+		return float64(streak(days, now.UTC()).Longest), nil
+	},
+	MetricTasksCompleted: func(ctx context.Context, s *AchievementService, user *domain.User, _ time.Time) (float64, error) {
+		assignments, err := s.Tasks.ListAssignments(ctx, user.ID, domain.TaskCompleted)
+		return float64(len(assignments)), err
+	},
+	MetricGoalsAchieved: func(ctx context.Context, s *AchievementService, user *domain.User, _ time.Time) (float64, error) {
+		goals, err := s.Goals.List(ctx, user.ID, maxCountedGoals)
+		if err != nil {
+			return 0, err
+		}
+		achieved := 0
+		for _, goal := range goals {
+			if goal.Status == domain.GoalAchieved {
+				achieved++
+			}
+		}
+		return float64(achieved), nil
+	},
+	domain.MetricPoints: func(_ context.Context, _ *AchievementService, user *domain.User, _ time.Time) (float64, error) {
+		return float64(user.Points), nil
+	},
+	domain.MetricCO2Saved: func(_ context.Context, _ *AchievementService, user *domain.User, _ time.Time) (float64, error) {
+		return user.CO2Saved, nil
+	},
+}
+
+// metricValues computes a user's metrics on first use, so evaluating
+// several badges of one metric reads storage once
+type metricValues struct {
+	s      *AchievementService
+	user   *domain.User
+	now    time.Time
+	values map[string]float64
+}
+
+func (m *metricValues) get(ctx context.Context, metric string) (float64, error) {
+	if value, ok := m.values[metric]; ok {
+		return value, nil
+	}
+	value, err := achievementMetrics[metric](ctx, m.s, m.user, m.now)
+	if err != nil {
+		return 0, err
+	}
+	m.values[metric] = value
+	return value, nil
+}
+
+// AchievementService awards badges. Each badge is checked when one of its
+// events is published and awarded once its metric reaches the threshold.
+// Reading achievements never awards; badges added after users reached their
+// threshold are awarded by Backfill.
+type AchievementService struct {
+	Repo   domain.AchievementService
+	Users  domain.UserService
+	Tasks  domain.TaskService
+	Goals  domain.GoalService
+	Stats  domain.StatsService
+	Badges []domain.Badge
+}
+
+// NewAchievementService creates a new achievement service awarding badges.
+// Panics if any repository is nil or a badge uses an unknown metric.
+func NewAchievementService(repo domain.AchievementService, users domain.UserService, tasks domain.TaskService, goals domain.GoalService, stats domain.StatsService, badges ...domain.Badge) *AchievementService {
+	if repo == nil || users == nil || tasks == nil || goals == nil || stats == nil {
+		panic("repositories must not be nil")
+	}
+	for _, badge := range badges {
+		if _, ok := achievementMetrics[badge.Metric]; !ok {
+			panic(fmt.Sprintf("badge %s: unknown metric %q", badge.ID, badge.Metric))
+		}
+	}
+	return &AchievementService{
+		Repo:   repo,
+		Users:  users,
+		Tasks:  tasks,
+		Goals:  goals,
+		Stats:  stats,
+		Badges: badges,
+	}
+}
+
+// Handle awards the badges the event makes the user eligible for
+func (s *AchievementService) Handle(ctx context.Context, event domain.Event) error {
+	_, err := s.award(ctx, event.UserID, event.At, func(badge domain.Badge) bool {
+		return triggeredBy(badge, event.Type)
+	})
+	return err
+}
+
+// Backfill awards every badge each active user qualifies for, such as badges
+// added after users reached their threshold, and returns how many it
+// awarded.
+func (s *AchievementService) Backfill(ctx context.Context, now time.Time) (int, error) {
+	userIDs, err := s.Users.ListIDs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	total := 0
+	for _, userID := range userIDs {
+		count, err := s.award(ctx, userID, now, func(domain.Badge) bool { return true })
+		if errors.Is(err, domain.ErrUserNotFound) {
+			// Deleted since it was listed
+			continue
+		} else if err != nil {
+			return total, err
+		}
+		total += count
+	}
+	return total, nil
+}
+
+// award awards the badges selected by eligible that the user has reached the
+// threshold of, and returns how many it awarded
+func (s *AchievementService) award(ctx context.Context, userID string, at time.Time, eligible func(domain.Badge) bool) (int, error) {
+	awarded, err := s.Repo.Awarded(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	user, err := s.Users.GetByID(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	metrics := &metricValues{s: s, user: user, now: at, values: map[string]float64{}}
+	count := 0
+	for _, badge := range s.Badges {
+		if _, ok := awarded[badge.ID]; ok || !eligible(badge) {
+			continue
+		}
+		value, err := metrics.get(ctx, badge.Metric)
+		if err != nil {
+			return count, err
+		}
+		if value < badge.Threshold {
+			continue
+		}
+		added, err := s.Repo.Award(ctx, user.ID, badge.ID, at)
+		if err != nil {
+			return count, err
+		}
+		if added {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// For lists every badge with the user's progress towards it. It only reads:
+// badges are earned once awarded by an event or Backfill.
+func (s *AchievementService) For(ctx context.Context, user *domain.User, now time.Time) ([]*domain.Achievement, error) {
+	awarded, err := s.Repo.Awarded(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := &metricValues{s: s, user: user, now: now, values: map[string]float64{}}
+	achievements := make([]*domain.Achievement, 0, len(s.Badges))
+	for _, badge := range s.Badges {
+		achievement := &domain.Achievement{Badge: badge}
+		if at, ok := awarded[badge.ID]; ok {
+			achievement.Earned = true
+			achievement.AwardedAt = &at
+			achievement.Progress = badge.Threshold
+		} else {
+			value, err := metrics.get(ctx, badge.Metric)
+			if err != nil {
+				return nil, err
+			}
+			achievement.Progress = math.Min(value, badge.Threshold)
+		}
+		achievements = append(achievements, achievement)
+	}
+	return achievements, nil
+}
+
+// triggeredBy reports whether events of eventType can earn badge
+func triggeredBy(badge domain.Badge, eventType string) bool {
+	for _, on := range badge.On {
+		if on == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+package service
+
+import "github.com/aygoko/EcoMInd/backend/domain"
+
+// Achievement metrics, besides domain.MetricPoints and domain.MetricCO2Saved
+const (
+	MetricActivitiesLogged = "activities_logged"
+	MetricActivityStreak   = "activity_streak" // longest run of days with a logged activity, in UTC
+	MetricTasksCompleted   = "tasks_completed"
+	MetricGoalsAchieved    = "goals_achieved"
+)
+
+var (
+	onActivity = []string{domain.EventActivityLogged}
+	onTask     = []string{domain.EventTaskCompleted}
+	onGoal     = []string{domain.EventGoalClosed}
+)
+
+// DefaultBadges are the built-in achievements, in display order
+var DefaultBadges = []domain.Badge{
+	{
+		ID: "first_activity", Title: "First step", Icon: "footprints",
+		Description: "Log your first activity",
+		Metric:      MetricActivitiesLogged, Threshold: 1, On: onActivity,
+	},
+	{
+		ID: "activities_50", Title: "Dedicated logger", Icon: "notebook",
+		Description: "Log 50 activities",
+		Metric:      MetricActivitiesLogged, Threshold: 50, On: onActivity,
+	},
+	{
+		ID: "streak_7", Title: "Week streak", Icon: "flame",
+		Description: "Log activities 7 days in a row",
+		Metric:      MetricActivityStreak, Threshold: 7, On: onActivity,
+	},
+	{
+		ID: "streak_30", Title: "Habit formed", Icon: "calendar",
+		Description: "Log activities 30 days in a row",
+		Metric:      MetricActivityStreak, Threshold: 30, On: onActivity,
+	},
+	{
+		ID: "first_task", Title: "Eco starter", Icon: "sprout",
+		Description: "Complete your first eco task",
+		Metric:      MetricTasksCompleted, Threshold: 1, On: onTask,
+	},
+	{
+		ID: "tasks_10", Title: "Task master", Icon: "checklist",
+		Description: "Complete 10 eco tasks",
+		Metric:      MetricTasksCompleted, Threshold: 10, On: onTask,
+	},
+	{
+		ID: "saved_100kg", Title: "100 kg saved", Icon: "leaf",
+		Description: "Save 100 kg CO2e with completed tasks",
+		Metric:      domain.MetricCO2Saved, Threshold: 100, On: onTask,
+	},
+	{
+		ID: "saved_1t", Title: "One tonne saved", Icon: "tree",
+		Description: "Save 1000 kg CO2e with completed tasks",
+		Metric:      domain.MetricCO2Saved, Threshold: 1000, On: onTask,
+	},
+	{
+		ID: "points_500", Title: "Point collector", Icon: "star",
+		Description: "Earn 500 points",
+		Metric:      domain.MetricPoints, Threshold: 500, On: onTask,
+	},
+	{
+		ID: "first_goal", Title: "Goal getter", Icon: "target",
+		Description: "Achieve a weekly goal",
+		Metric:      MetricGoalsAchieved, Threshold: 1, On: onGoal,
+	},
+	{
+		ID: "goals_4", Title: "Month of goals", Icon: "trophy",
+		Description: "Achieve 4 weekly goals",
+		Metric:      MetricGoalsAchieved, Threshold: 4, On: onGoal,
+	},
+}
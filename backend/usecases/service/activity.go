@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/google/uuid"
+)
+
+// maxActivityQuantity rejects quantities that can only be typos
+const maxActivityQuantity = 1e6
+
+// ErrInvalidActivity is returned for activities that can't be logged
+var ErrInvalidActivity = errs.Validation("quantity must be positive, occurred_at not in the future and region an ISO 3166-1 alpha-2 code")
+
+// ActivityService logs activities and computes their footprint. Users never
+// write their CO2 total; it follows from the activities they log. Logging
+// or deleting an activity drops the user's cached statistics.
+type ActivityService struct {
+	Repo    domain.ActivityService
+	Factors *EmissionFactorService
+	Stats   *StatsService
+	// Events receives EventActivityLogged; while nil nothing is published
+	Events *EventBus
+}
+
+// NewActivityService creates a new activity service instance.
+// Panics if a dependency is nil.
+func NewActivityService(repo domain.ActivityService, factors *EmissionFactorService, stats *StatsService) *ActivityService {
+	if repo == nil || factors == nil || stats == nil {
+		panic("dependencies must not be nil")
+	}
+	return &ActivityService{
+		Repo:    repo,
+		Factors: factors,
+		Stats:   stats,
+	}
+}
+
+// Log computes the footprint of quantity units of category and records it
+// for the user. region is the ISO 3166-1 alpha-2 code of where the activity
+// happened, empty if unknown; a zero occurredAt means now. It returns
+// domain.ErrEmissionFactorNotFound when no factor applies.
+func (s *ActivityService) Log(ctx context.Context, userID, category, region string, quantity float64, occurredAt time.Time) (*domain.Activity, error) {
+	now := time.Now()
+	if occurredAt.IsZero() {
+		occurredAt = now
+	}
+	region = strings.ToUpper(region)
+	if !(quantity > 0 && quantity <= maxActivityQuantity) || occurredAt.After(now.Add(time.Minute)) || (region != "" && !regionCode.MatchString(region)) {
+		return nil, ErrInvalidActivity
+	}
+
+	factor, err := s.Factors.Resolve(ctx, category, region, occurredAt)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &domain.Activity{
+		ID:            uuid.NewString(),
+		UserID:        userID,
+		Category:      factor.Category,
+		Quantity:      quantity,
+		Unit:          factor.Unit,
+		Region:        region,
+		FactorID:      factor.ID,
+		FactorVersion: factor.Version,
+		Factor:        factor.Factor,
+		FactorSource:  factor.Source,
+		CO2e:          math.Round(quantity*factor.Factor*1000) / 1000, // to the gram
+		OccurredAt:    occurredAt.UTC(),
+	}
+	if err := s.Repo.Create(ctx, activity); err != nil {
+		return nil, err
+	}
+	s.Stats.Invalidate(ctx, userID)
+	s.Events.Publish(ctx, domain.Event{Type: domain.EventActivityLogged, UserID: userID, At: now})
+	return activity, nil
+}
+
+// Get returns one of the user's activities.
+func (s *ActivityService) Get(ctx context.Context, userID, id string) (*domain.Activity, error) {
+	return s.Repo.Get(ctx, userID, id)
+}
+
+// List returns the user's activities, most recent first.
+func (s *ActivityService) List(ctx context.Context, userID string, filter domain.ActivityFilter) ([]*domain.Activity, error) {
+	return s.Repo.List(ctx, userID, filter)
+}
+
+// Delete removes one of the user's activities and its share of their total.
+func (s *ActivityService) Delete(ctx context.Context, userID, id string) error {
+	if err := s.Repo.Delete(ctx, userID, id); err != nil {
+		return err
+	}
+	s.Stats.Invalidate(ctx, userID)
+	return nil
+}
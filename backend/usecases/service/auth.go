@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/usecases/validate"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// phoneLike matches identifiers that should be looked up as a phone number
+var phoneLike = regexp.MustCompile(`^\+?[0-9][0-9 ()-]*$`)
+
+// dummyHash is compared against when the user doesn't exist so that unknown
+// identifiers take as long to reject as wrong passwords
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("ecomind-dummy-password"), bcrypt.DefaultCost)
+
+// AuthService verifies passwords and locks accounts after repeated failures.
+type AuthService struct {
+	Users         domain.UserService
+	Attempts      domain.LoginAttemptService
+	MaxAttempts   int
+	LockoutWindow time.Duration
+	PhoneRegion   string // region of phone numbers without a country code
+}
+
+// NewAuthService creates a new authentication service instance.
+// Panics if a repository is nil.
+func NewAuthService(users domain.UserService, attempts domain.LoginAttemptService, maxAttempts int, lockoutWindow time.Duration, phoneRegion string) *AuthService {
+	if users == nil || attempts == nil {
+		panic("repositories must not be nil")
+	}
+	return &AuthService{
+		Users:         users,
+		Attempts:      attempts,
+		MaxAttempts:   maxAttempts,
+		LockoutWindow: lockoutWindow,
+		PhoneRegion:   phoneRegion,
+	}
+}
+
+// findUser resolves a login, email or phone number to a user. Emails and
+// phone numbers are normalised the way they were when stored.
+func (s *AuthService) findUser(ctx context.Context, identifier string) (*domain.User, error) {
+	switch {
+	case strings.Contains(identifier, "@"):
+		return s.Users.GetByEmail(ctx, validate.NormalizeEmail(identifier))
+	case phoneLike.MatchString(identifier):
+		if phone, ok := validate.NormalizePhone(identifier, s.PhoneRegion); ok {
+			user, err := s.Users.GetByPhoneNumber(ctx, phone)
+			if !errors.Is(err, domain.ErrUserNotFound) {
+				return user, err
+			}
+		}
+		// Logins may be all digits too
+		return s.Users.Get(ctx, identifier)
+	default:
+		return s.Users.Get(ctx, identifier)
+	}
+}
+
+// Unlock forgets the user's failed sign-ins, lifting a lockout
+func (s *AuthService) Unlock(ctx context.Context, userID string) error {
+	return s.Attempts.Reset(ctx, "user:"+userID)
+}
+
+// Login checks a password for the user identified by login, email or phone
+// number. Every failure returns domain.ErrInvalidCredentials after the same
+// bcrypt work, or domain.ErrAccountLocked once MaxAttempts failures have been
+// recorded within LockoutWindow.
+func (s *AuthService) Login(ctx context.Context, identifier, password string) (*domain.User, error) {
+	user, err := s.findUser(ctx, identifier)
+	if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+		return nil, err
+	}
+
+	// Count failures per account so every identifier of a user shares one
+	// counter; unknown identifiers get their own so lockout can't reveal existence
+	attemptKey := "identifier:" + strings.ToLower(identifier)
+	if user != nil {
+		attemptKey = "user:" + user.ID
+	}
+	failures, err := s.Attempts.Failures(ctx, attemptKey)
+	if err != nil {
+		return nil, err
+	}
+	if failures >= s.MaxAttempts {
+		return nil, domain.ErrAccountLocked
+	}
+
+	hash := dummyHash
+	hasPassword := false
+	if user != nil {
+		stored, err := s.Users.GetPasswordHash(ctx, user.Login)
+		if err != nil && !errors.Is(err, domain.ErrUserNotFound) {
+			return nil, err
+		}
+		// Users who signed up through OAuth have no password
+		if stored != "" {
+			hash = []byte(stored)
+			hasPassword = true
+		}
+	}
+
+	match := bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil
+	if !match || !hasPassword {
+		if _, err := s.Attempts.RegisterFailure(ctx, attemptKey, s.LockoutWindow); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrInvalidCredentials
+	}
+
+	if err := s.Attempts.Reset(ctx, attemptKey); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
@@ -0,0 +1,143 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository/ram_storage"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+)
+
+const (
+	testPassword   = "Passw0rd-long"
+	testMaxLogins  = 3
+	wrongPassword  = "Wr0ng-password"
+	aliceEmail     = "alice@example.com"
+	alicePhone     = "+79001234567"
+	aliceNational  = "8 900 123-45-67"
+	unknownLogin   = "nobody"
+	oauthOnlyLogin = "tiktok_123"
+)
+
+// newAuthService builds an AuthService on in-memory storage with alice, who
+// has a password, and an OAuth-only user without one
+func newAuthService(t *testing.T) (*service.AuthService, *domain.User) {
+	t.Helper()
+	ctx := context.Background()
+	users := ram_storage.NewUserStorage()
+	alice, err := service.NewUserService(users, "RU").Create(ctx, "alice", aliceEmail, alicePhone, testPassword)
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := users.Create(ctx, &domain.User{ID: service.GenerateUserID(), Login: oauthOnlyLogin}); err != nil {
+		t.Fatalf("Create OAuth user: %v", err)
+	}
+	return service.NewAuthService(users, ram_storage.NewLoginAttemptStorage(), testMaxLogins, time.Hour, "RU"), alice
+}
+
+func TestAuthServiceLoginLocksAccount(t *testing.T) {
+	ctx := context.Background()
+	s, alice := newAuthService(t)
+
+	for i := 0; i < testMaxLogins; i++ {
+		if _, err := s.Login(ctx, "alice", wrongPassword); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Fatalf("wrong password %d error = %v, want ErrInvalidCredentials", i+1, err)
+		}
+	}
+	// Once locked, even the right password is refused
+	if _, err := s.Login(ctx, "alice", testPassword); !errors.Is(err, domain.ErrAccountLocked) {
+		t.Fatalf("Login when locked error = %v, want ErrAccountLocked", err)
+	}
+
+	if err := s.Unlock(ctx, alice.ID); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if user, err := s.Login(ctx, "alice", testPassword); err != nil || user.ID != alice.ID {
+		t.Errorf("Login after Unlock = %v, %v, want alice", user, err)
+	}
+}
+
+func TestAuthServiceLoginSharesCounterAcrossIdentifiers(t *testing.T) {
+	ctx := context.Background()
+	s, alice := newAuthService(t)
+	identifiers := []string{"alice", "Alice@Example.com", aliceNational}
+
+	// Every identifier signs alice in, normalised the way it was stored
+	for _, identifier := range identifiers {
+		if user, err := s.Login(ctx, identifier, testPassword); err != nil || user.ID != alice.ID {
+			t.Fatalf("Login(%q) = %v, %v, want alice", identifier, user, err)
+		}
+	}
+
+	// One failure through each of them uses up the account's attempts
+	for _, identifier := range identifiers {
+		if _, err := s.Login(ctx, identifier, wrongPassword); !errors.Is(err, domain.ErrInvalidCredentials) {
+			t.Fatalf("Login(%q) with a wrong password error = %v, want ErrInvalidCredentials", identifier, err)
+		}
+	}
+	for _, identifier := range append(identifiers, alicePhone) {
+		if _, err := s.Login(ctx, identifier, testPassword); !errors.Is(err, domain.ErrAccountLocked) {
+			t.Errorf("Login(%q) after %d failures error = %v, want ErrAccountLocked", identifier, testMaxLogins, err)
+		}
+	}
+}
+
+func TestAuthServiceLoginUnknownIdentifier(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newAuthService(t)
+
+	tests := []struct {
+		name       string
+		identifier string
+		password   string
+	}{
+		{"wrong password", "alice", wrongPassword},
+		{"unknown login", unknownLogin, testPassword},
+		{"unknown email", "nobody@example.com", testPassword},
+		{"unknown phone number", "+7 900 765-43-21", testPassword},
+		{"user without a password", oauthOnlyLogin, ""},
+	}
+	// Unknown identifiers fail and lock out exactly like a wrong password
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i := 0; i < testMaxLogins; i++ {
+				if _, err := s.Login(ctx, tt.identifier, tt.password); err != domain.ErrInvalidCredentials {
+					t.Fatalf("attempt %d error = %v, want ErrInvalidCredentials", i+1, err)
+				}
+			}
+			if _, err := s.Login(ctx, tt.identifier, tt.password); err != domain.ErrAccountLocked {
+				t.Errorf("attempt %d error = %v, want ErrAccountLocked", testMaxLogins+1, err)
+			}
+		})
+	}
+
+	// Their counters are their own: failing for "nobody" locked nobody else
+	s, alice := newAuthService(t)
+	for i := 0; i < testMaxLogins+1; i++ {
+		if _, err := s.Login(ctx, unknownLogin, wrongPassword); err == nil {
+			t.Fatal("Login of an unknown user succeeded")
+		}
+	}
+	if user, err := s.Login(ctx, "alice", testPassword); err != nil || user.ID != alice.ID {
+		t.Errorf("Login of alice after failures for another identifier = %v, %v, want alice", user, err)
+	}
+}
+
+func TestAuthServiceLoginResetsAfterSuccess(t *testing.T) {
+	ctx := context.Background()
+	s, _ := newAuthService(t)
+
+	for round := 0; round < 3; round++ {
+		for i := 0; i < testMaxLogins-1; i++ {
+			if _, err := s.Login(ctx, "alice", wrongPassword); !errors.Is(err, domain.ErrInvalidCredentials) {
+				t.Fatalf("round %d: wrong password error = %v, want ErrInvalidCredentials", round, err)
+			}
+		}
+		if _, err := s.Login(ctx, "alice", testPassword); err != nil {
+			t.Fatalf("round %d: Login one failure short of the limit: %v", round, err)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/aygoko/EcoMInd/backend/usecases/coach"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxCoachMessageLength bounds a question to the coach, in characters
+	maxCoachMessageLength = 1000
+	// coachHistoryTurns is how many earlier exchanges are passed along
+	coachHistoryTurns = 5
+	// coachSummaryWindow is how far back the summary sums logged activities
+	coachSummaryWindow = 30 * 24 * time.Hour
+	// coachSummaryRecommendations is how many recommendations the summary lists
+	coachSummaryRecommendations = 3
+)
+
+var (
+	ErrInvalidCoachMessage = errs.Validation("invalid coach message")
+	ErrCoachUnavailable    = errs.Upstream("the coach is unavailable, try again later")
+)
+
+// CoachService answers users' questions through a coaching provider. Every
+// exchange is logged with the prompt that produced it, including failed
+// ones, and users are limited to RateLimit messages per RateWindow.
+type CoachService struct {
+	Repo            domain.CoachService
+	Provider        coach.Provider
+	Limiter         domain.RateLimitService
+	Activities      domain.ActivityService
+	Goals           *GoalService
+	Recommendations *RecommendationService
+	RateLimit       int
+	RateWindow      time.Duration
+}
+
+// NewCoachService creates a new coach service instance.
+// Panics if any dependency is nil.
+func NewCoachService(
+	repo domain.CoachService,
+	provider coach.Provider,
+	limiter domain.RateLimitService,
+	activities domain.ActivityService,
+	goals *GoalService,
+	recommendations *RecommendationService,
+	rateLimit int,
+	rateWindow time.Duration,
+) *CoachService {
+	if repo == nil || provider == nil || limiter == nil || activities == nil || goals == nil || recommendations == nil {
+		panic("repositories must not be nil")
+	}
+	return &CoachService{
+		Repo:            repo,
+		Provider:        provider,
+		Limiter:         limiter,
+		Activities:      activities,
+		Goals:           goals,
+		Recommendations: recommendations,
+		RateLimit:       rateLimit,
+		RateWindow:      rateWindow,
+	}
+}
+
+// Ask sends the user's message to the coach and returns the logged
+// exchange. It returns a *domain.RateLimitError once the user used up their
+// messages for the window and ErrCoachUnavailable when the provider fails.
+func (s *CoachService) Ask(ctx context.Context, user *domain.User, message string, now time.Time) (*domain.CoachExchange, error) {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return nil, fmt.Errorf("%w: message is required", ErrInvalidCoachMessage)
+	}
+	if utf8.RuneCountInString(message) > maxCoachMessageLength {
+		return nil, fmt.Errorf("%w: message must be at most %d characters", ErrInvalidCoachMessage, maxCoachMessageLength)
+	}
+
+	count, resetAt, err := s.Limiter.Hit(ctx, "coach:"+user.ID, s.RateWindow)
+	if err != nil {
+		return nil, err
+	}
+	if count > s.RateLimit {
+		return nil, &domain.RateLimitError{RetryAfter: resetAt.Sub(now)}
+	}
+
+	summary, err := s.Summary(ctx, user, now)
+	if err != nil {
+		return nil, err
+	}
+	history, err := s.history(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	exchange := &domain.CoachExchange{
+		ID:       uuid.NewString(),
+		UserID:   user.ID,
+		Provider: s.Provider.Name(),
+		Message:  message,
+	}
+	started := time.Now()
+	reply, adviseErr := s.Provider.Advise(ctx, &coach.Request{Summary: summary, History: history, Message: message})
+	exchange.Duration = time.Since(started)
+	if adviseErr != nil {
+		log.Printf("coach provider %s failed for user %s: %v", exchange.Provider, user.ID, adviseErr)
+		exchange.Error = adviseErr.Error()
+		// Log the prompt the provider would have been given
+		exchange.Prompt, _ = coach.SystemPrompt(summary)
+	} else {
+		exchange.Reply = reply.Text
+		exchange.Prompt = reply.Prompt
+	}
+
+	if err := s.Repo.Create(ctx, exchange); err != nil {
+		return nil, err
+	}
+	if adviseErr != nil {
+		return nil, ErrCoachUnavailable
+	}
+	return exchange, nil
+}
+
+// History returns the user's exchanges with the coach, most recent first
+func (s *CoachService) History(ctx context.Context, userID string, limit int) ([]*domain.CoachExchange, error) {
+	exchanges, err := s.Repo.List(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	answered := make([]*domain.CoachExchange, 0, len(exchanges))
+	for _, exchange := range exchanges {
+		if exchange.Error == "" {
+			answered = append(answered, exchange)
+		}
+	}
+	return answered, nil
+}
+
+// history returns the latest answered exchanges as conversation turns,
+// oldest first
+func (s *CoachService) history(ctx context.Context, userID string) ([]domain.CoachMessage, error) {
+	exchanges, err := s.History(ctx, userID, coachHistoryTurns)
+	if err != nil {
+		return nil, err
+	}
+	messages := make([]domain.CoachMessage, 0, 2*len(exchanges))
+	for i := len(exchanges) - 1; i >= 0; i-- {
+		messages = append(messages,
+			domain.CoachMessage{Role: domain.CoachRoleUser, Content: exchanges[i].Message},
+			domain.CoachMessage{Role: domain.CoachRoleAssistant, Content: exchanges[i].Reply},
+		)
+	}
+	return messages, nil
+}
+
+// Summary gathers what the coach knows about the user's footprint
+func (s *CoachService) Summary(ctx context.Context, user *domain.User, now time.Time) (*domain.FootprintSummary, error) {
+	summary := &domain.FootprintSummary{
+		Name:           user.Login,
+		AnnualBaseline: user.CO2Baseline,
+		LoggedTotal:    user.CO2,
+		RecentByType:   map[string]float64{},
+		CO2Saved:       user.CO2Saved,
+		Points:         user.Points,
+	}
+
+	activities, err := s.Activities.List(ctx, user.ID, domain.ActivityFilter{From: now.Add(-coachSummaryWindow), To: now})
+	if err != nil {
+		return nil, err
+	}
+	for _, activity := range activities {
+		summary.RecentByType[activity.Category] += activity.CO2e
+	}
+
+	goal, err := s.Goals.Current(ctx, user.ID, now)
+	switch {
+	case err == nil:
+		summary.Goal = goal
+	case !errors.Is(err, domain.ErrGoalNotFound):
+		return nil, err
+	}
+
+	recommendations, err := s.Recommendations.For(ctx, user.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(recommendations) > coachSummaryRecommendations {
+		recommendations = recommendations[:coachSummaryRecommendations]
+	}
+	summary.Recommendations = recommendations
+	return summary, nil
+}
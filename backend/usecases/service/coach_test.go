@@ -0,0 +1,190 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/config"
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository/ram_storage"
+	"github.com/aygoko/EcoMInd/backend/usecases/coach"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+)
+
+// failingProvider is a coaching provider whose upstream is down
+type failingProvider struct{}
+
+func (failingProvider) Name() string { return "failing" }
+
+func (failingProvider) Advise(context.Context, *coach.Request) (*coach.Reply, error) {
+	return nil, errors.New("upstream timed out")
+}
+
+// newCoachService builds a CoachService on in-memory storage with a signed-up
+// user. A nil provider selects the local one.
+func newCoachService(t *testing.T, provider coach.Provider, rateLimit int) (*service.CoachService, *domain.User) {
+	t.Helper()
+	if provider == nil {
+		var err error
+		provider, err = coach.New(config.CoachConfig{Provider: "local"})
+		if err != nil {
+			t.Fatalf("coach.New: %v", err)
+		}
+	}
+
+	users := ram_storage.NewUserStorage()
+	activities := ram_storage.NewActivityStorage(users)
+	goals := service.NewGoalService(ram_storage.NewGoalStorage(), users, activities, ram_storage.NewTaskStorage(users))
+	recommendations := service.NewRecommendationService(ram_storage.NewRecommendationStorage(), ram_storage.NewSurveyResponseStorage(users), activities)
+	coachService := service.NewCoachService(
+		ram_storage.NewCoachStorage(),
+		provider,
+		ram_storage.NewRateLimitStorage(),
+		activities,
+		goals,
+		recommendations,
+		rateLimit,
+		time.Hour,
+	)
+
+	user := &domain.User{ID: service.GenerateUserID(), Login: "alice"}
+	if err := users.Create(context.Background(), user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	return coachService, user
+}
+
+func TestCoachServiceAskLogsExchange(t *testing.T) {
+	ctx := context.Background()
+	s, user := newCoachService(t, nil, 10)
+
+	exchange, err := s.Ask(ctx, user, "  How do I drive less?  ", time.Now())
+	if err != nil {
+		t.Fatalf("Ask: %v", err)
+	}
+	if exchange.Reply == "" {
+		t.Error("Ask returned an empty reply")
+	}
+
+	logged, err := s.Repo.List(ctx, user.ID, 10)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(logged) != 1 {
+		t.Fatalf("logged %d exchanges, want 1", len(logged))
+	}
+	got := logged[0]
+	if got.Provider != "local" {
+		t.Errorf("Provider = %q, want local", got.Provider)
+	}
+	if got.Message != "How do I drive less?" {
+		t.Errorf("Message = %q, want the trimmed question", got.Message)
+	}
+	if got.Reply != exchange.Reply {
+		t.Errorf("logged Reply = %q, want %q", got.Reply, exchange.Reply)
+	}
+	if !strings.Contains(got.Prompt, "Footprint summary") || !strings.Contains(got.Prompt, `"alice"`) {
+		t.Errorf("logged Prompt doesn't hold the user's footprint summary:\n%s", got.Prompt)
+	}
+	if got.Error != "" {
+		t.Errorf("Error = %q, want none", got.Error)
+	}
+}
+
+func TestCoachServiceAskLogsProviderFailure(t *testing.T) {
+	ctx := context.Background()
+	s, user := newCoachService(t, failingProvider{}, 10)
+
+	if _, err := s.Ask(ctx, user, "How do I drive less?", time.Now()); !errors.Is(err, service.ErrCoachUnavailable) {
+		t.Fatalf("Ask error = %v, want ErrCoachUnavailable", err)
+	}
+
+	logged, err := s.Repo.List(ctx, user.ID, 10)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(logged) != 1 {
+		t.Fatalf("logged %d exchanges, want 1", len(logged))
+	}
+	if logged[0].Error != "upstream timed out" || logged[0].Reply != "" {
+		t.Errorf("logged Error = %q, Reply = %q, want the provider's error and no reply", logged[0].Error, logged[0].Reply)
+	}
+	if !strings.Contains(logged[0].Prompt, "Footprint summary") {
+		t.Errorf("logged Prompt = %q, want the prompt the provider would have been given", logged[0].Prompt)
+	}
+
+	history, err := s.History(ctx, user.ID, 10)
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	if len(history) != 0 {
+		t.Errorf("History has %d exchanges, want failed ones left out", len(history))
+	}
+}
+
+func TestCoachServiceAskRateLimit(t *testing.T) {
+	ctx := context.Background()
+	s, user := newCoachService(t, nil, 2)
+
+	for i := 0; i < 2; i++ {
+		if _, err := s.Ask(ctx, user, "What should I do next?", time.Now()); err != nil {
+			t.Fatalf("Ask %d: %v", i+1, err)
+		}
+	}
+	_, err := s.Ask(ctx, user, "What should I do next?", time.Now())
+	var rateLimitErr *domain.RateLimitError
+	if !errors.As(err, &rateLimitErr) {
+		t.Fatalf("Ask over the limit error = %v, want *domain.RateLimitError", err)
+	}
+	if rateLimitErr.RetryAfter <= 0 || rateLimitErr.RetryAfter > time.Hour {
+		t.Errorf("RetryAfter = %s, want within the hour window", rateLimitErr.RetryAfter)
+	}
+
+	logged, err := s.Repo.List(ctx, user.ID, 10)
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(logged) != 2 {
+		t.Errorf("logged %d exchanges, want the rejected message left out", len(logged))
+	}
+
+	other := &domain.User{ID: service.GenerateUserID(), Login: "bob"}
+	if _, err := s.Ask(ctx, other, "What should I do next?", time.Now()); err != nil {
+		t.Errorf("Ask by another user: %v, want their own limit", err)
+	}
+}
+
+func TestCoachServiceAskRejectsInvalidMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+	}{
+		{"empty", ""},
+		{"blank", "  \n\t "},
+		{"too long", strings.Repeat("é", 1001)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s, user := newCoachService(t, nil, 1)
+
+			if _, err := s.Ask(ctx, user, tt.message, time.Now()); !errors.Is(err, service.ErrInvalidCoachMessage) {
+				t.Fatalf("Ask error = %v, want ErrInvalidCoachMessage", err)
+			}
+			logged, err := s.Repo.List(ctx, user.ID, 10)
+			if err != nil {
+				t.Fatalf("List: %v", err)
+			}
+			if len(logged) != 0 {
+				t.Errorf("logged %d exchanges, want none", len(logged))
+			}
+			// A rejected message doesn't use up the user's only message
+			if _, err := s.Ask(ctx, user, "Any tips?", time.Now()); err != nil {
+				t.Errorf("Ask after a rejected message: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidFactorCatalogue is wrapped by every import validation error
+var ErrInvalidFactorCatalogue = errs.Validation("invalid emission factor catalogue")
+
+var (
+	factorCategory = regexp.MustCompile(`^[a-z0-9_]+$`)
+	regionCode     = regexp.MustCompile(`^[A-Z]{2}$`)
+)
+
+// DefaultEmissionFactors are the built-in factors, in kg CO2e per unit,
+// published as the first catalogue version of a fresh installation
+var DefaultEmissionFactors = []domain.EmissionFactor{
+	{Category: "car_petrol", Unit: "km", Factor: 0.170, Source: "DEFRA 2023"},
+	{Category: "car_diesel", Unit: "km", Factor: 0.171, Source: "DEFRA 2023"},
+	{Category: "car_electric", Unit: "km", Factor: 0.047, Source: "DEFRA 2023"},
+	{Category: "motorbike", Unit: "km", Factor: 0.114, Source: "DEFRA 2023"},
+	{Category: "bus", Unit: "km", Factor: 0.097, Source: "DEFRA 2023"},
+	{Category: "train", Unit: "km", Factor: 0.035, Source: "DEFRA 2023"},
+	{Category: "flight_short_haul", Unit: "km", Factor: 0.151, Source: "DEFRA 2023"},
+	{Category: "flight_long_haul", Unit: "km", Factor: 0.148, Source: "DEFRA 2023"},
+	{Category: "electricity", Unit: "kWh", Factor: 0.207, Source: "DEFRA 2023"},
+	{Category: "natural_gas", Unit: "kWh", Factor: 0.183, Source: "DEFRA 2023"},
+	{Category: "meal_beef", Unit: "meal", Factor: 7.7, Source: "Poore & Nemecek 2018"},
+	{Category: "meal_chicken", Unit: "meal", Factor: 1.8, Source: "Poore & Nemecek 2018"},
+	{Category: "meal_vegetarian", Unit: "meal", Factor: 1.0, Source: "Poore & Nemecek 2018"},
+	{Category: "meal_vegan", Unit: "meal", Factor: 0.7, Source: "Poore & Nemecek 2018"},
+}
+
+// EmissionFactorService manages the versioned emission-factor catalogue.
+// New factors are imported as a draft version and take effect when an
+// admin publishes it; activities keep the factor they were computed with.
+type EmissionFactorService struct {
+	Repo domain.EmissionFactorService
+}
+
+// NewEmissionFactorService creates a new emission factor service instance.
+// Panics if the provided repository is nil.
+func NewEmissionFactorService(repo domain.EmissionFactorService) *EmissionFactorService {
+	if repo == nil {
+		panic("repository must not be nil")
+	}
+	return &EmissionFactorService{Repo: repo}
+}
+
+// SeedDefaults publishes DefaultEmissionFactors as the first version when
+// the catalogue is empty. It reports whether it did.
+func (s *EmissionFactorService) SeedDefaults(ctx context.Context) (bool, error) {
+	versions, err := s.Repo.ListVersions(ctx)
+	if err != nil || len(versions) > 0 {
+		return false, err
+	}
+	factors := make([]*domain.EmissionFactor, len(DefaultEmissionFactors))
+	for i := range DefaultEmissionFactors {
+		factor := DefaultEmissionFactors[i]
+		factors[i] = &factor
+	}
+	version, err := s.Import(ctx, "built-in defaults", factors)
+	if err != nil {
+		return false, err
+	}
+	return true, s.Repo.Publish(ctx, version.Version)
+}
+
+// Import validates factors and stores them as a new draft version. Regions
+// are upper-cased. It returns an error wrapping ErrInvalidFactorCatalogue
+// when the factors are inconsistent.
+func (s *EmissionFactorService) Import(ctx context.Context, note string, factors []*domain.EmissionFactor) (*domain.EmissionFactorVersion, error) {
+	if err := validateFactors(factors); err != nil {
+		return nil, err
+	}
+	for _, factor := range factors {
+		factor.ID = uuid.NewString()
+	}
+	version := &domain.EmissionFactorVersion{Note: note}
+	if err := s.Repo.CreateVersion(ctx, version, factors); err != nil {
+		return nil, err
+	}
+	return version, nil
+}
+
+// validateFactors checks every factor and that periods of one category and
+// region don't overlap, so resolution is unambiguous
+func validateFactors(factors []*domain.EmissionFactor) error {
+	if len(factors) == 0 {
+		return fmt.Errorf("%w: no factors", ErrInvalidFactorCatalogue)
+	}
+	units := make(map[string]string)
+	periods := make(map[string][]*domain.EmissionFactor)
+	for i, factor := range factors {
+		factor.Region = strings.ToUpper(factor.Region)
+		switch {
+		case !factorCategory.MatchString(factor.Category):
+			return fmt.Errorf("%w: factor %d: category %q must be lower-case letters, digits or _", ErrInvalidFactorCatalogue, i+1, factor.Category)
+		case factor.Unit == "":
+			return fmt.Errorf("%w: factor %d: unit is required", ErrInvalidFactorCatalogue, i+1)
+		case factor.Factor < 0:
+			return fmt.Errorf("%w: factor %d: factor must not be negative", ErrInvalidFactorCatalogue, i+1)
+		case factor.Region != "" && !regionCode.MatchString(factor.Region):
+			return fmt.Errorf("%w: factor %d: region %q must be an ISO 3166-1 alpha-2 code", ErrInvalidFactorCatalogue, i+1, factor.Region)
+		case factor.ValidFrom != nil && factor.ValidTo != nil && !factor.ValidTo.After(*factor.ValidFrom):
+			return fmt.Errorf("%w: factor %d: valid_to must be after valid_from", ErrInvalidFactorCatalogue, i+1)
+		}
+		// Overrides must use the category's unit or quantities would be misread
+		if unit, ok := units[factor.Category]; ok && unit != factor.Unit {
+			return fmt.Errorf("%w: factor %d: %s uses unit %q but was %q before", ErrInvalidFactorCatalogue, i+1, factor.Category, factor.Unit, unit)
+		}
+		units[factor.Category] = factor.Unit
+
+		key := factor.Category + "/" + factor.Region
+		for _, other := range periods[key] {
+			if overlaps(factor, other) {
+				return fmt.Errorf("%w: factor %d: %s overlaps another factor for the same region and period", ErrInvalidFactorCatalogue, i+1, factor.Category)
+			}
+		}
+		periods[key] = append(periods[key], factor)
+	}
+	return nil
+}
+
+// overlaps reports whether the validity periods of a and b intersect
+func overlaps(a, b *domain.EmissionFactor) bool {
+	aStartsBeforeBEnds := a.ValidFrom == nil || b.ValidTo == nil || a.ValidFrom.Before(*b.ValidTo)
+	bStartsBeforeAEnds := b.ValidFrom == nil || a.ValidTo == nil || b.ValidFrom.Before(*a.ValidTo)
+	return aStartsBeforeBEnds && bStartsBeforeAEnds
+}
+
+// Publish makes a draft version current.
+func (s *EmissionFactorService) Publish(ctx context.Context, version int) error {
+	return s.Repo.Publish(ctx, version)
+}
+
+// Resolve returns the factor of the current version for category that
+// applies in region at t, preferring a regional override over the default.
+// It returns domain.ErrEmissionFactorNotFound when none applies.
+func (s *EmissionFactorService) Resolve(ctx context.Context, category, region string, t time.Time) (*domain.EmissionFactor, error) {
+	current, err := s.Repo.CurrentVersion(ctx)
+	if errors.Is(err, domain.ErrFactorVersionNotFound) {
+		return nil, domain.ErrEmissionFactorNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	factors, err := s.Repo.FindFactors(ctx, current.Version, category)
+	if err != nil {
+		return nil, err
+	}
+
+	var fallback *domain.EmissionFactor
+	for _, factor := range factors {
+		if !factor.AppliesAt(t) {
+			continue
+		}
+		if region != "" && factor.Region == region {
+			return factor, nil
+		}
+		if factor.Region == "" {
+			fallback = factor
+		}
+	}
+	if fallback == nil {
+		return nil, domain.ErrEmissionFactorNotFound
+	}
+	return fallback, nil
+}
+
+// Current returns the current version and its factors.
+func (s *EmissionFactorService) Current(ctx context.Context) (*domain.EmissionFactorVersion, []*domain.EmissionFactor, error) {
+	version, err := s.Repo.CurrentVersion(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	factors, err := s.Repo.ListFactors(ctx, version.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return version, factors, nil
+}
+
+// GetVersion returns a version and its factors.
+func (s *EmissionFactorService) GetVersion(ctx context.Context, number int) (*domain.EmissionFactorVersion, []*domain.EmissionFactor, error) {
+	version, err := s.Repo.GetVersion(ctx, number)
+	if err != nil {
+		return nil, nil, err
+	}
+	factors, err := s.Repo.ListFactors(ctx, version.Version)
+	if err != nil {
+		return nil, nil, err
+	}
+	return version, factors, nil
+}
+
+// ListVersions returns every version, newest first.
+func (s *EmissionFactorService) ListVersions(ctx context.Context) ([]*domain.EmissionFactorVersion, error) {
+	return s.Repo.ListVersions(ctx)
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// factorRecord is one factor as written in an import file. Times are dates
+// (2006-01-02) or RFC 3339 timestamps; empty means unbounded.
+type factorRecord struct {
+	Category  string  `json:"category"`
+	Unit      string  `json:"unit"`
+	Factor    float64 `json:"factor"`
+	Source    string  `json:"source"`
+	Region    string  `json:"region"`
+	ValidFrom string  `json:"valid_from"`
+	ValidTo   string  `json:"valid_to"`
+}
+
+// csvColumns are the columns an import CSV may have; the first three are required
+var csvColumns = []string{"category", "unit", "factor", "source", "region", "valid_from", "valid_to"}
+
+// ParseEmissionFactors reads an import file in format "csv" or "json". A CSV
+// file starts with a header naming its columns; a JSON file is an array of
+// objects with the same keys.
+func ParseEmissionFactors(r io.Reader, format string) ([]*domain.EmissionFactor, error) {
+	var records []factorRecord
+	switch format {
+	case "csv":
+		var err error
+		if records, err = readFactorCSV(r); err != nil {
+			return nil, err
+		}
+	case "json":
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&records); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFactorCatalogue, err)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q (want csv or json)", ErrInvalidFactorCatalogue, format)
+	}
+
+	factors := make([]*domain.EmissionFactor, 0, len(records))
+	for i, record := range records {
+		factor, err := record.factor()
+		if err != nil {
+			return nil, fmt.Errorf("%w: factor %d: %v", ErrInvalidFactorCatalogue, i+1, err)
+		}
+		factors = append(factors, factor)
+	}
+	return factors, nil
+}
+
+func readFactorCSV(r io.Reader) ([]factorRecord, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header: %v", ErrInvalidFactorCatalogue, err)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		known := false
+		for _, column := range csvColumns {
+			known = known || column == name
+		}
+		if !known {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidFactorCatalogue, name)
+		}
+		index[name] = i
+	}
+	for _, column := range csvColumns[:3] {
+		if _, ok := index[column]; !ok {
+			return nil, fmt.Errorf("%w: missing column %q", ErrInvalidFactorCatalogue, column)
+		}
+	}
+
+	var records []factorRecord
+	for {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidFactorCatalogue, err)
+		}
+		get := func(column string) string {
+			if i, ok := index[column]; ok {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+		line, _ := reader.FieldPos(0)
+		factor, err := strconv.ParseFloat(get("factor"), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: factor %q is not a number", ErrInvalidFactorCatalogue, line, get("factor"))
+		}
+		records = append(records, factorRecord{
+			Category:  get("category"),
+			Unit:      get("unit"),
+			Factor:    factor,
+			Source:    get("source"),
+			Region:    get("region"),
+			ValidFrom: get("valid_from"),
+			ValidTo:   get("valid_to"),
+		})
+	}
+}
+
+func (r factorRecord) factor() (*domain.EmissionFactor, error) {
+	validFrom, err := parseFactorTime(r.ValidFrom)
+	if err != nil {
+		return nil, fmt.Errorf("valid_from: %v", err)
+	}
+	validTo, err := parseFactorTime(r.ValidTo)
+	if err != nil {
+		return nil, fmt.Errorf("valid_to: %v", err)
+	}
+	return &domain.EmissionFactor{
+		Category:  r.Category,
+		Unit:      r.Unit,
+		Factor:    r.Factor,
+		Source:    r.Source,
+		Region:    r.Region,
+		ValidFrom: validFrom,
+		ValidTo:   validTo,
+	}, nil
+}
+
+func parseFactorTime(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		if t, err = time.Parse(time.RFC3339, s); err != nil {
+			return nil, fmt.Errorf("%q is neither a date nor an RFC 3339 time", s)
+		}
+	}
+	return &t, nil
+}
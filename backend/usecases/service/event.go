@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+// EventBus delivers domain events to its handlers synchronously, in the
+// order they subscribed. The change behind an event is already stored when
+// it's published, so handler failures are logged rather than returned.
+type EventBus struct {
+	handlers []domain.EventHandler
+}
+
+// NewEventBus creates an event bus without handlers
+func NewEventBus() *EventBus {
+	return &EventBus{}
+}
+
+// Subscribe adds a handler. Subscribe everything before serving requests;
+// the bus isn't safe for subscribing concurrently with publishing.
+func (b *EventBus) Subscribe(handler domain.EventHandler) {
+	b.handlers = append(b.handlers, handler)
+}
+
+// Publish hands event to every handler. A nil bus drops events.
+func (b *EventBus) Publish(ctx context.Context, event domain.Event) {
+	if b == nil {
+		return
+	}
+	for _, handler := range b.handlers {
+		if err := handler.Handle(ctx, event); err != nil {
+			log.Printf("Failed to handle %s event of user %s: %v", event.Type, event.UserID, err)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidGoal is wrapped by every goal validation error
+var ErrInvalidGoal = errs.Validation("invalid goal")
+
+const (
+	// goalStep moves a proposed target up after an achieved week and down
+	// after a missed one
+	goalStep = 0.1
+	// minGoalTarget and maxGoalTarget bound weekly targets, in kg CO2e
+	minGoalTarget = 1.0
+	maxGoalTarget = 1000.0
+	// rolloverBatch is how many ended goals Rollover loads at a time
+	rolloverBatch = 100
+)
+
+// GoalService manages weekly CO2 reduction goals. Progress is computed from
+// the activities logged and tasks completed within the goal's ISO week in
+// the user's timezone.
+type GoalService struct {
+	Repo       domain.GoalService
+	Users      domain.UserService
+	Activities domain.ActivityService
+	Tasks      domain.TaskService
+	// Events receives EventGoalClosed; while nil nothing is published
+	Events *EventBus
+}
+
+// NewGoalService creates a new weekly goal service instance.
+// Panics if any repository is nil.
+func NewGoalService(repo domain.GoalService, users domain.UserService, activities domain.ActivityService, tasks domain.TaskService) *GoalService {
+	if repo == nil || users == nil || activities == nil || tasks == nil {
+		panic("repositories must not be nil")
+	}
+	return &GoalService{
+		Repo:       repo,
+		Users:      users,
+		Activities: activities,
+		Tasks:      tasks,
+	}
+}
+
+// ISOWeek returns the ISO week containing t in loc, like 2026-W42, with its
+// bounds: Monday 00:00 inclusive to the next Monday exclusive
+func ISOWeek(t time.Time, loc *time.Location) (week string, start, end time.Time) {
+	t = t.In(loc)
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	start = time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, loc)
+	year, number := start.ISOWeek()
+	return fmt.Sprintf("%04d-W%02d", year, number), start, start.AddDate(0, 0, 7)
+}
+
+// parseISOWeek returns the bounds of an ISO week like 2026-W42 in loc
+func parseISOWeek(week string, loc *time.Location) (start, end time.Time, err error) {
+	var year, number int
+	if _, err := fmt.Sscanf(week, "%4d-W%2d", &year, &number); err != nil {
+		return start, end, fmt.Errorf("%w: week must look like 2026-W42", ErrInvalidGoal)
+	}
+	// January 4th always falls in week 1
+	_, start, _ = ISOWeek(time.Date(year, time.January, 4, 12, 0, 0, 0, loc), loc)
+	start = start.AddDate(0, 0, 7*(number-1))
+	if key, _, _ := ISOWeek(start, loc); key != week {
+		return start, end, fmt.Errorf("%w: %s is not a week of %d", ErrInvalidGoal, week, year)
+	}
+	return start, start.AddDate(0, 0, 7), nil
+}
+
+// Set sets the user's target for this or next week, "current" or empty
+// meaning this week in timezone. A new goal's timezone defaults to UTC and a missing
+// target to a tenth of the user's weekly baseline. Confirming a proposed
+// goal this way makes it the user's own.
+func (s *GoalService) Set(ctx context.Context, userID, week, timezone string, target *float64, now time.Time) (*domain.WeeklyGoal, error) {
+	if target != nil && (*target < minGoalTarget || *target > maxGoalTarget) {
+		return nil, fmt.Errorf("%w: target_co2 must be between %g and %g kg", ErrInvalidGoal, minGoalTarget, maxGoalTarget)
+	}
+	if timezone == "" {
+		timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidGoal, timezone)
+	}
+
+	var start, end time.Time
+	if week == "" || week == "current" {
+		week, start, end = ISOWeek(now, loc)
+	} else if start, end, err = parseISOWeek(week, loc); err != nil {
+		return nil, err
+	}
+	if start.After(now.AddDate(0, 0, 7)) {
+		return nil, fmt.Errorf("%w: goals can only be set for this week or the next", ErrInvalidGoal)
+	}
+
+	goal, err := s.Repo.Get(ctx, userID, week)
+	switch {
+	case err == nil:
+		if goal.Status != domain.GoalActive || !goal.EndsAt.After(now) {
+			return nil, domain.ErrGoalClosed
+		}
+		if target != nil {
+			goal.TargetCO2 = *target
+		}
+		goal.Proposed = false
+		if err := s.Repo.Update(ctx, goal); err != nil {
+			return nil, err
+		}
+	case errors.Is(err, domain.ErrGoalNotFound):
+		if !end.After(now) {
+			return nil, domain.ErrGoalClosed
+		}
+		user, err := s.Users.GetByID(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		goal = &domain.WeeklyGoal{
+			ID:        uuid.NewString(),
+			UserID:    userID,
+			Week:      week,
+			Timezone:  loc.String(),
+			StartsAt:  start,
+			EndsAt:    end,
+			TargetCO2: suggestedTarget(user),
+			Status:    domain.GoalActive,
+		}
+		if target != nil {
+			goal.TargetCO2 = *target
+		}
+		if err := s.Repo.Create(ctx, goal); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, err
+	}
+	return goal, s.fillProgress(ctx, goal)
+}
+
+// Get returns the user's goal for an ISO week
+func (s *GoalService) Get(ctx context.Context, userID, week string) (*domain.WeeklyGoal, error) {
+	goal, err := s.Repo.Get(ctx, userID, week)
+	if err != nil {
+		return nil, err
+	}
+	return goal, s.fillProgress(ctx, goal)
+}
+
+// Current returns the user's goal for the week containing now
+func (s *GoalService) Current(ctx context.Context, userID string, now time.Time) (*domain.WeeklyGoal, error) {
+	// Goals reach at most a week ahead, so the current goal is among the
+	// latest few even when the user changed timezone
+	goals, err := s.Repo.List(ctx, userID, 3)
+	if err != nil {
+		return nil, err
+	}
+	for _, goal := range goals {
+		if !goal.StartsAt.After(now) && goal.EndsAt.After(now) {
+			return goal, s.fillProgress(ctx, goal)
+		}
+	}
+	return nil, domain.ErrGoalNotFound
+}
+
+// History returns the user's goals, most recent week first
+func (s *GoalService) History(ctx context.Context, userID string, limit int) ([]*domain.WeeklyGoal, error) {
+	goals, err := s.Repo.List(ctx, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, goal := range goals {
+		if err := s.fillProgress(ctx, goal); err != nil {
+			return nil, err
+		}
+	}
+	return goals, nil
+}
+
+// Rollover closes every active goal whose week ended by now, recording its
+// result, and proposes a goal for the user's next week. It returns how many
+// goals it closed.
+func (s *GoalService) Rollover(ctx context.Context, now time.Time) (int, error) {
+	closed := 0
+	for {
+		due, err := s.Repo.ListDue(ctx, now, rolloverBatch)
+		if err != nil {
+			return closed, err
+		}
+		for _, goal := range due {
+			if err := s.close(ctx, goal, now); err != nil {
+				return closed, fmt.Errorf("closing goal %s: %w", goal.ID, err)
+			}
+			closed++
+		}
+		if len(due) < rolloverBatch {
+			return closed, nil
+		}
+	}
+}
+
+// close records a goal's final progress and proposes the next one
+func (s *GoalService) close(ctx context.Context, goal *domain.WeeklyGoal, now time.Time) error {
+	progress, err := s.progress(ctx, goal)
+	if err != nil {
+		return err
+	}
+	goal.Progress = progress
+	goal.Status = domain.GoalMissed
+	if progress.Reduction >= goal.TargetCO2 {
+		goal.Status = domain.GoalAchieved
+	}
+	goal.ClosedAt = &now
+	if err := s.Repo.Update(ctx, goal); err != nil {
+		return err
+	}
+
+	if _, err := s.Users.GetByID(ctx, goal.UserID); errors.Is(err, domain.ErrUserNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	s.Events.Publish(ctx, domain.Event{Type: domain.EventGoalClosed, UserID: goal.UserID, At: now})
+
+	loc, err := time.LoadLocation(goal.Timezone)
+	if err != nil {
+		return err
+	}
+	// After downtime, skip straight to the current week instead of
+	// proposing goals for weeks that are already over
+	next := goal.EndsAt
+	if !next.AddDate(0, 0, 7).After(now) {
+		next = now
+	}
+	week, start, end := ISOWeek(next, loc)
+
+	target := goal.TargetCO2 * (1 - goalStep)
+	if goal.Status == domain.GoalAchieved {
+		target = goal.TargetCO2 * (1 + goalStep)
+	}
+	proposal := &domain.WeeklyGoal{
+		ID:        uuid.NewString(),
+		UserID:    goal.UserID,
+		Week:      week,
+		Timezone:  goal.Timezone,
+		StartsAt:  start,
+		EndsAt:    end,
+		TargetCO2: math.Min(maxGoalTarget, math.Max(minGoalTarget, math.Round(target*10)/10)),
+		Proposed:  true,
+		Status:    domain.GoalActive,
+	}
+	// The user may have set next week's goal already
+	if err := s.Repo.Create(ctx, proposal); err != nil && !errors.Is(err, domain.ErrGoalConflict) {
+		return err
+	}
+	return nil
+}
+
+// fillProgress computes live progress of an active goal; closed goals keep
+// the progress recorded when their week ended
+func (s *GoalService) fillProgress(ctx context.Context, goal *domain.WeeklyGoal) error {
+	if goal.Status != domain.GoalActive {
+		return nil
+	}
+	progress, err := s.progress(ctx, goal)
+	if err != nil {
+		return err
+	}
+	goal.Progress = progress
+	return nil
+}
+
+// progress measures the goal's week. Logged emissions are compared with the
+// week before rather than the survey baseline, which also covers what users
+// don't log, and only count when something was logged, since an empty week
+// says nothing about emissions.
+func (s *GoalService) progress(ctx context.Context, goal *domain.WeeklyGoal) (domain.GoalProgress, error) {
+	var progress domain.GoalProgress
+	activities, err := s.Activities.List(ctx, goal.UserID, domain.ActivityFilter{From: goal.StartsAt, To: goal.EndsAt})
+	if err != nil {
+		return progress, err
+	}
+	for _, activity := range activities {
+		progress.Emitted += activity.CO2e
+	}
+	previous, err := s.Activities.List(ctx, goal.UserID, domain.ActivityFilter{From: goal.StartsAt.AddDate(0, 0, -7), To: goal.StartsAt})
+	if err != nil {
+		return progress, err
+	}
+	for _, activity := range previous {
+		progress.PrevEmitted += activity.CO2e
+	}
+	assignments, err := s.Tasks.ListAssignments(ctx, goal.UserID, domain.TaskCompleted)
+	if err != nil {
+		return progress, err
+	}
+	for _, assignment := range assignments {
+		if completedAt := assignment.CompletedAt; !completedAt.Before(goal.StartsAt) && completedAt.Before(goal.EndsAt) {
+			progress.SavedByTasks += assignment.CO2Saved
+		}
+	}
+
+	progress.Reduction = progress.SavedByTasks
+	if len(activities) > 0 && progress.PrevEmitted > progress.Emitted {
+		progress.Reduction += progress.PrevEmitted - progress.Emitted
+	}
+	progress.Emitted = math.Round(progress.Emitted*1000) / 1000
+	progress.PrevEmitted = math.Round(progress.PrevEmitted*1000) / 1000
+	progress.SavedByTasks = math.Round(progress.SavedByTasks*1000) / 1000
+	progress.Reduction = math.Round(progress.Reduction*1000) / 1000
+	progress.Percent = math.Min(100, math.Round(progress.Reduction/goal.TargetCO2*1000)/10)
+	return progress, nil
+}
+
+// suggestedTarget proposes saving a tenth of the user's survey baseline
+// spread over the weeks of a year
+func suggestedTarget(user *domain.User) float64 {
+	return math.Max(minGoalTarget, math.Round(user.CO2Baseline/52*goalStep*10)/10)
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+)
+
+// Leaderboard scopes and periods
+const (
+	ScopeGlobal  = "global"
+	ScopeFriends = "friends"
+	PeriodAll    = "all"
+	PeriodWeek   = "week"
+)
+
+// ErrInvalidLeaderboard is returned for unknown scopes, periods or metrics
+var ErrInvalidLeaderboard = errs.Validation("invalid leaderboard")
+
+// LeaderboardService ranks users by the points and CO2 savings of the tasks
+// they complete, over all time and per ISO week in UTC. Boards are updated
+// as tasks are completed and can be rebuilt from the task history.
+type LeaderboardService struct {
+	Boards domain.LeaderboardService
+	Users  domain.UserService
+	Tasks  domain.TaskService
+	// Friends lists whom a user is compared with on the friends board;
+	// while nil that board only holds the user
+	Friends domain.FriendLister
+	// Privacy decides whose CO2 savings the viewer sees; while nil only
+	// their own
+	Privacy domain.PrivacyReader
+}
+
+// NewLeaderboardService creates a new leaderboard service instance.
+// Panics if any repository is nil.
+func NewLeaderboardService(boards domain.LeaderboardService, users domain.UserService, tasks domain.TaskService) *LeaderboardService {
+	if boards == nil || users == nil || tasks == nil {
+		panic("repositories must not be nil")
+	}
+	return &LeaderboardService{
+		Boards: boards,
+		Users:  users,
+		Tasks:  tasks,
+	}
+}
+
+// Record adds a completed task to the all-time boards and those of the week
+// it was completed in
+func (s *LeaderboardService) Record(ctx context.Context, assignment *domain.TaskAssignment) error {
+	week, _, _ := ISOWeek(*assignment.CompletedAt, time.UTC)
+	return s.Boards.Add(ctx, week, domain.UserAward{
+		UserID:   assignment.UserID,
+		Points:   assignment.Points,
+		CO2Saved: assignment.CO2Saved,
+	})
+}
+
+// Page returns a page of a leaderboard with the viewer's own standing.
+// Empty scope, period and metric default to global, all and points.
+func (s *LeaderboardService) Page(ctx context.Context, viewerID, scope, period, metric string, offset, limit int, now time.Time) (*domain.LeaderboardPage, error) {
+	board := domain.Board{Metric: metric}
+	switch board.Metric {
+	case "":
+		board.Metric = domain.MetricPoints
+	case domain.MetricPoints, domain.MetricCO2Saved:
+	default:
+		return nil, fmt.Errorf("%w: metric must be %s or %s", ErrInvalidLeaderboard, domain.MetricPoints, domain.MetricCO2Saved)
+	}
+	switch period {
+	case "", PeriodAll:
+	case PeriodWeek:
+		board.Week, _, _ = ISOWeek(now, time.UTC)
+	default:
+		return nil, fmt.Errorf("%w: period must be %s or %s", ErrInvalidLeaderboard, PeriodAll, PeriodWeek)
+	}
+
+	var page *domain.LeaderboardPage
+	var err error
+	switch scope {
+	case "", ScopeGlobal:
+		page, err = s.globalPage(ctx, viewerID, board, offset, limit)
+	case ScopeFriends:
+		page, err = s.friendsPage(ctx, viewerID, board, offset, limit)
+	default:
+		return nil, fmt.Errorf("%w: scope must be %s or %s", ErrInvalidLeaderboard, ScopeGlobal, ScopeFriends)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if page.Entries, err = s.named(ctx, viewerID, board.Metric, page.Entries); err != nil {
+		return nil, err
+	}
+	if page.Me != nil {
+		user, err := s.Users.GetByID(ctx, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		page.Me.Name = user.Login
+	}
+	return page, nil
+}
+
+func (s *LeaderboardService) globalPage(ctx context.Context, viewerID string, board domain.Board, offset, limit int) (*domain.LeaderboardPage, error) {
+	entries, total, err := s.Boards.Top(ctx, board, offset, limit)
+	if err != nil {
+		return nil, err
+	}
+	me, err := s.Boards.Standings(ctx, board, []string{viewerID})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.LeaderboardPage{Entries: entries, Total: total, Me: me[0]}, nil
+}
+
+// friendsPage ranks the viewer among their friends
+func (s *LeaderboardService) friendsPage(ctx context.Context, viewerID string, board domain.Board, offset, limit int) (*domain.LeaderboardPage, error) {
+	userIDs := []string{viewerID}
+	if s.Friends != nil {
+		friendIDs, err := s.Friends.FriendIDs(ctx, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		userIDs = append(userIDs, friendIDs...)
+	}
+	entries, err := s.Boards.Standings(ctx, board, userIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// Standings carry global ranks; order by the global rank and renumber
+	// so ties break the same way as on the global board
+	sort.SliceStable(entries, func(i, j int) bool {
+		if (entries[i].Rank == 0) != (entries[j].Rank == 0) {
+			return entries[j].Rank == 0
+		}
+		return entries[i].Rank < entries[j].Rank
+	})
+	page := &domain.LeaderboardPage{Total: int64(len(entries))}
+	for i, entry := range entries {
+		entry.Rank = int64(i + 1)
+		if entry.UserID == viewerID {
+			page.Me = entry
+		}
+	}
+	if offset < len(entries) {
+		entries = entries[offset:]
+		if len(entries) > limit {
+			entries = entries[:limit]
+		}
+		page.Entries = entries
+	}
+	return page, nil
+}
+
+// named fills in users' logins, dropping users who were deleted since they
+// scored; the next rebuild removes them from the boards. Users who don't
+// share their CO2 savings with the viewer are hidden on boards of metric.
+func (s *LeaderboardService) named(ctx context.Context, viewerID, metric string, entries []*domain.LeaderboardEntry) ([]*domain.LeaderboardEntry, error) {
+	privacy, err := newCO2Privacy(ctx, s.Privacy, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	named := make([]*domain.LeaderboardEntry, 0, len(entries))
+	for _, entry := range entries {
+		user, err := s.Users.GetByID(ctx, entry.UserID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		entry.Name = user.Login
+		shows, err := privacy.shows(ctx, entry.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !shows {
+			privacy.hide(entry, metric)
+		}
+		named = append(named, entry)
+	}
+	return named, nil
+}
+
+// Rebuild recomputes the all-time boards and those of the given number of
+// most recent weeks from completed tasks, for recovery after Redis data loss
+func (s *LeaderboardService) Rebuild(ctx context.Context, now time.Time, weeks int) error {
+	awards, err := s.Tasks.Awards(ctx, time.Time{}, time.Time{})
+	if err != nil {
+		return err
+	}
+	if err := s.Boards.Replace(ctx, "", awards); err != nil {
+		return err
+	}
+	for i := 0; i < weeks; i++ {
+		week, start, end := ISOWeek(now.AddDate(0, 0, -7*i), time.UTC)
+		awards, err := s.Tasks.Awards(ctx, start, end)
+		if err != nil {
+			return err
+		}
+		if err := s.Boards.Replace(ctx, week, awards); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/usecases/validate"
+	"golang.org/x/oauth2"
+)
+
+// OAuthService runs the server side of OAuth sign-in: it issues signed,
+// expiring, single-use state values bound to a PKCE verifier, and maps
+// provider accounts to users through linked identities.
+type OAuthService struct {
+	Users       domain.UserService
+	Identities  domain.IdentityService
+	States      domain.OAuthStateService
+	StateSecret []byte
+	StateTTL    time.Duration
+}
+
+// NewOAuthService creates a new OAuth service instance.
+// Panics if a repository is nil.
+func NewOAuthService(users domain.UserService, identities domain.IdentityService, states domain.OAuthStateService, stateSecret string, stateTTL time.Duration) *OAuthService {
+	if users == nil || identities == nil || states == nil {
+		panic("repositories must not be nil")
+	}
+	return &OAuthService{
+		Users:       users,
+		Identities:  identities,
+		States:      states,
+		StateSecret: []byte(stateSecret),
+		StateTTL:    stateTTL,
+	}
+}
+
+// linkTicketTTL bounds how long the client has to open a link ticket in the
+// browser
+const linkTicketTTL = time.Minute
+
+// sign returns the HMAC of a state payload
+func (s *OAuthService) sign(payload string) string {
+	mac := hmac.New(sha256.New, s.StateSecret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// signed returns a new value "prefix.nonce.expiry.signature" that expires
+// after ttl
+func (s *OAuthService) signed(prefix string, ttl time.Duration) (string, time.Time, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", time.Time{}, err
+	}
+	expiresAt := time.Now().Add(ttl)
+	payload := prefix + "." + base64.RawURLEncoding.EncodeToString(nonce) + "." + strconv.FormatInt(expiresAt.Unix(), 10)
+	return payload + "." + s.sign(payload), expiresAt, nil
+}
+
+// verify reports whether value was made by signed with prefix and hasn't
+// expired. It is checked before the store is consulted so forged values
+// never reach it.
+func (s *OAuthService) verify(prefix, value string) bool {
+	i := strings.LastIndexByte(value, '.')
+	if i < 0 {
+		return false
+	}
+	payload, signature := value[:i], value[i+1:]
+	if !hmac.Equal([]byte(signature), []byte(s.sign(payload))) {
+		return false
+	}
+	rest, ok := strings.CutPrefix(payload, prefix+".")
+	if !ok {
+		return false
+	}
+	parts := strings.Split(rest, ".")
+	if len(parts) != 2 {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	return err == nil && time.Now().Unix() < expiresAt
+}
+
+// Begin starts an authorization request for provider and returns the state
+// and PKCE verifier to send to it, and a binding for the browser to keep in
+// a cookie and present to Complete. linkUserID is the signed-in user when the
+// provider account should be linked rather than used to sign in.
+func (s *OAuthService) Begin(ctx context.Context, provider, linkUserID string) (*domain.OAuthState, string, error) {
+	value, expiresAt, err := s.signed(provider, s.StateTTL)
+	if err != nil {
+		return nil, "", err
+	}
+	binding := make([]byte, 32)
+	if _, err := rand.Read(binding); err != nil {
+		return nil, "", err
+	}
+	bindingString := base64.RawURLEncoding.EncodeToString(binding)
+
+	state := &domain.OAuthState{
+		State:        value,
+		Provider:     provider,
+		CodeVerifier: oauth2.GenerateVerifier(),
+		LinkUserID:   linkUserID,
+		BindingHash:  s.sign("binding." + bindingString),
+		ExpiresAt:    expiresAt,
+	}
+	if err := s.States.Save(ctx, state); err != nil {
+		return nil, "", err
+	}
+	return state, bindingString, nil
+}
+
+// BeginLink issues a single-use ticket for linking provider to the user.
+// The client that asked for it, typically an app with its own cookie jar,
+// opens it in the browser that will visit the provider, where StartLink
+// begins the authorization request.
+func (s *OAuthService) BeginLink(ctx context.Context, provider, userID string) (string, error) {
+	ticket, expiresAt, err := s.signed("link."+provider, linkTicketTTL)
+	if err != nil {
+		return "", err
+	}
+	err = s.States.Save(ctx, &domain.OAuthState{
+		State:      ticket,
+		Provider:   provider,
+		LinkUserID: userID,
+		ExpiresAt:  expiresAt,
+	})
+	if err != nil {
+		return "", err
+	}
+	return ticket, nil
+}
+
+// StartLink redeems a ticket from BeginLink and begins the authorization
+// request linking provider to the ticket's user, bound to the browser that
+// opened the ticket like one from Begin.
+func (s *OAuthService) StartLink(ctx context.Context, provider, ticket string) (*domain.OAuthState, string, error) {
+	if !s.verify("link."+provider, ticket) {
+		return nil, "", domain.ErrInvalidOAuthState
+	}
+	pending, err := s.States.Take(ctx, ticket)
+	if err != nil {
+		return nil, "", err
+	}
+	if pending.Provider != provider || pending.LinkUserID == "" {
+		return nil, "", domain.ErrInvalidOAuthState
+	}
+	return s.Begin(ctx, provider, pending.LinkUserID)
+}
+
+// Complete validates the state returned by provider and consumes it.
+// binding must be the one Begin returned, so a request started in one
+// browser can't be finished in another: otherwise an attacker could start
+// linking their account and have a victim consent.
+func (s *OAuthService) Complete(ctx context.Context, provider, state, binding string) (*domain.OAuthState, error) {
+	if !s.verify(provider, state) {
+		return nil, domain.ErrInvalidOAuthState
+	}
+
+	pending, err := s.States.Take(ctx, state)
+	if err != nil {
+		return nil, err
+	}
+	if pending.Provider != provider {
+		return nil, domain.ErrInvalidOAuthState
+	}
+	if binding == "" || !hmac.Equal([]byte(pending.BindingHash), []byte(s.sign("binding."+binding))) {
+		return nil, domain.ErrInvalidOAuthState
+	}
+	return pending, nil
+}
+
+// SignIn returns the user linked to a provider account, creating a new user
+// on first sign-in. Accounts are never matched by email: an existing user
+// must sign in and link the provider explicitly. New users get their email
+// normalised like one entered at sign-up, or none if the provider's isn't
+// valid, and a login derived by validate.ProviderLogin.
+func (s *OAuthService) SignIn(ctx context.Context, provider, subject, email string) (*domain.User, error) {
+	identity, err := s.Identities.GetByProviderSubject(ctx, provider, subject)
+	if err == nil {
+		return s.Users.GetByID(ctx, identity.UserID)
+	}
+	if !errors.Is(err, domain.ErrIdentityNotFound) {
+		return nil, err
+	}
+
+	v := validate.New(ctx)
+	email = v.Email("email", email)
+	if v.Err() != nil {
+		email = ""
+	}
+	user := &domain.User{
+		ID:    GenerateUserID(),
+		Login: validate.ProviderLogin(provider, subject),
+		Email: email,
+	}
+	if err := s.Users.Create(ctx, user); err != nil {
+		if errors.Is(err, domain.ErrUserConflict) {
+			return nil, fmt.Errorf("%w; sign in to that account and link %s instead", err, provider)
+		}
+		return nil, err
+	}
+	err = s.Identities.Create(ctx, &domain.Identity{
+		Provider: provider,
+		Subject:  subject,
+		UserID:   user.ID,
+		Email:    email,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// Link attaches a provider account to an existing user. It returns
+// domain.ErrIdentityConflict when the provider account belongs to another
+// user or the user already has an identity at that provider.
+func (s *OAuthService) Link(ctx context.Context, userID, provider, subject, email string) (*domain.Identity, error) {
+	identity := &domain.Identity{
+		Provider: provider,
+		Subject:  subject,
+		UserID:   userID,
+		Email:    validate.NormalizeEmail(email),
+	}
+	if err := s.Identities.Create(ctx, identity); err != nil {
+		return nil, err
+	}
+	return identity, nil
+}
+
+// Unlink detaches a provider from the user unless it is their only way to
+// sign in.
+func (s *OAuthService) Unlink(ctx context.Context, user *domain.User, provider string) error {
+	identities, err := s.Identities.ListByUser(ctx, user.ID)
+	if err != nil {
+		return err
+	}
+	linked := false
+	for _, identity := range identities {
+		linked = linked || identity.Provider == provider
+	}
+	if !linked {
+		return domain.ErrIdentityNotFound
+	}
+	passwordHash, err := s.Users.GetPasswordHash(ctx, user.Login)
+	if err != nil {
+		return err
+	}
+	if passwordHash == "" && len(identities) <= 1 {
+		return domain.ErrLastSignInMethod
+	}
+	return s.Identities.Delete(ctx, user.ID, provider)
+}
+
+// ListIdentities lists the providers linked to the user.
+func (s *OAuthService) ListIdentities(ctx context.Context, userID string) ([]*domain.Identity, error) {
+	return s.Identities.ListByUser(ctx, userID)
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+)
+
+const (
+	// recommendationWindow is how far back logged activities are considered
+	recommendationWindow = 90 * 24 * time.Hour
+	// minAnnualisePeriod keeps a few days of logs from being extrapolated
+	// into a year
+	minAnnualisePeriod = 30 * 24 * time.Hour
+	// minRecommendedSavings drops suggestions that would barely matter, in kg CO2e a year
+	minRecommendedSavings = 1.0
+)
+
+// footprintProfile is what recommendations are derived from: the user's
+// onboarding answers and what they logged recently, both as annual kg CO2e
+type footprintProfile struct {
+	answers    map[string]domain.Answer
+	scores     map[string]float64 // per answered survey question
+	logged     map[string]float64 // per activity category, annualised
+	quantities map[string]float64 // per activity category, annualised in its unit
+}
+
+// chose reports whether the user picked any of options for a survey question
+func (p *footprintProfile) chose(questionID string, options ...string) bool {
+	return chose(p.answers[questionID], options...)
+}
+
+// estimate returns the annual footprint of an area from the activities logged
+// in categories, falling back to the survey questions when none were logged
+func (p *footprintProfile) estimate(questionIDs, categories []string) (kg float64, fromLogs bool) {
+	for _, category := range categories {
+		kg += p.logged[category]
+	}
+	if kg > 0 {
+		return kg, true
+	}
+	for _, id := range questionIDs {
+		kg += p.scores[id]
+	}
+	return kg, false
+}
+
+// RecommendationService suggests reduction actions from the rules in
+// recommendationRules, ranked by estimated savings weighted by how easy they
+// are to adopt. Dismissed suggestions aren't repeated.
+type RecommendationService struct {
+	Repo       domain.RecommendationService
+	Surveys    domain.SurveyResponseService
+	Activities domain.ActivityService
+}
+
+// NewRecommendationService creates a new recommendation service instance.
+// Panics if any repository is nil.
+func NewRecommendationService(repo domain.RecommendationService, surveys domain.SurveyResponseService, activities domain.ActivityService) *RecommendationService {
+	if repo == nil || surveys == nil || activities == nil {
+		panic("repositories must not be nil")
+	}
+	return &RecommendationService{
+		Repo:       repo,
+		Surveys:    surveys,
+		Activities: activities,
+	}
+}
+
+// For returns the user's recommendations, best first
+func (s *RecommendationService) For(ctx context.Context, userID string, now time.Time) ([]*domain.Recommendation, error) {
+	profile, err := s.profile(ctx, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	dismissedIDs, err := s.Repo.Dismissed(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	dismissed := make(map[string]bool, len(dismissedIDs))
+	for _, id := range dismissedIDs {
+		dismissed[id] = true
+	}
+
+	recommendations := []*domain.Recommendation{}
+	for _, rule := range recommendationRules {
+		if dismissed[rule.ID] {
+			continue
+		}
+		savings, fromLogs := rule.Savings(profile)
+		savings = math.Round(savings)
+		if savings < minRecommendedSavings {
+			continue
+		}
+		recommendations = append(recommendations, &domain.Recommendation{
+			ID:            rule.ID,
+			Title:         rule.Title,
+			Category:      rule.Category,
+			Explanation:   rule.Explain(savings, fromLogs),
+			AnnualSavings: savings,
+			Feasibility:   rule.Feasibility,
+			Score:         math.Round(savings*rule.Feasibility*10) / 10,
+		})
+	}
+	sort.SliceStable(recommendations, func(i, j int) bool {
+		return recommendations[i].Score > recommendations[j].Score
+	})
+	return recommendations, nil
+}
+
+// Dismiss stops a recommendation from being suggested to the user again
+func (s *RecommendationService) Dismiss(ctx context.Context, userID, recommendationID string) error {
+	if findRecommendationRule(recommendationID) == nil {
+		return domain.ErrRecommendationNotFound
+	}
+	return s.Repo.Dismiss(ctx, userID, recommendationID)
+}
+
+// profile gathers the user's onboarding answers and recent activities
+func (s *RecommendationService) profile(ctx context.Context, userID string, now time.Time) (*footprintProfile, error) {
+	profile := &footprintProfile{
+		answers:    map[string]domain.Answer{},
+		scores:     map[string]float64{},
+		logged:     map[string]float64{},
+		quantities: map[string]float64{},
+	}
+
+	response, err := s.Surveys.Latest(ctx, userID, OnboardingSurvey.ID)
+	switch {
+	case err == nil:
+		profile.answers = response.Answers
+		accepted := make(map[string]domain.Answer, len(response.Answers))
+		for _, q := range OnboardingSurvey.Questions {
+			answer, ok := response.Answers[q.ID]
+			if !ok {
+				continue
+			}
+			// Stored answers were validated on submission
+			score, err := scoreAnswer(q, answer, accepted)
+			if err != nil {
+				continue
+			}
+			profile.scores[q.ID] = score
+			accepted[q.ID] = answer
+		}
+	case !errors.Is(err, domain.ErrSurveyResponseNotFound):
+		return nil, err
+	}
+
+	activities, err := s.Activities.List(ctx, userID, domain.ActivityFilter{From: now.Add(-recommendationWindow), To: now})
+	if err != nil {
+		return nil, err
+	}
+	if len(activities) == 0 {
+		return profile, nil
+	}
+	// Listed most recent first, so the last one shows how long the user has logged
+	period := now.Sub(activities[len(activities)-1].OccurredAt)
+	if period < minAnnualisePeriod {
+		period = minAnnualisePeriod
+	}
+	perYear := float64(365*24*time.Hour) / float64(period)
+	for _, activity := range activities {
+		profile.logged[activity.Category] += activity.CO2e * perYear
+		profile.quantities[activity.Category] += activity.Quantity * perYear
+	}
+	return profile, nil
+}
@@ -0,0 +1,192 @@
+package service
+
+import "fmt"
+
+// recommendationRule is a candidate action. Savings estimates the annual kg
+// CO2e the user would save, 0 when the action doesn't apply to them, and
+// whether the estimate comes from logged activities rather than the survey.
+type recommendationRule struct {
+	ID          string
+	Title       string
+	Category    string
+	Feasibility float64
+	Savings     func(p *footprintProfile) (kg float64, fromLogs bool)
+	Explain     func(savings float64, fromLogs bool) string
+}
+
+// basis names where an estimate came from, for explanations
+func basis(fromLogs bool) string {
+	if fromLogs {
+		return "Based on the activities you logged recently"
+	}
+	return "Based on your survey answers"
+}
+
+// recommendationRules are the actions the engine can suggest. Savings use
+// the same rough averages as the onboarding survey.
+var recommendationRules = []*recommendationRule{
+	{
+		ID: "drive_less", Title: "Replace a third of your car trips", Category: "transport", Feasibility: 0.6,
+		Savings: func(p *footprintProfile) (float64, bool) {
+			car, fromLogs := p.estimate([]string{"car_km_week"}, []string{"car_petrol", "car_diesel", "car_electric"})
+			return car * 0.3, fromLogs
+		},
+		Explain: func(savings float64, fromLogs bool) string {
+			return fmt.Sprintf("%s, driving is one of your largest sources of emissions. Taking public transport, cycling or walking for a third of your trips would save about %.0f kg CO2e a year.", basis(fromLogs), savings)
+		},
+	},
+	{
+		ID: "switch_to_electric_car", Title: "Switch to an electric car", Category: "transport", Feasibility: 0.2,
+		Savings: func(p *footprintProfile) (float64, bool) {
+			var questions []string
+			if p.chose("car_type", "petrol", "diesel", "hybrid") {
+				questions = []string{"car_km_week"}
+			}
+			combustion, fromLogs := p.estimate(questions, []string{"car_petrol", "car_diesel"})
+			// An electric car emits about 0.047 kg CO2e per km against 0.17
+			return combustion * (1 - 0.047/0.170), fromLogs
+		},
+		Explain: func(savings float64, fromLogs bool) string {
+			return fmt.Sprintf("%s, you drive a combustion car. An electric car emits around 70%% less per kilometre, about %.0f kg CO2e a year for you, more with a green electricity tariff.", basis(fromLogs), savings)
+		},
+	},
+	{
+		ID: "train_instead_of_short_flights", Title: "Take the train instead of short flights", Category: "transport", Feasibility: 0.5,
+		Savings: func(p *footprintProfile) (float64, bool) {
+			var questions []string
+			if p.chose("flights", "short_few", "short_many") {
+				questions = []string{"flights"}
+			}
+			flights, fromLogs := p.estimate(questions, []string{"flight_short_haul"})
+			// Rail emits roughly a fifth of a short-haul flight per passenger km
+			return flights * 0.8, fromLogs
+		},
+		Explain: func(savings float64, fromLogs bool) string {
+			return fmt.Sprintf("%s, you take short flights. Trains emit around 80%% less on the same routes, saving about %.0f kg CO2e a year.", basis(fromLogs), savings)
+		},
+	},
+	{
+		ID: "fewer_long_flights", Title: "Halve your long-haul flights", Category: "transport", Feasibility: 0.3,
+		Savings: func(p *footprintProfile) (float64, bool) {
+			var questions []string
+			if p.chose("flights", "long_one", "long_many") {
+				questions = []string{"flights"}
+			}
+			flights, fromLogs := p.estimate(questions, []string{"flight_long_haul"})
+			return flights * 0.5, fromLogs
+		},
+		Explain: func(savings float64, fromLogs bool) string {
+			return fmt.Sprintf("%s, long-haul flights weigh heavily on your footprint. Flying half as often, or staying longer per trip, would save about %.0f kg CO2e a year.", basis(fromLogs), savings)
+		},
+	},
+	{
+		ID: "eat_less_meat", Title: "Eat less red meat", Category: "food", Feasibility: 0.6,
+		Savings: func(p *footprintProfile) (float64, bool) {
+			if beef := p.quantities["meal_beef"]; beef > 0 {
+				// Swapping half of the beef meals for vegetarian ones, 7.7 vs 1.0 kg each
+				return beef * 0.5 * (7.7 - 1.0), true
+			}
+			if p.chose("diet", "meat_daily", "meat_sometimes") {
+				// Halfway to a vegetarian diet
+				return (p.scores["diet"] - 1400) * 0.5, false
+			}
+			return 0, false
+		},
+		Explain: func(savings float64, fromLogs bool) string {
+			return fmt.Sprintf("%s, meat is a large part of your diet. Replacing half of your meat meals, beef first, with vegetarian ones would save about %.0f kg CO2e a year.", basis(fromLogs), savings)
+		},
+	},
+	{
+		ID: "cut_food_waste", Title: "Plan meals to cut food waste", Category: "food", Feasibility: 0.8,
+		Savings: func(p *footprintProfile) (float64, bool) {
+			if p.chose("food_habits", "food_waste") {
+				return 200, false
+			}
+			return 0, false
+		},
+		Explain: func(savings float64, fromLogs bool) string {
+			return fmt.Sprintf("%s, you often throw food away. Planning meals and using leftovers would save about %.0f kg CO2e a year.", basis(fromLogs), savings)
+		},
+	},
+	{
+		ID: "green_electricity", Title: "Switch to a renewable electricity tariff", Category: "home", Feasibility: 0.9,
+		Savings: func(p *footprintProfile) (float64, bool) {
+			if p.chose("green_electricity", "yes") {
+				return 0, false
+			}
+			var questions []string
+			if p.chose("green_electricity", "no", "unsure") {
+				questions = []string{"green_electricity"}
+			}
+			electricity, fromLogs := p.estimate(questions, []string{"electricity"})
+			return electricity * 0.9, fromLogs
+		},
+		Explain: func(savings float64, fromLogs bool) string {
+			return fmt.Sprintf("%s, your electricity isn't from renewable sources. Switching tariffs usually takes a few minutes and would save about %.0f kg CO2e a year.", basis(fromLogs), savings)
+		},
+	},
+	{
+		ID: "lower_thermostat", Title: "Turn the heating down by one degree", Category: "home", Feasibility: 0.9,
+		Savings: func(p *footprintProfile) (float64, bool) {
+			var questions []string
+			if p.chose("heating", "gas", "oil", "electric", "district") {
+				questions = []string{"heating"}
+			}
+			heating, fromLogs := p.estimate(questions, []string{"natural_gas"})
+			// Each degree less saves around 8% of heating energy
+			return heating * 0.08, fromLogs
+		},
+		Explain: func(savings float64, fromLogs bool) string {
+			return fmt.Sprintf("%s, heating is a big part of your home's footprint. One degree less saves around 8%% of heating energy, about %.0f kg CO2e a year.", basis(fromLogs), savings)
+		},
+	},
+	{
+		ID: "heat_pump", Title: "Replace your boiler with a heat pump", Category: "home", Feasibility: 0.15,
+		Savings: func(p *footprintProfile) (float64, bool) {
+			if gas := p.logged["natural_gas"]; gas > 0 {
+				return gas * 0.7, true
+			}
+			if p.chose("heating", "gas", "oil") {
+				// A heat pump heats the same home for about 400 kg CO2e a year
+				return p.scores["heating"] - 400, false
+			}
+			return 0, false
+		},
+		Explain: func(savings float64, fromLogs bool) string {
+			return fmt.Sprintf("%s, you heat with fossil fuel. A heat pump is a larger investment, often subsidised, and would save about %.0f kg CO2e a year.", basis(fromLogs), savings)
+		},
+	},
+	{
+		ID: "buy_less_new", Title: "Buy second-hand and repair", Category: "consumption", Feasibility: 0.6,
+		Savings: func(p *footprintProfile) (float64, bool) {
+			if p.chose("shopping", "sometimes", "often") {
+				return (p.scores["shopping"] - 400) * 0.5, false
+			}
+			return 0, false
+		},
+		Explain: func(savings float64, fromLogs bool) string {
+			return fmt.Sprintf("%s, you buy new things regularly. Choosing second-hand or repairing for half of those purchases would save about %.0f kg CO2e a year.", basis(fromLogs), savings)
+		},
+	},
+	{
+		ID: "skip_bottled_water", Title: "Drink tap water", Category: "food", Feasibility: 0.95,
+		Savings: func(p *footprintProfile) (float64, bool) {
+			if p.chose("food_habits", "bottled_water") {
+				return 45, false
+			}
+			return 0, false
+		},
+		Explain: func(savings float64, fromLogs bool) string {
+			return fmt.Sprintf("%s, you drink bottled water. A refillable bottle saves about %.0f kg CO2e a year and a lot of plastic.", basis(fromLogs), savings)
+		},
+	},
+}
+
+func findRecommendationRule(id string) *recommendationRule {
+	for _, rule := range recommendationRules {
+		if rule.ID == id {
+			return rule
+		}
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/google/uuid"
+)
+
+// SessionService issues and rotates refresh tokens. Each sign-in starts a
+// session; every refresh consumes the presented token and issues a new one in
+// the same session. Presenting a token twice means it leaked, so the whole
+// session is revoked.
+type SessionService struct {
+	Repo       domain.SessionService
+	RefreshTTL time.Duration
+}
+
+// NewSessionService creates a new session service instance.
+// Panics if the provided repository is nil.
+func NewSessionService(repo domain.SessionService, refreshTTL time.Duration) *SessionService {
+	if repo == nil {
+		panic("repository must not be nil")
+	}
+	return &SessionService{
+		Repo:       repo,
+		RefreshTTL: refreshTTL,
+	}
+}
+
+// newRefreshToken returns a random opaque token and the hash it is stored under
+func newRefreshToken() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, hashRefreshToken(token), nil
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// extend extends the session and returns a fresh refresh token for it along
+// with the record to store
+func (s *SessionService) extend(session *domain.Session) (string, *domain.RefreshToken, error) {
+	token, hash, err := newRefreshToken()
+	if err != nil {
+		return "", nil, err
+	}
+	now := time.Now()
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(s.RefreshTTL)
+	return token, &domain.RefreshToken{
+		Hash:      hash,
+		SessionID: session.ID,
+		UserID:    session.UserID,
+		ExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+// Start opens a session for a device that just signed in and returns its
+// first refresh token.
+func (s *SessionService) Start(ctx context.Context, userID, userAgent, ip string) (*domain.Session, string, error) {
+	session := &domain.Session{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		UserAgent: userAgent,
+		IP:        ip,
+		CreatedAt: time.Now(),
+	}
+	token, record, err := s.extend(session)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.Repo.SaveSession(ctx, session, record); err != nil {
+		return nil, "", err
+	}
+	return session, token, nil
+}
+
+// Refresh rotates a refresh token. It returns domain.ErrRefreshTokenReused
+// after revoking the session when the token was already used, and
+// domain.ErrSessionNotFound for unknown, expired or revoked tokens.
+func (s *SessionService) Refresh(ctx context.Context, refreshToken, userAgent, ip string) (*domain.Session, string, error) {
+	token, session, err := s.Repo.ConsumeRefreshToken(ctx, hashRefreshToken(refreshToken))
+	if errors.Is(err, domain.ErrRefreshTokenReused) {
+		if err := s.Repo.DeleteSession(ctx, token.UserID, token.SessionID); err != nil && !errors.Is(err, domain.ErrSessionNotFound) {
+			return nil, "", err
+		}
+		return nil, "", domain.ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	session.UserAgent = userAgent
+	session.IP = ip
+	newToken, record, err := s.extend(session)
+	if err != nil {
+		return nil, "", err
+	}
+	// Only rotates while the session lives: a replay or sign-out that revoked
+	// it since the token was consumed wins
+	if err := s.Repo.RotateSession(ctx, session, record); err != nil {
+		return nil, "", err
+	}
+	return session, newToken, nil
+}
+
+// Logout revokes the session a refresh token belongs to.
+func (s *SessionService) Logout(ctx context.Context, refreshToken string) error {
+	token, _, err := s.Repo.ConsumeRefreshToken(ctx, hashRefreshToken(refreshToken))
+	if err != nil && !errors.Is(err, domain.ErrRefreshTokenReused) {
+		return err
+	}
+	return s.Repo.DeleteSession(ctx, token.UserID, token.SessionID)
+}
+
+// Check returns domain.ErrSessionNotFound unless the session is live and
+// belongs to the user, so access tokens stop working once their session is
+// revoked instead of at the end of their TTL.
+func (s *SessionService) Check(ctx context.Context, userID, sessionID string) error {
+	session, err := s.Repo.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+// Revoke ends one of the user's sessions.
+func (s *SessionService) Revoke(ctx context.Context, userID, sessionID string) error {
+	return s.Repo.DeleteSession(ctx, userID, sessionID)
+}
+
+// RevokeAll ends every session of the user.
+func (s *SessionService) RevokeAll(ctx context.Context, userID string) error {
+	return s.Repo.DeleteUserSessions(ctx, userID)
+}
+
+// List returns the user's active sessions.
+func (s *SessionService) List(ctx context.Context, userID string) ([]*domain.Session, error) {
+	return s.Repo.ListSessions(ctx, userID)
+}
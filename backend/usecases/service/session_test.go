@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository/ram_storage"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+)
+
+// revokingSessionRepo revokes every session of the token's user right after
+// a refresh token is consumed, like a sign-out landing mid-rotation
+type revokingSessionRepo struct {
+	domain.SessionService
+}
+
+func (r revokingSessionRepo) ConsumeRefreshToken(ctx context.Context, tokenHash string) (*domain.RefreshToken, *domain.Session, error) {
+	token, session, err := r.SessionService.ConsumeRefreshToken(ctx, tokenHash)
+	if err == nil {
+		err = r.SessionService.DeleteUserSessions(ctx, token.UserID)
+	}
+	return token, session, err
+}
+
+func newSessionService() *service.SessionService {
+	return service.NewSessionService(ram_storage.NewSessionStorage(), time.Hour)
+}
+
+// startSession signs a device of userID in and returns its session and
+// first refresh token
+func startSession(t *testing.T, s *service.SessionService, userID string) (*domain.Session, string) {
+	t.Helper()
+	session, token, err := s.Start(context.Background(), userID, "test-agent", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	return session, token
+}
+
+func TestSessionServiceRefreshRotates(t *testing.T) {
+	ctx := context.Background()
+	s := newSessionService()
+	session, token := startSession(t, s, "user-1")
+
+	refreshed, newToken, err := s.Refresh(ctx, token, "new-agent", "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if refreshed.ID != session.ID {
+		t.Errorf("Refresh session ID = %s, want the same session %s", refreshed.ID, session.ID)
+	}
+	if newToken == "" || newToken == token {
+		t.Errorf("Refresh token = %q, want a new one", newToken)
+	}
+	if refreshed.UserAgent != "new-agent" || refreshed.IP != "10.0.0.1" {
+		t.Errorf("Refresh session device = %s %s, want the refreshing device", refreshed.UserAgent, refreshed.IP)
+	}
+	if err := s.Check(ctx, "user-1", session.ID); err != nil {
+		t.Errorf("Check after Refresh: %v", err)
+	}
+	if _, _, err := s.Refresh(ctx, newToken, "new-agent", "10.0.0.1"); err != nil {
+		t.Errorf("Refresh with the rotated token: %v", err)
+	}
+}
+
+func TestSessionServiceReplayRevokesSession(t *testing.T) {
+	ctx := context.Background()
+	s := newSessionService()
+	session, token := startSession(t, s, "user-1")
+	other, otherToken := startSession(t, s, "user-1")
+
+	_, newToken, err := s.Refresh(ctx, token, "test-agent", "127.0.0.1")
+	if err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if _, _, err := s.Refresh(ctx, token, "attacker", "10.6.6.6"); !errors.Is(err, domain.ErrRefreshTokenReused) {
+		t.Fatalf("replayed Refresh error = %v, want ErrRefreshTokenReused", err)
+	}
+
+	// The whole token family is dead, the user's other devices are not
+	if err := s.Check(ctx, "user-1", session.ID); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("Check after replay error = %v, want ErrSessionNotFound", err)
+	}
+	if _, _, err := s.Refresh(ctx, newToken, "test-agent", "127.0.0.1"); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("Refresh with the rotated token after replay error = %v, want ErrSessionNotFound", err)
+	}
+	if err := s.Check(ctx, "user-1", other.ID); err != nil {
+		t.Errorf("Check of another session: %v", err)
+	}
+	if _, _, err := s.Refresh(ctx, otherToken, "test-agent", "127.0.0.1"); err != nil {
+		t.Errorf("Refresh of another session: %v", err)
+	}
+}
+
+func TestSessionServiceLogout(t *testing.T) {
+	ctx := context.Background()
+	s := newSessionService()
+	session, token := startSession(t, s, "user-1")
+	other, _ := startSession(t, s, "user-1")
+
+	if err := s.Logout(ctx, token); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+	if err := s.Check(ctx, "user-1", session.ID); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("Check after Logout error = %v, want ErrSessionNotFound", err)
+	}
+	if _, _, err := s.Refresh(ctx, token, "test-agent", "127.0.0.1"); err == nil {
+		t.Error("Refresh after Logout succeeded")
+	}
+	if err := s.Logout(ctx, token); err == nil {
+		t.Error("second Logout succeeded")
+	}
+
+	sessions, err := s.List(ctx, "user-1")
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(sessions) != 1 || sessions[0].ID != other.ID {
+		t.Errorf("List after Logout = %v, want only the other session", sessions)
+	}
+}
+
+func TestSessionServiceRevokeAll(t *testing.T) {
+	ctx := context.Background()
+	s := newSessionService()
+	first, firstToken := startSession(t, s, "user-1")
+	second, secondToken := startSession(t, s, "user-1")
+	bystander, _ := startSession(t, s, "user-2")
+
+	if err := s.RevokeAll(ctx, "user-1"); err != nil {
+		t.Fatalf("RevokeAll: %v", err)
+	}
+	for _, tt := range []struct {
+		session *domain.Session
+		token   string
+	}{{first, firstToken}, {second, secondToken}} {
+		if err := s.Check(ctx, "user-1", tt.session.ID); !errors.Is(err, domain.ErrSessionNotFound) {
+			t.Errorf("Check of session %s after RevokeAll error = %v, want ErrSessionNotFound", tt.session.ID, err)
+		}
+		if _, _, err := s.Refresh(ctx, tt.token, "test-agent", "127.0.0.1"); !errors.Is(err, domain.ErrSessionNotFound) {
+			t.Errorf("Refresh of session %s after RevokeAll error = %v, want ErrSessionNotFound", tt.session.ID, err)
+		}
+	}
+	if sessions, err := s.List(ctx, "user-1"); err != nil || len(sessions) != 0 {
+		t.Errorf("List after RevokeAll = %v, %v, want none", sessions, err)
+	}
+	if err := s.Check(ctx, "user-2", bystander.ID); err != nil {
+		t.Errorf("Check of another user's session: %v", err)
+	}
+}
+
+func TestSessionServiceRefreshLosesToRevocation(t *testing.T) {
+	ctx := context.Background()
+	s := service.NewSessionService(revokingSessionRepo{ram_storage.NewSessionStorage()}, time.Hour)
+	session, token := startSession(t, s, "user-1")
+
+	if _, _, err := s.Refresh(ctx, token, "test-agent", "127.0.0.1"); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Fatalf("Refresh racing a revocation error = %v, want ErrSessionNotFound", err)
+	}
+	if err := s.Check(ctx, "user-1", session.ID); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Errorf("Check after the race error = %v, want the session to stay revoked", err)
+	}
+	if sessions, err := s.List(ctx, "user-1"); err != nil || len(sessions) != 0 {
+		t.Errorf("List after the race = %v, %v, want none", sessions, err)
+	}
+}
@@ -0,0 +1,451 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+)
+
+// profileActivities is how many recent activities a profile shows
+const profileActivities = 10
+
+var (
+	ErrInvalidRelation = errs.Validation("you can't befriend, follow or block yourself")
+	ErrInvalidPrivacy  = errs.Validation("privacy levels must be public, friends or private")
+)
+
+// SocialService manages friendships, follows, blocks and privacy. Friend
+// requests need the other user's consent; follows don't but only show what
+// the followed user made public. Users who blocked each other can't find
+// each other's profiles or send requests.
+type SocialService struct {
+	Repo       domain.SocialService
+	Users      domain.UserService
+	Activities domain.ActivityService
+}
+
+// NewSocialService creates a new social service instance.
+// Panics if any repository is nil.
+func NewSocialService(repo domain.SocialService, users domain.UserService, activities domain.ActivityService) *SocialService {
+	if repo == nil || users == nil || activities == nil {
+		panic("repositories must not be nil")
+	}
+	return &SocialService{
+		Repo:       repo,
+		Users:      users,
+		Activities: activities,
+	}
+}
+
+// co2Privacy decides whose CO2 savings a viewer sees outside of profiles,
+// such as on leaderboards, by the same rules as Profile.Shows
+type co2Privacy struct {
+	reader   domain.PrivacyReader
+	viewerID string
+	friends  map[string]bool
+}
+
+// newCO2Privacy loads the viewer's friends. A nil reader applies
+// domain.DefaultPrivacy as if the viewer had no friends.
+func newCO2Privacy(ctx context.Context, reader domain.PrivacyReader, viewerID string) (*co2Privacy, error) {
+	p := &co2Privacy{reader: reader, viewerID: viewerID, friends: map[string]bool{}}
+	if reader == nil {
+		return p, nil
+	}
+	friendIDs, err := reader.FriendIDs(ctx, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range friendIDs {
+		p.friends[id] = true
+	}
+	return p, nil
+}
+
+// shows reports whether the viewer may see the user's CO2 savings
+func (p *co2Privacy) shows(ctx context.Context, userID string) (bool, error) {
+	profile := domain.Profile{Relation: domain.ProfileNone, Privacy: domain.DefaultPrivacy}
+	switch {
+	case userID == p.viewerID:
+		profile.Relation = domain.ProfileSelf
+	case p.friends[userID]:
+		profile.Relation = domain.ProfileFriends
+	}
+	if p.reader != nil && profile.Relation != domain.ProfileSelf {
+		settings, err := p.reader.Privacy(ctx, userID)
+		if err != nil {
+			return false, err
+		}
+		profile.Privacy = *settings
+	}
+	return profile.Shows(profile.Privacy.CO2), nil
+}
+
+// hide clears what entry shows of a user who keeps their CO2 savings from
+// the viewer. Boards ranked by CO2 make them anonymous, also dropping the
+// points that would match them up with the points board; other boards
+// leave out their CO2.
+func (p *co2Privacy) hide(entry *domain.LeaderboardEntry, metric string) {
+	entry.Private = true
+	if metric == domain.MetricCO2Saved {
+		entry.Name = ""
+		entry.Points = 0
+	} else {
+		entry.CO2Saved = 0
+	}
+}
+
+// find returns the relation from one user to another, or nil
+func find(relations []*domain.Relation, from, to string) *domain.Relation {
+	for _, relation := range relations {
+		if relation.UserID == from && relation.OtherID == to {
+			return relation
+		}
+	}
+	return nil
+}
+
+// other returns the user at login with the relations between them and
+// userID. It returns domain.ErrUserNotFound when either blocked the other.
+func (s *SocialService) other(ctx context.Context, userID, login string) (*domain.User, []*domain.Relation, error) {
+	other, err := s.Users.Get(ctx, login)
+	if err != nil {
+		return nil, nil, err
+	}
+	if other.ID == userID {
+		return nil, nil, ErrInvalidRelation
+	}
+	relations, err := s.Repo.Between(ctx, userID, other.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, relation := range relations {
+		if relation.Status == domain.RelationBlocked {
+			return nil, nil, domain.ErrUserNotFound
+		}
+	}
+	return other, relations, nil
+}
+
+// RequestFriend asks the user at login to be friends. If they already asked
+// the user, the request is accepted instead.
+func (s *SocialService) RequestFriend(ctx context.Context, userID, login string) (*domain.Relation, error) {
+	other, relations, err := s.other(ctx, userID, login)
+	if err != nil {
+		return nil, err
+	}
+	if incoming := find(relations, other.ID, userID); incoming != nil && incoming.Status == domain.RelationPending {
+		incoming.Status = domain.RelationFriends
+		return incoming, s.Repo.SaveRelation(ctx, incoming)
+	}
+	if len(relations) > 0 {
+		return nil, domain.ErrRelationConflict
+	}
+	relation := &domain.Relation{UserID: userID, OtherID: other.ID, Status: domain.RelationPending}
+	return relation, s.Repo.SaveRelation(ctx, relation)
+}
+
+// AcceptFriend accepts the friend request of the user at login
+func (s *SocialService) AcceptFriend(ctx context.Context, userID, login string) (*domain.Relation, error) {
+	other, relations, err := s.other(ctx, userID, login)
+	if err != nil {
+		return nil, err
+	}
+	incoming := find(relations, other.ID, userID)
+	if incoming == nil || incoming.Status != domain.RelationPending {
+		return nil, domain.ErrRelationNotFound
+	}
+	incoming.Status = domain.RelationFriends
+	return incoming, s.Repo.SaveRelation(ctx, incoming)
+}
+
+// DeclineFriend turns down the friend request of the user at login
+func (s *SocialService) DeclineFriend(ctx context.Context, userID, login string) error {
+	other, relations, err := s.other(ctx, userID, login)
+	if err != nil {
+		return err
+	}
+	incoming := find(relations, other.ID, userID)
+	if incoming == nil || incoming.Status != domain.RelationPending {
+		return domain.ErrRelationNotFound
+	}
+	return s.Repo.DeleteRelation(ctx, other.ID, userID)
+}
+
+// RemoveFriend ends a friendship with the user at login, or withdraws the
+// user's request to them
+func (s *SocialService) RemoveFriend(ctx context.Context, userID, login string) error {
+	_, relations, err := s.other(ctx, userID, login)
+	if err != nil {
+		return err
+	}
+	for _, relation := range relations {
+		if relation.Status == domain.RelationFriends || (relation.Status == domain.RelationPending && relation.UserID == userID) {
+			return s.Repo.DeleteRelation(ctx, relation.UserID, relation.OtherID)
+		}
+	}
+	return domain.ErrRelationNotFound
+}
+
+// Block ends any friendship, request and follow between the user and the
+// user at login and keeps them from finding each other
+func (s *SocialService) Block(ctx context.Context, userID, login string) error {
+	other, err := s.Users.Get(ctx, login)
+	if err != nil {
+		return err
+	}
+	if other.ID == userID {
+		return ErrInvalidRelation
+	}
+	relations, err := s.Repo.Between(ctx, userID, other.ID)
+	if err != nil {
+		return err
+	}
+	for _, relation := range relations {
+		if relation.Status != domain.RelationBlocked {
+			if err := s.Repo.DeleteRelation(ctx, relation.UserID, relation.OtherID); err != nil && !errors.Is(err, domain.ErrRelationNotFound) {
+				return err
+			}
+		}
+	}
+	for _, pair := range [][2]string{{userID, other.ID}, {other.ID, userID}} {
+		if err := s.Repo.Unfollow(ctx, pair[0], pair[1]); err != nil && !errors.Is(err, domain.ErrFollowNotFound) {
+			return err
+		}
+	}
+	if block := find(relations, userID, other.ID); block != nil && block.Status == domain.RelationBlocked {
+		return nil
+	}
+	return s.Repo.SaveRelation(ctx, &domain.Relation{UserID: userID, OtherID: other.ID, Status: domain.RelationBlocked})
+}
+
+// Unblock lifts the user's block of the user at login
+func (s *SocialService) Unblock(ctx context.Context, userID, login string) error {
+	other, err := s.Users.Get(ctx, login)
+	if err != nil {
+		return err
+	}
+	relations, err := s.Repo.Between(ctx, userID, other.ID)
+	if err != nil {
+		return err
+	}
+	if block := find(relations, userID, other.ID); block == nil || block.Status != domain.RelationBlocked {
+		return domain.ErrRelationNotFound
+	}
+	return s.Repo.DeleteRelation(ctx, userID, other.ID)
+}
+
+// Follow makes the user follow the user at login
+func (s *SocialService) Follow(ctx context.Context, userID, login string) error {
+	other, _, err := s.other(ctx, userID, login)
+	if err != nil {
+		return err
+	}
+	return s.Repo.Follow(ctx, userID, other.ID)
+}
+
+// Unfollow stops the user following the user at login
+func (s *SocialService) Unfollow(ctx context.Context, userID, login string) error {
+	other, err := s.Users.Get(ctx, login)
+	if err != nil {
+		return err
+	}
+	return s.Repo.Unfollow(ctx, userID, other.ID)
+}
+
+// named fills in the logins of contacts, dropping deleted users
+func (s *SocialService) named(ctx context.Context, contacts []*domain.Contact) ([]*domain.Contact, error) {
+	named := make([]*domain.Contact, 0, len(contacts))
+	for _, contact := range contacts {
+		user, err := s.Users.GetByID(ctx, contact.UserID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		contact.Login = user.Login
+		named = append(named, contact)
+	}
+	return named, nil
+}
+
+// contacts lists the other side of the user's relations with status,
+// keeping those that pass keep
+func (s *SocialService) contacts(ctx context.Context, userID, status string, keep func(*domain.Relation) bool) ([]*domain.Contact, error) {
+	relations, err := s.Repo.Relations(ctx, userID, status)
+	if err != nil {
+		return nil, err
+	}
+	contacts := []*domain.Contact{}
+	for _, relation := range relations {
+		if !keep(relation) {
+			continue
+		}
+		otherID := relation.OtherID
+		if otherID == userID {
+			otherID = relation.UserID
+		}
+		contacts = append(contacts, &domain.Contact{UserID: otherID, Since: relation.UpdatedAt})
+	}
+	return s.named(ctx, contacts)
+}
+
+// Friends lists the user's friends, most recent first
+func (s *SocialService) Friends(ctx context.Context, userID string) ([]*domain.Contact, error) {
+	return s.contacts(ctx, userID, domain.RelationFriends, func(*domain.Relation) bool { return true })
+}
+
+// Requests lists the friend requests the user received and sent, most recent first
+func (s *SocialService) Requests(ctx context.Context, userID string) (incoming, outgoing []*domain.Contact, err error) {
+	incoming, err = s.contacts(ctx, userID, domain.RelationPending, func(relation *domain.Relation) bool {
+		return relation.OtherID == userID
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	outgoing, err = s.contacts(ctx, userID, domain.RelationPending, func(relation *domain.Relation) bool {
+		return relation.UserID == userID
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return incoming, outgoing, nil
+}
+
+// Blocked lists the users the user blocked, most recent first
+func (s *SocialService) Blocked(ctx context.Context, userID string) ([]*domain.Contact, error) {
+	return s.contacts(ctx, userID, domain.RelationBlocked, func(relation *domain.Relation) bool {
+		return relation.UserID == userID
+	})
+}
+
+// Followers lists who follows the user, most recent first
+func (s *SocialService) Followers(ctx context.Context, userID string) ([]*domain.Contact, error) {
+	contacts, err := s.Repo.Followers(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.named(ctx, contacts)
+}
+
+// Following lists whom the user follows, most recent first
+func (s *SocialService) Following(ctx context.Context, userID string) ([]*domain.Contact, error) {
+	contacts, err := s.Repo.Following(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.named(ctx, contacts)
+}
+
+// FriendIDs lists the IDs of the user's friends. It implements
+// domain.FriendLister for the friends leaderboard.
+func (s *SocialService) FriendIDs(ctx context.Context, userID string) ([]string, error) {
+	relations, err := s.Repo.Relations(ctx, userID, domain.RelationFriends)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(relations))
+	for _, relation := range relations {
+		if relation.UserID == userID {
+			ids = append(ids, relation.OtherID)
+		} else {
+			ids = append(ids, relation.UserID)
+		}
+	}
+	return ids, nil
+}
+
+// Privacy returns the user's privacy settings
+func (s *SocialService) Privacy(ctx context.Context, userID string) (*domain.PrivacySettings, error) {
+	return s.Repo.Privacy(ctx, userID)
+}
+
+// SetPrivacy changes the user's privacy settings; empty levels keep their
+// current value
+func (s *SocialService) SetPrivacy(ctx context.Context, userID string, changes domain.PrivacySettings) (*domain.PrivacySettings, error) {
+	settings, err := s.Repo.Privacy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, change := range []struct {
+		level *string
+		value string
+	}{
+		{&settings.CO2, changes.CO2},
+		{&settings.Activity, changes.Activity},
+	} {
+		switch change.value {
+		case "":
+		case domain.PrivacyPublic, domain.PrivacyFriends, domain.PrivacyPrivate:
+			*change.level = change.value
+		default:
+			return nil, fmt.Errorf("%w, got %q", ErrInvalidPrivacy, change.value)
+		}
+	}
+	if err := s.Repo.SetPrivacy(ctx, userID, settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// Profile returns the user at login as seen by viewer, which is nil for
+// anonymous visitors. Users who blocked each other aren't found.
+func (s *SocialService) Profile(ctx context.Context, viewer *domain.User, login string) (*domain.Profile, error) {
+	owner, err := s.Users.Get(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	profile := &domain.Profile{User: owner, Relation: domain.ProfileNone}
+
+	switch {
+	case viewer != nil && viewer.ID == owner.ID:
+		profile.Relation = domain.ProfileSelf
+	case viewer != nil:
+		_, relations, err := s.other(ctx, viewer.ID, login)
+		if err != nil {
+			return nil, err
+		}
+		for _, relation := range relations {
+			switch {
+			case relation.Status == domain.RelationFriends:
+				profile.Relation = domain.ProfileFriends
+			case relation.UserID == viewer.ID:
+				profile.Relation = domain.ProfileRequestSent
+			default:
+				profile.Relation = domain.ProfileRequestReceived
+			}
+		}
+		if profile.Following, err = s.Repo.IsFollowing(ctx, viewer.ID, owner.ID); err != nil {
+			return nil, err
+		}
+		if profile.FollowsYou, err = s.Repo.IsFollowing(ctx, owner.ID, viewer.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	followers, err := s.Repo.Followers(ctx, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	following, err := s.Repo.Following(ctx, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	profile.Followers, profile.FollowingCount = len(followers), len(following)
+
+	privacy, err := s.Repo.Privacy(ctx, owner.ID)
+	if err != nil {
+		return nil, err
+	}
+	profile.Privacy = *privacy
+	if profile.Shows(privacy.Activity) {
+		profile.RecentActivities, err = s.Activities.List(ctx, owner.ID, domain.ActivityFilter{Limit: profileActivities})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return profile, nil
+}
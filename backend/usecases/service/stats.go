@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+)
+
+const (
+	// maxStatsPoints bounds the length of a time series
+	maxStatsPoints = 366
+	// worldRegion keys the world average in NationalAverages
+	worldRegion = "WORLD"
+	daysPerYear = 365.25
+	dateLayout  = "2006-01-02"
+)
+
+// ErrInvalidStatsQuery is wrapped by every stats query validation error
+var ErrInvalidStatsQuery = errs.Validation("invalid stats query")
+
+// defaultStatsPoints is the length of a series when no range is given
+var defaultStatsPoints = map[string]int{
+	domain.StatsDay:   30,
+	domain.StatsWeek:  12,
+	domain.StatsMonth: 12,
+}
+
+// NationalAverages are annual per-capita CO2 emissions in kg by ISO 3166-1
+// alpha-2 code, rounded from Our World in Data's 2022 figures
+var NationalAverages = map[string]float64{
+	worldRegion: 4700,
+	"AU":        14900,
+	"BR":        2300,
+	"CA":        14200,
+	"CN":        8000,
+	"DE":        8000,
+	"ES":        5200,
+	"FR":        4600,
+	"GB":        4700,
+	"IN":        2000,
+	"IT":        5700,
+	"JP":        8500,
+	"KR":        11600,
+	"NL":        7100,
+	"NO":        7500,
+	"PL":        7900,
+	"RU":        12400,
+	"SE":        3600,
+	"TR":        5100,
+	"US":        14900,
+}
+
+// StatsQuery selects the statistics to compute. From and To are dates
+// (YYYY-MM-DD) in Timezone, widened to whole periods; when empty, the
+// latest few periods up to now are shown. Region picks the national average,
+// the world's when empty or unknown.
+type StatsQuery struct {
+	Period   string
+	Timezone string
+	From     string
+	To       string // exclusive
+	Region   string
+}
+
+// StatsService computes a user's footprint statistics. Aggregations are
+// cached per query until the user's activities change.
+type StatsService struct {
+	Repo  domain.StatsService
+	Cache domain.StatsCacheService
+}
+
+// NewStatsService creates a new stats service instance.
+// Panics if any repository is nil.
+func NewStatsService(repo domain.StatsService, cache domain.StatsCacheService) *StatsService {
+	if repo == nil || cache == nil {
+		panic("repositories must not be nil")
+	}
+	return &StatsService{
+		Repo:  repo,
+		Cache: cache,
+	}
+}
+
+// For returns the user's statistics for query as of now
+func (s *StatsService) For(ctx context.Context, user *domain.User, query StatsQuery, now time.Time) (*domain.Stats, error) {
+	if query.Period == "" {
+		query.Period = domain.StatsDay
+	}
+	if _, ok := defaultStatsPoints[query.Period]; !ok {
+		return nil, fmt.Errorf("%w: period must be day, week or month", ErrInvalidStatsQuery)
+	}
+	if query.Timezone == "" {
+		query.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(query.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: unknown timezone %q", ErrInvalidStatsQuery, query.Timezone)
+	}
+	from, to, err := statsRange(query, loc, now)
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates, err := s.aggregates(ctx, user.ID, query.Period, loc, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &domain.Stats{
+		Period:     query.Period,
+		Timezone:   loc.String(),
+		From:       from,
+		To:         to,
+		Series:     fillSeries(aggregates.Series, query.Period, loc, from, to),
+		Categories: aggregates.Categories,
+		Streak:     streak(aggregates.ActiveDays, now.In(loc)),
+	}
+	for _, category := range stats.Categories {
+		stats.Total += category.CO2
+	}
+	stats.Total = roundGram(stats.Total)
+	for i := range stats.Categories {
+		stats.Categories[i].CO2 = roundGram(stats.Categories[i].CO2)
+		stats.Categories[i].Share = percentOf(stats.Categories[i].CO2, stats.Total)
+	}
+	stats.Comparison = compare(stats.Total, user.CO2Baseline, strings.ToUpper(query.Region), to.Sub(from))
+	return stats, nil
+}
+
+// Invalidate drops the user's cached statistics after their activities changed
+func (s *StatsService) Invalidate(ctx context.Context, userID string) {
+	if err := s.Cache.Invalidate(ctx, userID); err != nil {
+		log.Printf("Failed to invalidate stats of user %s: %v", userID, err)
+	}
+}
+
+// aggregates returns the cached aggregations of the query or computes them
+func (s *StatsService) aggregates(ctx context.Context, userID, period string, loc *time.Location, from, to time.Time) (*domain.StatsAggregates, error) {
+	key := period + "|" + loc.String() + "|" + strconv.FormatInt(from.Unix(), 10) + "|" + strconv.FormatInt(to.Unix(), 10)
+	aggregates, err := s.Cache.Get(ctx, userID, key)
+	if err == nil {
+		return aggregates, nil
+	} else if !errors.Is(err, domain.ErrStatsNotCached) {
+		// Serve from storage while the cache is down
+		log.Printf("Failed to read cached stats of user %s: %v", userID, err)
+	}
+
+	aggregates = &domain.StatsAggregates{}
+	if aggregates.Series, err = s.Repo.Series(ctx, userID, period, loc, from, to); err != nil {
+		return nil, err
+	}
+	if aggregates.Categories, err = s.Repo.Breakdown(ctx, userID, from, to); err != nil {
+		return nil, err
+	}
+	if aggregates.ActiveDays, err = s.Repo.ActiveDays(ctx, userID, loc); err != nil {
+		return nil, err
+	}
+	if err := s.Cache.Set(ctx, userID, key, aggregates); err != nil {
+		log.Printf("Failed to cache stats of user %s: %v", userID, err)
+	}
+	return aggregates, nil
+}
+
+// periodStart returns the start of the day, ISO week or month containing t in loc
+func periodStart(t time.Time, period string, loc *time.Location) time.Time {
+	t = t.In(loc)
+	switch period {
+	case domain.StatsWeek:
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+		return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+	case domain.StatsMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+	default:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	}
+}
+
+// nextPeriod returns the start of the period after the one starting at start
+func nextPeriod(start time.Time, period string) time.Time {
+	switch period {
+	case domain.StatsWeek:
+		return start.AddDate(0, 0, 7)
+	case domain.StatsMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// statsRange resolves the query's dates to whole periods in loc
+func statsRange(query StatsQuery, loc *time.Location, now time.Time) (from, to time.Time, err error) {
+	to = nextPeriod(periodStart(now, query.Period, loc), query.Period)
+	if query.To != "" {
+		day, err := time.ParseInLocation(dateLayout, query.To, loc)
+		if err != nil {
+			return from, to, fmt.Errorf("%w: to must be a date like 2026-01-31", ErrInvalidStatsQuery)
+		}
+		// The period holding the day before to is the last one shown
+		to = nextPeriod(periodStart(day.AddDate(0, 0, -1), query.Period, loc), query.Period)
+	}
+
+	if query.From != "" {
+		day, err := time.ParseInLocation(dateLayout, query.From, loc)
+		if err != nil {
+			return from, to, fmt.Errorf("%w: from must be a date like 2026-01-01", ErrInvalidStatsQuery)
+		}
+		from = periodStart(day, query.Period, loc)
+	} else {
+		from = to
+		for i := 0; i < defaultStatsPoints[query.Period]; i++ {
+			from = periodStart(from.AddDate(0, 0, -1), query.Period, loc)
+		}
+	}
+
+	if !from.Before(to) {
+		return from, to, fmt.Errorf("%w: from must be before to", ErrInvalidStatsQuery)
+	}
+	points := 0
+	for start := from; start.Before(to); start = nextPeriod(start, query.Period) {
+		if points++; points > maxStatsPoints {
+			return from, to, fmt.Errorf("%w: at most %d %ss can be shown at once", ErrInvalidStatsQuery, maxStatsPoints, query.Period)
+		}
+	}
+	return from, to, nil
+}
+
+// fillSeries returns a point for every period in [from, to), zero where
+// nothing was logged
+func fillSeries(logged []domain.StatsPoint, period string, loc *time.Location, from, to time.Time) []domain.StatsPoint {
+	byStart := make(map[int64]domain.StatsPoint, len(logged))
+	for _, point := range logged {
+		byStart[point.Start.Unix()] = point
+	}
+	series := []domain.StatsPoint{}
+	for start := from; start.Before(to); start = nextPeriod(start, period) {
+		point := byStart[start.Unix()]
+		point.Start = start.In(loc)
+		point.CO2 = roundGram(point.CO2)
+		series = append(series, point)
+	}
+	return series
+}
+
+// compare relates total to the national average and the survey baseline,
+// both prorated to length
+func compare(total, baseline float64, region string, length time.Duration) domain.StatsComparison {
+	years := length.Hours() / 24 / daysPerYear
+	average, ok := NationalAverages[region]
+	if !ok {
+		region, average = worldRegion, NationalAverages[worldRegion]
+	}
+	comparison := domain.StatsComparison{Region: region, NationalAverage: roundGram(average * years)}
+	comparison.VsNationalAverage = difference(total, comparison.NationalAverage)
+	if baseline > 0 {
+		prorated := roundGram(baseline * years)
+		vsBaseline := difference(total, prorated)
+		comparison.Baseline = &prorated
+		comparison.VsBaseline = &vsBaseline
+	}
+	return comparison
+}
+
+// streak measures the runs of consecutive days in activeDays, which are
+// dates most recent first
+func streak(activeDays []string, today time.Time) domain.StatsStreak {
+	var result domain.StatsStreak
+	runs := []int{}
+	var previous time.Time
+	for _, day := range activeDays {
+		date, err := time.Parse(dateLayout, day)
+		if err != nil {
+			continue
+		}
+		if len(runs) > 0 && previous.AddDate(0, 0, -1).Equal(date) {
+			runs[len(runs)-1]++
+		} else {
+			runs = append(runs, 1)
+		}
+		previous = date
+	}
+	if len(runs) == 0 {
+		return result
+	}
+
+	result.LastActive = activeDays[0]
+	for _, run := range runs {
+		if run > result.Longest {
+			result.Longest = run
+		}
+	}
+	if isCurrent(result.LastActive, today) {
+		result.Current = runs[0]
+	}
+	return result
+}
+
+// isCurrent reports whether a streak ending on lastActive is still alive today
+func isCurrent(lastActive string, today time.Time) bool {
+	date := today.Format(dateLayout)
+	yesterday := today.AddDate(0, 0, -1).Format(dateLayout)
+	return lastActive == date || lastActive == yesterday
+}
+
+// roundGram rounds kg to the gram
+func roundGram(kg float64) float64 {
+	return math.Round(kg*1000) / 1000
+}
+
+// percentOf returns part as a percentage of whole, to one decimal
+func percentOf(part, whole float64) float64 {
+	if whole == 0 {
+		return 0
+	}
+	return math.Round(part/whole*1000) / 10
+}
+
+// difference returns how far value is above reference in percent, to one decimal
+func difference(value, reference float64) float64 {
+	if reference == 0 {
+		return 0
+	}
+	return math.Round((value-reference)/reference*1000) / 10
+}
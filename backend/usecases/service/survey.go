@@ -0,0 +1,196 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidSurveyAnswers is wrapped by every answer validation error
+var ErrInvalidSurveyAnswers = errs.Validation("invalid survey answers")
+
+// SurveyService serves survey definitions and scores responses into an
+// estimated annual footprint that becomes the user's CO2 baseline.
+type SurveyService struct {
+	Responses domain.SurveyResponseService
+	Surveys   map[string]*domain.Survey
+}
+
+// NewSurveyService creates a new survey service serving the given surveys.
+// Panics if the repository is nil.
+func NewSurveyService(responses domain.SurveyResponseService, surveys ...*domain.Survey) *SurveyService {
+	if responses == nil {
+		panic("repository must not be nil")
+	}
+	s := &SurveyService{
+		Responses: responses,
+		Surveys:   make(map[string]*domain.Survey, len(surveys)),
+	}
+	for _, survey := range surveys {
+		s.Surveys[survey.ID] = survey
+	}
+	return s
+}
+
+// Get returns a survey definition.
+func (s *SurveyService) Get(id string) (*domain.Survey, error) {
+	survey, ok := s.Surveys[id]
+	if !ok {
+		return nil, domain.ErrSurveyNotFound
+	}
+	return survey, nil
+}
+
+// Submit scores the user's answers, stores them and sets the user's CO2
+// baseline to the result. It returns an error wrapping
+// ErrInvalidSurveyAnswers when the answers don't fit the survey.
+func (s *SurveyService) Submit(ctx context.Context, userID, surveyID string, answers map[string]domain.Answer) (*domain.SurveyResponse, error) {
+	survey, err := s.Get(surveyID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := Score(survey, answers)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.SurveyResponse{
+		ID:       uuid.NewString(),
+		UserID:   userID,
+		SurveyID: survey.ID,
+		Answers:  answers,
+		Result:   *result,
+	}
+	if err := s.Responses.Create(ctx, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Latest returns the user's most recent response to a survey.
+func (s *SurveyService) Latest(ctx context.Context, userID, surveyID string) (*domain.SurveyResponse, error) {
+	if _, err := s.Get(surveyID); err != nil {
+		return nil, err
+	}
+	return s.Responses.Latest(ctx, userID, surveyID)
+}
+
+// Score validates answers against the survey and adds up the footprint.
+// Questions are walked in order so branching only sees earlier answers; a
+// question whose condition isn't met must be left unanswered.
+func Score(survey *domain.Survey, answers map[string]domain.Answer) (*domain.SurveyResult, error) {
+	result := &domain.SurveyResult{Breakdown: make(map[string]float64)}
+	accepted := make(map[string]domain.Answer, len(answers))
+
+	for _, q := range survey.Questions {
+		answer, answered := answers[q.ID]
+		if q.ShowIf != nil && !chose(accepted[q.ShowIf.QuestionID], q.ShowIf.Options...) {
+			if answered {
+				return nil, fmt.Errorf("%w: %s does not apply to the earlier answers", ErrInvalidSurveyAnswers, q.ID)
+			}
+			continue
+		}
+		if !answered {
+			if q.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("%w: %s is required", ErrInvalidSurveyAnswers, q.ID)
+		}
+
+		score, err := scoreAnswer(q, answer, accepted)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidSurveyAnswers, q.ID, err)
+		}
+		result.Breakdown[q.Category] += score
+		accepted[q.ID] = answer
+	}
+	for id := range answers {
+		if _, ok := accepted[id]; !ok {
+			if _, known := findQuestion(survey, id); !known {
+				return nil, fmt.Errorf("%w: unknown question %s", ErrInvalidSurveyAnswers, id)
+			}
+		}
+	}
+
+	for category, score := range result.Breakdown {
+		result.Breakdown[category] = math.Round(score)
+		result.AnnualCO2e += result.Breakdown[category]
+	}
+	return result, nil
+}
+
+// scoreAnswer checks an answer against its question and returns its annual kg CO2e
+func scoreAnswer(q *domain.Question, answer domain.Answer, accepted map[string]domain.Answer) (float64, error) {
+	if q.Type == domain.QuestionNumeric {
+		if answer.Value == nil || len(answer.Options) > 0 {
+			return 0, errors.New("expects a numeric value")
+		}
+		value := *answer.Value
+		if math.IsNaN(value) || value < q.Min || value > q.Max {
+			return 0, fmt.Errorf("must be between %g and %g", q.Min, q.Max)
+		}
+		factor := q.Factor
+		if q.FactorBy != nil {
+			if earlier := accepted[q.FactorBy.QuestionID]; len(earlier.Options) == 1 {
+				factor = q.FactorBy.Factors[earlier.Options[0]]
+			}
+		}
+		return value * factor, nil
+	}
+
+	if answer.Value != nil {
+		return 0, errors.New("expects options, not a value")
+	}
+	switch {
+	case q.Type == domain.QuestionSingleChoice && len(answer.Options) != 1:
+		return 0, errors.New("expects exactly one option")
+	case q.Type == domain.QuestionMultiChoice && len(answer.Options) == 0 && !q.Optional:
+		return 0, errors.New("expects at least one option")
+	}
+	score := 0.0
+	seen := make(map[string]bool, len(answer.Options))
+	for _, id := range answer.Options {
+		option := findOption(q, id)
+		if option == nil || seen[id] {
+			return 0, fmt.Errorf("invalid option %q", id)
+		}
+		seen[id] = true
+		score += option.Score
+	}
+	return score, nil
+}
+
+// chose reports whether answer picked any of options
+func chose(answer domain.Answer, options ...string) bool {
+	for _, picked := range answer.Options {
+		for _, option := range options {
+			if picked == option {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func findQuestion(survey *domain.Survey, id string) (*domain.Question, bool) {
+	for _, q := range survey.Questions {
+		if q.ID == id {
+			return q, true
+		}
+	}
+	return nil, false
+}
+
+func findOption(q *domain.Question, id string) *domain.Option {
+	for _, option := range q.Options {
+		if option.ID == id {
+			return option
+		}
+	}
+	return nil
+}
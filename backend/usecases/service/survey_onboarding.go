@@ -0,0 +1,120 @@
+package service
+
+import "github.com/aygoko/EcoMInd/backend/domain"
+
+// OnboardingSurvey estimates a new user's annual footprint. Scores are
+// annual kg CO2e per person, rounded from DEFRA 2023 and Poore & Nemecek
+// 2018 averages; weekly distances are scaled by 52 weeks.
+var OnboardingSurvey = &domain.Survey{
+	ID:    "onboarding",
+	Title: "Your carbon footprint",
+	Questions: []*domain.Question{
+		{
+			ID: "commute", Category: "transport", Type: domain.QuestionSingleChoice,
+			Text: "How do you usually get around?",
+			Options: []*domain.Option{
+				{ID: "car", Label: "Car"},
+				{ID: "public_transport", Label: "Public transport"},
+				{ID: "bike_walk", Label: "Bike or on foot"},
+				{ID: "remote", Label: "I mostly stay home"},
+			},
+		},
+		{
+			ID: "car_type", Category: "transport", Type: domain.QuestionSingleChoice,
+			Text:   "What kind of car do you drive?",
+			ShowIf: &domain.Condition{QuestionID: "commute", Options: []string{"car"}},
+			Options: []*domain.Option{
+				{ID: "petrol", Label: "Petrol"},
+				{ID: "diesel", Label: "Diesel"},
+				{ID: "hybrid", Label: "Hybrid"},
+				{ID: "electric", Label: "Electric"},
+			},
+		},
+		{
+			ID: "car_km_week", Category: "transport", Type: domain.QuestionNumeric,
+			Text: "How many kilometres do you drive in a week?", Unit: "km", Max: 3000,
+			ShowIf: &domain.Condition{QuestionID: "commute", Options: []string{"car"}},
+			FactorBy: &domain.FactorBy{QuestionID: "car_type", Factors: map[string]float64{
+				"petrol":   0.170 * 52,
+				"diesel":   0.171 * 52,
+				"hybrid":   0.120 * 52,
+				"electric": 0.047 * 52,
+			}},
+		},
+		{
+			ID: "transit_km_week", Category: "transport", Type: domain.QuestionNumeric,
+			Text: "How many kilometres do you ride public transport in a week?", Unit: "km", Max: 3000,
+			ShowIf: &domain.Condition{QuestionID: "commute", Options: []string{"public_transport"}},
+			Factor: 0.07 * 52,
+		},
+		{
+			ID: "flights", Category: "transport", Type: domain.QuestionSingleChoice,
+			Text: "How much did you fly in the last year?",
+			Options: []*domain.Option{
+				{ID: "none", Label: "Not at all"},
+				{ID: "short_few", Label: "One or two short flights", Score: 300},
+				{ID: "short_many", Label: "Three or more short flights", Score: 1000},
+				{ID: "long_one", Label: "One long-haul return flight", Score: 1600},
+				{ID: "long_many", Label: "Several long-haul flights", Score: 4000},
+			},
+		},
+		{
+			ID: "home_type", Category: "home", Type: domain.QuestionSingleChoice,
+			Text: "Where do you live?",
+			Options: []*domain.Option{
+				{ID: "apartment_small", Label: "Small apartment", Score: 900},
+				{ID: "apartment_large", Label: "Large apartment", Score: 1500},
+				{ID: "house", Label: "House", Score: 2500},
+			},
+		},
+		{
+			ID: "heating", Category: "home", Type: domain.QuestionSingleChoice,
+			Text: "How is your home heated?",
+			Options: []*domain.Option{
+				{ID: "gas", Label: "Gas", Score: 1500},
+				{ID: "oil", Label: "Oil", Score: 2200},
+				{ID: "electric", Label: "Electric heaters", Score: 900},
+				{ID: "heat_pump", Label: "Heat pump", Score: 400},
+				{ID: "district", Label: "District heating", Score: 700},
+			},
+		},
+		{
+			ID: "green_electricity", Category: "home", Type: domain.QuestionSingleChoice,
+			Text: "Is your electricity from renewable sources?",
+			Options: []*domain.Option{
+				{ID: "yes", Label: "Yes"},
+				{ID: "no", Label: "No", Score: 500},
+				{ID: "unsure", Label: "Not sure", Score: 500},
+			},
+		},
+		{
+			ID: "diet", Category: "food", Type: domain.QuestionSingleChoice,
+			Text: "Which best describes your diet?",
+			Options: []*domain.Option{
+				{ID: "meat_daily", Label: "Meat every day", Score: 2600},
+				{ID: "meat_sometimes", Label: "Meat a few times a week", Score: 1900},
+				{ID: "pescatarian", Label: "Fish but no meat", Score: 1500},
+				{ID: "vegetarian", Label: "Vegetarian", Score: 1400},
+				{ID: "vegan", Label: "Vegan", Score: 1100},
+			},
+		},
+		{
+			ID: "food_habits", Category: "food", Type: domain.QuestionMultiChoice, Optional: true,
+			Text: "Which of these apply to you?",
+			Options: []*domain.Option{
+				{ID: "food_waste", Label: "I often throw food away", Score: 250},
+				{ID: "takeaway", Label: "I order takeaway every week", Score: 150},
+				{ID: "bottled_water", Label: "I drink bottled water", Score: 50},
+			},
+		},
+		{
+			ID: "shopping", Category: "consumption", Type: domain.QuestionSingleChoice,
+			Text: "How often do you buy new clothes, gadgets or furniture?",
+			Options: []*domain.Option{
+				{ID: "rarely", Label: "Rarely, I buy second-hand or repair", Score: 400},
+				{ID: "sometimes", Label: "Now and then", Score: 1000},
+				{ID: "often", Label: "Often", Score: 2000},
+			},
+		},
+	},
+}
@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidTask is wrapped by every task validation error
+var ErrInvalidTask = errs.Validation("invalid task")
+
+// maxProofLength bounds the note or URL attached to a completed task
+const maxProofLength = 2000
+
+// DefaultTasks are the built-in catalogue entries of a fresh installation.
+// CO2Saved is a rough estimate in kg CO2e for doing the task once; Repeat
+// matches how long doing it takes.
+var DefaultTasks = []domain.Task{
+	{Title: "Car-free day", Description: "Walk, cycle or take public transport instead of driving for a whole day.", Category: "transport", CO2Saved: 4.0, Difficulty: domain.TaskMedium, Points: 30, Repeat: domain.TaskDaily},
+	{Title: "Cycle to work", Description: "Ride a bike for your commute instead of driving.", Category: "transport", CO2Saved: 2.5, Difficulty: domain.TaskMedium, Points: 25, Repeat: domain.TaskDaily},
+	{Title: "Take the train", Description: "Choose the train over a short-haul flight for your next trip.", Category: "transport", CO2Saved: 60, Difficulty: domain.TaskHard, Points: 100, Repeat: domain.TaskWeekly},
+	{Title: "Meat-free day", Description: "Eat only vegetarian meals for a day.", Category: "food", CO2Saved: 3.5, Difficulty: domain.TaskEasy, Points: 15, Repeat: domain.TaskDaily},
+	{Title: "Vegan week", Description: "Eat only plant-based meals for seven days.", Category: "food", CO2Saved: 25, Difficulty: domain.TaskHard, Points: 80, Repeat: domain.TaskWeekly},
+	{Title: "Zero food waste", Description: "Plan meals and use up leftovers so no food is thrown away this week.", Category: "food", CO2Saved: 3.0, Difficulty: domain.TaskMedium, Points: 20, Repeat: domain.TaskWeekly},
+	{Title: "Lower the thermostat", Description: "Turn the heating down by one degree for a week.", Category: "energy", CO2Saved: 5.0, Difficulty: domain.TaskEasy, Points: 15, Repeat: domain.TaskWeekly},
+	{Title: "Line-dry your laundry", Description: "Skip the tumble dryer for your next load.", Category: "energy", CO2Saved: 1.8, Difficulty: domain.TaskEasy, Points: 10, Repeat: domain.TaskDaily},
+	{Title: "Unplug standby devices", Description: "Switch off chargers and devices at the wall overnight for a week.", Category: "energy", CO2Saved: 0.7, Difficulty: domain.TaskEasy, Points: 5, Repeat: domain.TaskWeekly},
+	{Title: "Reusable bottle and cup", Description: "Use your own bottle and coffee cup every day for a week.", Category: "waste", CO2Saved: 0.8, Difficulty: domain.TaskEasy, Points: 10, Repeat: domain.TaskWeekly},
+	{Title: "Repair instead of replace", Description: "Fix a broken item or buy it second-hand instead of new.", Category: "consumption", CO2Saved: 15, Difficulty: domain.TaskMedium, Points: 40, Repeat: domain.TaskWeekly},
+}
+
+var taskDifficulties = map[string]bool{
+	domain.TaskEasy:   true,
+	domain.TaskMedium: true,
+	domain.TaskHard:   true,
+}
+
+var taskRepeats = map[string]bool{
+	domain.TaskOnce:   true,
+	domain.TaskDaily:  true,
+	domain.TaskWeekly: true,
+}
+
+// TaskService manages the eco task catalogue and users' progress through it.
+// Completing a task awards its points and estimated CO2 savings and puts
+// them on the leaderboards. A user can complete a task once per period of
+// its repeat policy, so awards can't be farmed by completing it over and
+// over.
+type TaskService struct {
+	Repo        domain.TaskService
+	Leaderboard *LeaderboardService
+	// Events receives EventTaskCompleted; while nil nothing is published
+	Events *EventBus
+}
+
+// NewTaskService creates a new task service instance.
+// Panics if the provided repository or leaderboard is nil.
+func NewTaskService(repo domain.TaskService, leaderboard *LeaderboardService) *TaskService {
+	if repo == nil || leaderboard == nil {
+		panic("repository and leaderboard must not be nil")
+	}
+	return &TaskService{
+		Repo:        repo,
+		Leaderboard: leaderboard,
+	}
+}
+
+// SeedDefaults adds DefaultTasks when the catalogue is empty. It reports
+// whether it did.
+func (s *TaskService) SeedDefaults(ctx context.Context) (bool, error) {
+	tasks, err := s.Repo.ListTasks(ctx, domain.TaskFilter{IncludeInactive: true})
+	if err != nil || len(tasks) > 0 {
+		return false, err
+	}
+	for i := range DefaultTasks {
+		task := DefaultTasks[i]
+		task.Active = true
+		if err := s.CreateTask(ctx, &task); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// CreateTask validates a task and adds it to the catalogue
+func (s *TaskService) CreateTask(ctx context.Context, task *domain.Task) error {
+	if err := validateTask(task); err != nil {
+		return err
+	}
+	task.ID = uuid.NewString()
+	return s.Repo.CreateTask(ctx, task)
+}
+
+// UpdateTask validates and replaces a catalogue entry. Completed
+// assignments keep the points they were awarded.
+func (s *TaskService) UpdateTask(ctx context.Context, task *domain.Task) error {
+	if err := validateTask(task); err != nil {
+		return err
+	}
+	return s.Repo.UpdateTask(ctx, task)
+}
+
+func validateTask(task *domain.Task) error {
+	task.Title = strings.TrimSpace(task.Title)
+	task.Description = strings.TrimSpace(task.Description)
+	if task.Repeat == "" {
+		task.Repeat = domain.TaskWeekly
+	}
+	switch {
+	case task.Title == "":
+		return fmt.Errorf("%w: title is required", ErrInvalidTask)
+	case !factorCategory.MatchString(task.Category):
+		return fmt.Errorf("%w: category %q must be lower-case letters, digits or _", ErrInvalidTask, task.Category)
+	case !taskDifficulties[task.Difficulty]:
+		return fmt.Errorf("%w: difficulty must be easy, medium or hard", ErrInvalidTask)
+	case !taskRepeats[task.Repeat]:
+		return fmt.Errorf("%w: repeat must be once, daily or weekly", ErrInvalidTask)
+	case task.CO2Saved < 0:
+		return fmt.Errorf("%w: co2_saved must not be negative", ErrInvalidTask)
+	case task.Points < 0:
+		return fmt.Errorf("%w: points must not be negative", ErrInvalidTask)
+	}
+	return nil
+}
+
+// GetTask returns a catalogue entry
+func (s *TaskService) GetTask(ctx context.Context, id string) (*domain.Task, error) {
+	return s.Repo.GetTask(ctx, id)
+}
+
+// Catalogue lists the tasks users can pick
+func (s *TaskService) Catalogue(ctx context.Context, category, difficulty string) ([]*domain.Task, error) {
+	return s.Repo.ListTasks(ctx, domain.TaskFilter{Category: category, Difficulty: difficulty})
+}
+
+// Assign gives an active catalogue task to the user. It fails with
+// domain.ErrTaskRepeatTooSoon when the user already completed the task in
+// its current repeat period.
+func (s *TaskService) Assign(ctx context.Context, userID, taskID string) (*domain.TaskAssignment, error) {
+	task, err := s.Repo.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if !task.Active {
+		return nil, domain.ErrTaskNotFound
+	}
+	completed, err := s.Repo.ListAssignments(ctx, userID, domain.TaskCompleted)
+	if err != nil {
+		return nil, err
+	}
+	period := task.Period(time.Now())
+	for _, done := range completed {
+		if done.Task.ID == task.ID && done.Period == period {
+			return nil, domain.ErrTaskRepeatTooSoon
+		}
+	}
+	assignment := &domain.TaskAssignment{
+		ID:     uuid.NewString(),
+		UserID: userID,
+		Task:   *task,
+	}
+	if err := s.Repo.Assign(ctx, assignment); err != nil {
+		return nil, err
+	}
+	return assignment, nil
+}
+
+// Assignments lists the user's tasks, optionally only those with status
+func (s *TaskService) Assignments(ctx context.Context, userID, status string) ([]*domain.TaskAssignment, error) {
+	if status != "" && status != domain.TaskAssigned && status != domain.TaskCompleted {
+		return nil, fmt.Errorf("%w: status must be assigned or completed", ErrInvalidTask)
+	}
+	return s.Repo.ListAssignments(ctx, userID, status)
+}
+
+// GetAssignment returns one of the user's tasks
+func (s *TaskService) GetAssignment(ctx context.Context, userID, id string) (*domain.TaskAssignment, error) {
+	return s.Repo.GetAssignment(ctx, userID, id)
+}
+
+// Complete marks one of the user's tasks done with an optional proof and
+// awards its points and CO2 savings. It fails with domain.ErrTaskRepeatTooSoon
+// when the user already completed the task in its current repeat period.
+func (s *TaskService) Complete(ctx context.Context, userID, id, proof string) (*domain.TaskAssignment, error) {
+	proof = strings.TrimSpace(proof)
+	if utf8.RuneCountInString(proof) > maxProofLength {
+		return nil, fmt.Errorf("%w: proof must be at most %d characters", ErrInvalidTask, maxProofLength)
+	}
+	open, err := s.Repo.GetAssignment(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	assignment, err := s.Repo.Complete(ctx, userID, id, proof, open.Task.Period(time.Now()))
+	if err != nil {
+		return nil, err
+	}
+	// The award is stored; a leaderboard that missed it is fixed by a rebuild
+	if err := s.Leaderboard.Record(ctx, assignment); err != nil {
+		log.Printf("Failed to record task assignment %s on the leaderboards: %v", assignment.ID, err)
+	}
+	s.Events.Publish(ctx, domain.Event{Type: domain.EventTaskCompleted, UserID: userID, At: *assignment.CompletedAt})
+	return assignment, nil
+}
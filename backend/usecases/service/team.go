@@ -0,0 +1,625 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrInvalidTeam is wrapped by every team and challenge validation error
+	ErrInvalidTeam = errs.Validation("invalid team")
+	// ErrTeamForbidden is returned when a member's role doesn't allow an action
+	ErrTeamForbidden = errs.Forbidden("your role in this team doesn't allow that")
+)
+
+const (
+	maxTeamName        = 80
+	maxTeamDescription = 500
+	// maxChallengeLength bounds a challenge's time box
+	maxChallengeLength = 366 * 24 * time.Hour
+	// inviteCodeAlphabet leaves out letters and digits that are easy to mix up
+	inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	inviteCodeLength   = 8
+	// inviteCodeAttempts bounds retries when a random invite code is taken
+	inviteCodeAttempts = 3
+)
+
+// teamRoles ranks roles by what they allow
+var teamRoles = map[string]int{
+	domain.TeamRoleMember: 1,
+	domain.TeamRoleAdmin:  2,
+	domain.TeamRoleOwner:  3,
+}
+
+// TeamService manages teams and their challenges. Only members see a team;
+// admins manage invites and challenges and the owner manages roles.
+// Challenges are scored from the task history, so they need no bookkeeping
+// as tasks are completed. Users who blocked each other can't invite each
+// other.
+type TeamService struct {
+	Repo      domain.TeamService
+	Users     domain.UserService
+	Tasks     domain.TaskService
+	Relations domain.SocialService
+	// Privacy decides whose CO2 savings a member sees; while nil only
+	// their own
+	Privacy domain.PrivacyReader
+}
+
+// NewTeamService creates a new team service instance.
+// Panics if any repository is nil.
+func NewTeamService(repo domain.TeamService, users domain.UserService, tasks domain.TaskService, relations domain.SocialService) *TeamService {
+	if repo == nil || users == nil || tasks == nil || relations == nil {
+		panic("repositories must not be nil")
+	}
+	return &TeamService{
+		Repo:      repo,
+		Users:     users,
+		Tasks:     tasks,
+		Relations: relations,
+	}
+}
+
+// blocked reports whether either user blocked the other
+func (s *TeamService) blocked(ctx context.Context, userID, otherID string) (bool, error) {
+	relations, err := s.Relations.Between(ctx, userID, otherID)
+	if err != nil {
+		return false, err
+	}
+	for _, relation := range relations {
+		if relation.Status == domain.RelationBlocked {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// newInviteCode returns a random invite code
+func newInviteCode() (string, error) {
+	raw := make([]byte, inviteCodeLength)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	code := make([]byte, inviteCodeLength)
+	for i, b := range raw {
+		code[i] = inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)]
+	}
+	return string(code), nil
+}
+
+// withNewInviteCode calls save with fresh invite codes until one isn't taken
+func withNewInviteCode(team *domain.Team, save func() error) error {
+	for attempt := 0; ; attempt++ {
+		code, err := newInviteCode()
+		if err != nil {
+			return err
+		}
+		team.InviteCode = code
+		err = save()
+		if !errors.Is(err, domain.ErrTeamConflict) || attempt+1 == inviteCodeAttempts {
+			return err
+		}
+	}
+}
+
+func validateTeam(team *domain.Team) error {
+	team.Name = strings.TrimSpace(team.Name)
+	team.Description = strings.TrimSpace(team.Description)
+	switch {
+	case team.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidTeam)
+	case utf8.RuneCountInString(team.Name) > maxTeamName:
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidTeam, maxTeamName)
+	case utf8.RuneCountInString(team.Description) > maxTeamDescription:
+		return fmt.Errorf("%w: description must be at most %d characters", ErrInvalidTeam, maxTeamDescription)
+	}
+	return nil
+}
+
+// member returns the user's membership of a team, requiring at least role.
+// Non-members get domain.ErrTeamNotFound so teams stay private.
+func (s *TeamService) member(ctx context.Context, userID, teamID, role string) (*domain.TeamMember, error) {
+	member, err := s.Repo.GetMember(ctx, teamID, userID)
+	if errors.Is(err, domain.ErrTeamMemberNotFound) {
+		return nil, domain.ErrTeamNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if teamRoles[member.Role] < teamRoles[role] {
+		return nil, ErrTeamForbidden
+	}
+	return member, nil
+}
+
+// Create creates a team owned by the user
+func (s *TeamService) Create(ctx context.Context, userID, name, description string) (*domain.Team, error) {
+	team := &domain.Team{ID: uuid.NewString(), Name: name, Description: description}
+	if err := validateTeam(team); err != nil {
+		return nil, err
+	}
+	owner := &domain.TeamMember{TeamID: team.ID, UserID: userID, Role: domain.TeamRoleOwner}
+	err := withNewInviteCode(team, func() error {
+		return s.Repo.CreateTeam(ctx, team, owner)
+	})
+	if err != nil {
+		return nil, err
+	}
+	team.Role = owner.Role
+	team.Totals, err = s.totals(ctx, team.ID)
+	if err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// List returns the user's teams, most recently joined first
+func (s *TeamService) List(ctx context.Context, userID string) ([]*domain.Team, error) {
+	teams, err := s.Repo.Teams(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, team := range teams {
+		if teamRoles[team.Role] < teamRoles[domain.TeamRoleAdmin] {
+			team.InviteCode = ""
+		}
+		if team.Totals, err = s.totals(ctx, team.ID); err != nil {
+			return nil, err
+		}
+	}
+	return teams, nil
+}
+
+// Get returns a team of the user's with its totals
+func (s *TeamService) Get(ctx context.Context, userID, teamID string) (*domain.Team, error) {
+	member, err := s.member(ctx, userID, teamID, domain.TeamRoleMember)
+	if err != nil {
+		return nil, err
+	}
+	team, err := s.Repo.GetTeam(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	team.Role = member.Role
+	if teamRoles[team.Role] < teamRoles[domain.TeamRoleAdmin] {
+		team.InviteCode = ""
+	}
+	if team.Totals, err = s.totals(ctx, teamID); err != nil {
+		return nil, err
+	}
+	return team, nil
+}
+
+// Update changes a team's name or description; nil values stay as they are
+func (s *TeamService) Update(ctx context.Context, userID, teamID string, name, description *string) (*domain.Team, error) {
+	if _, err := s.member(ctx, userID, teamID, domain.TeamRoleAdmin); err != nil {
+		return nil, err
+	}
+	team, err := s.Repo.GetTeam(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	if name != nil {
+		team.Name = *name
+	}
+	if description != nil {
+		team.Description = *description
+	}
+	if err := validateTeam(team); err != nil {
+		return nil, err
+	}
+	if err := s.Repo.UpdateTeam(ctx, team); err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID, teamID)
+}
+
+// RotateCode replaces a team's invite code, so the old one stops working
+func (s *TeamService) RotateCode(ctx context.Context, userID, teamID string) (*domain.Team, error) {
+	if _, err := s.member(ctx, userID, teamID, domain.TeamRoleAdmin); err != nil {
+		return nil, err
+	}
+	team, err := s.Repo.GetTeam(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	err = withNewInviteCode(team, func() error {
+		return s.Repo.UpdateTeam(ctx, team)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID, teamID)
+}
+
+// Delete deletes a team with its challenges; only the owner may
+func (s *TeamService) Delete(ctx context.Context, userID, teamID string) error {
+	if _, err := s.member(ctx, userID, teamID, domain.TeamRoleOwner); err != nil {
+		return err
+	}
+	return s.Repo.DeleteTeam(ctx, teamID)
+}
+
+// Invite invites the user at login to a team. Like SocialService, it returns
+// domain.ErrUserNotFound when either user blocked the other.
+func (s *TeamService) Invite(ctx context.Context, userID, teamID, login string) (*domain.TeamInvite, error) {
+	if _, err := s.member(ctx, userID, teamID, domain.TeamRoleAdmin); err != nil {
+		return nil, err
+	}
+	invitee, err := s.Users.Get(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	if blocked, err := s.blocked(ctx, userID, invitee.ID); err != nil {
+		return nil, err
+	} else if blocked {
+		return nil, domain.ErrUserNotFound
+	}
+	if _, err := s.Repo.GetMember(ctx, teamID, invitee.ID); err == nil {
+		return nil, domain.ErrTeamMemberConflict
+	} else if !errors.Is(err, domain.ErrTeamMemberNotFound) {
+		return nil, err
+	}
+	invite := &domain.TeamInvite{TeamID: teamID, UserID: invitee.ID, InvitedBy: userID}
+	if err := s.Repo.SaveInvite(ctx, invite); err != nil {
+		return nil, err
+	}
+	return invite, nil
+}
+
+// Invites lists the user's pending team invites, most recent first. Invites
+// from users who were blocked, or blocked the user, since are left out.
+func (s *TeamService) Invites(ctx context.Context, userID string) ([]*domain.TeamInvite, error) {
+	invites, err := s.Repo.Invites(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	visible := make([]*domain.TeamInvite, 0, len(invites))
+	for _, invite := range invites {
+		if blocked, err := s.blocked(ctx, userID, invite.InvitedBy); err != nil {
+			return nil, err
+		} else if blocked {
+			continue
+		}
+		team, err := s.Repo.GetTeam(ctx, invite.TeamID)
+		if err != nil {
+			return nil, err
+		}
+		invite.TeamName = team.Name
+		visible = append(visible, invite)
+	}
+	return visible, nil
+}
+
+// AcceptInvite makes the user a member of a team that invited them
+func (s *TeamService) AcceptInvite(ctx context.Context, userID, teamID string) (*domain.Team, error) {
+	invites, err := s.Invites(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, invite := range invites {
+		if invite.TeamID == teamID {
+			return s.join(ctx, userID, teamID)
+		}
+	}
+	return nil, domain.ErrTeamInviteNotFound
+}
+
+// DeclineInvite turns down an invite to a team
+func (s *TeamService) DeclineInvite(ctx context.Context, userID, teamID string) error {
+	return s.Repo.DeleteInvite(ctx, teamID, userID)
+}
+
+// Join makes the user a member of the team with an invite code
+func (s *TeamService) Join(ctx context.Context, userID, code string) (*domain.Team, error) {
+	team, err := s.Repo.TeamByCode(ctx, strings.ToUpper(strings.TrimSpace(code)))
+	if err != nil {
+		return nil, err
+	}
+	return s.join(ctx, userID, team.ID)
+}
+
+func (s *TeamService) join(ctx context.Context, userID, teamID string) (*domain.Team, error) {
+	err := s.Repo.AddMember(ctx, &domain.TeamMember{TeamID: teamID, UserID: userID, Role: domain.TeamRoleMember})
+	if err != nil {
+		return nil, err
+	}
+	return s.Get(ctx, userID, teamID)
+}
+
+// named fills in members' logins and totals from their user records,
+// dropping members whose accounts were deleted
+func (s *TeamService) named(ctx context.Context, members []*domain.TeamMember) ([]*domain.TeamMember, error) {
+	named := make([]*domain.TeamMember, 0, len(members))
+	for _, member := range members {
+		user, err := s.Users.GetByID(ctx, member.UserID)
+		if errors.Is(err, domain.ErrUserNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		member.Login = user.Login
+		member.Points = user.Points
+		member.CO2Saved = user.CO2Saved
+		named = append(named, member)
+	}
+	return named, nil
+}
+
+// members returns a team's members with their logins and totals
+func (s *TeamService) members(ctx context.Context, teamID string) ([]*domain.TeamMember, error) {
+	members, err := s.Repo.Members(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	return s.named(ctx, members)
+}
+
+// totals adds up what a team's members were awarded over all time
+func (s *TeamService) totals(ctx context.Context, teamID string) (*domain.TeamTotals, error) {
+	members, err := s.members(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	totals := &domain.TeamTotals{Members: len(members)}
+	for _, member := range members {
+		totals.Points += member.Points
+		totals.CO2Saved += member.CO2Saved
+	}
+	totals.CO2Saved = roundGram(totals.CO2Saved)
+	return totals, nil
+}
+
+// Members lists a team's members in the order they joined, without the CO2
+// savings of those who don't share them with the user
+func (s *TeamService) Members(ctx context.Context, userID, teamID string) ([]*domain.TeamMember, error) {
+	if _, err := s.member(ctx, userID, teamID, domain.TeamRoleMember); err != nil {
+		return nil, err
+	}
+	members, err := s.members(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	privacy, err := newCO2Privacy(ctx, s.Privacy, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, member := range members {
+		shows, err := privacy.shows(ctx, member.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if !shows {
+			member.CO2Saved = 0
+			member.Private = true
+		}
+	}
+	return members, nil
+}
+
+// SetRole changes the role of the member at login; only the owner may.
+// Making someone else the owner hands over ownership, leaving the previous
+// owner an admin.
+func (s *TeamService) SetRole(ctx context.Context, userID, teamID, login, role string) error {
+	if _, err := s.member(ctx, userID, teamID, domain.TeamRoleOwner); err != nil {
+		return err
+	}
+	if _, ok := teamRoles[role]; !ok {
+		return fmt.Errorf("%w: role must be %s, %s or %s", ErrInvalidTeam, domain.TeamRoleOwner, domain.TeamRoleAdmin, domain.TeamRoleMember)
+	}
+	target, err := s.Users.Get(ctx, login)
+	if err != nil {
+		return err
+	}
+	if target.ID == userID {
+		return fmt.Errorf("%w: hand over ownership to change your own role", ErrInvalidTeam)
+	}
+	if err := s.Repo.SetRole(ctx, teamID, target.ID, role); err != nil {
+		return err
+	}
+	if role == domain.TeamRoleOwner {
+		return s.Repo.SetRole(ctx, teamID, userID, domain.TeamRoleAdmin)
+	}
+	return nil
+}
+
+// RemoveMember removes the member at login from a team, or lets the user
+// leave it. Admins can remove members and the owner anyone; the owner
+// can't leave without handing over ownership first.
+func (s *TeamService) RemoveMember(ctx context.Context, userID, teamID, login string) error {
+	me, err := s.member(ctx, userID, teamID, domain.TeamRoleMember)
+	if err != nil {
+		return err
+	}
+	target, err := s.Users.Get(ctx, login)
+	if err != nil {
+		return err
+	}
+	if target.ID == userID {
+		if me.Role == domain.TeamRoleOwner {
+			return fmt.Errorf("%w: hand over ownership or delete the team before leaving it", ErrInvalidTeam)
+		}
+		return s.Repo.RemoveMember(ctx, teamID, userID)
+	}
+
+	member, err := s.Repo.GetMember(ctx, teamID, target.ID)
+	if err != nil {
+		return err
+	}
+	if teamRoles[me.Role] < teamRoles[domain.TeamRoleAdmin] || teamRoles[member.Role] >= teamRoles[me.Role] {
+		return ErrTeamForbidden
+	}
+	return s.Repo.RemoveMember(ctx, teamID, target.ID)
+}
+
+// CreateChallenge starts a challenge towards a shared target in a team.
+// A zero start means now; Metric defaults to CO2 saved.
+func (s *TeamService) CreateChallenge(ctx context.Context, userID, teamID string, challenge *domain.Challenge, now time.Time) (*domain.Challenge, error) {
+	if _, err := s.member(ctx, userID, teamID, domain.TeamRoleAdmin); err != nil {
+		return nil, err
+	}
+	challenge.ID = uuid.NewString()
+	challenge.TeamID = teamID
+	challenge.CreatedBy = userID
+	challenge.Title = strings.TrimSpace(challenge.Title)
+	challenge.Description = strings.TrimSpace(challenge.Description)
+	if challenge.Metric == "" {
+		challenge.Metric = domain.MetricCO2Saved
+	}
+	if challenge.StartsAt.IsZero() {
+		challenge.StartsAt = now
+	}
+	switch {
+	case challenge.Title == "":
+		return nil, fmt.Errorf("%w: title is required", ErrInvalidTeam)
+	case utf8.RuneCountInString(challenge.Title) > maxTeamName:
+		return nil, fmt.Errorf("%w: title must be at most %d characters", ErrInvalidTeam, maxTeamName)
+	case utf8.RuneCountInString(challenge.Description) > maxTeamDescription:
+		return nil, fmt.Errorf("%w: description must be at most %d characters", ErrInvalidTeam, maxTeamDescription)
+	case challenge.Metric != domain.MetricPoints && challenge.Metric != domain.MetricCO2Saved:
+		return nil, fmt.Errorf("%w: metric must be %s or %s", ErrInvalidTeam, domain.MetricPoints, domain.MetricCO2Saved)
+	case !(challenge.Target > 0):
+		return nil, fmt.Errorf("%w: target must be positive", ErrInvalidTeam)
+	case !challenge.EndsAt.After(challenge.StartsAt):
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at", ErrInvalidTeam)
+	case !challenge.EndsAt.After(now):
+		return nil, fmt.Errorf("%w: ends_at must be in the future", ErrInvalidTeam)
+	case challenge.EndsAt.Sub(challenge.StartsAt) > maxChallengeLength:
+		return nil, fmt.Errorf("%w: a challenge can last at most a year", ErrInvalidTeam)
+	}
+	if err := s.Repo.CreateChallenge(ctx, challenge); err != nil {
+		return nil, err
+	}
+	return challenge, s.score(ctx, challenge, now, userID)
+}
+
+// Challenges lists a team's challenges with their progress, latest start first
+func (s *TeamService) Challenges(ctx context.Context, userID, teamID string, now time.Time) ([]*domain.Challenge, error) {
+	if _, err := s.member(ctx, userID, teamID, domain.TeamRoleMember); err != nil {
+		return nil, err
+	}
+	challenges, err := s.Repo.Challenges(ctx, teamID)
+	if err != nil {
+		return nil, err
+	}
+	for _, challenge := range challenges {
+		if err := s.score(ctx, challenge, now, ""); err != nil {
+			return nil, err
+		}
+	}
+	return challenges, nil
+}
+
+// Challenge returns a team's challenge with its progress and leaderboard
+func (s *TeamService) Challenge(ctx context.Context, userID, teamID, challengeID string, now time.Time) (*domain.Challenge, error) {
+	if _, err := s.member(ctx, userID, teamID, domain.TeamRoleMember); err != nil {
+		return nil, err
+	}
+	challenge, err := s.Repo.GetChallenge(ctx, teamID, challengeID)
+	if err != nil {
+		return nil, err
+	}
+	return challenge, s.score(ctx, challenge, now, userID)
+}
+
+// DeleteChallenge deletes a team's challenge
+func (s *TeamService) DeleteChallenge(ctx context.Context, userID, teamID, challengeID string) error {
+	if _, err := s.member(ctx, userID, teamID, domain.TeamRoleAdmin); err != nil {
+		return err
+	}
+	return s.Repo.DeleteChallenge(ctx, teamID, challengeID)
+}
+
+// score fills in a challenge's status and the progress its team's current
+// members made, and when viewerID is set also ranks them by their share on
+// a leaderboard as seen by that member. Members who haven't scored yet are
+// listed last with rank 0.
+func (s *TeamService) score(ctx context.Context, challenge *domain.Challenge, now time.Time, viewerID string) error {
+	switch {
+	case now.Before(challenge.StartsAt):
+		challenge.Status = domain.ChallengeUpcoming
+	case now.Before(challenge.EndsAt):
+		challenge.Status = domain.ChallengeActive
+	default:
+		challenge.Status = domain.ChallengeFinished
+	}
+
+	members, err := s.members(ctx, challenge.TeamID)
+	if err != nil {
+		return err
+	}
+	entries := make(map[string]*domain.LeaderboardEntry, len(members))
+	for _, member := range members {
+		entries[member.UserID] = &domain.LeaderboardEntry{UserID: member.UserID, Name: member.Login}
+	}
+	if challenge.Status != domain.ChallengeUpcoming {
+		end := challenge.EndsAt
+		if now.Before(end) {
+			end = now
+		}
+		awards, err := s.Tasks.Awards(ctx, challenge.StartsAt, end)
+		if err != nil {
+			return err
+		}
+		for _, award := range awards {
+			if entry, ok := entries[award.UserID]; ok {
+				entry.Points = award.Points
+				entry.CO2Saved = roundGram(award.CO2Saved)
+			}
+		}
+	}
+
+	value := func(entry *domain.LeaderboardEntry) float64 {
+		if challenge.Metric == domain.MetricPoints {
+			return float64(entry.Points)
+		}
+		return entry.CO2Saved
+	}
+	challenge.Progress = 0
+	for _, entry := range entries {
+		challenge.Progress += value(entry)
+	}
+	challenge.Progress = roundGram(challenge.Progress)
+	if viewerID == "" {
+		return nil
+	}
+
+	challenge.Leaderboard = make([]*domain.LeaderboardEntry, 0, len(entries))
+	for _, entry := range entries {
+		challenge.Leaderboard = append(challenge.Leaderboard, entry)
+	}
+	sort.Slice(challenge.Leaderboard, func(i, j int) bool {
+		a, b := challenge.Leaderboard[i], challenge.Leaderboard[j]
+		if value(a) != value(b) {
+			return value(a) > value(b)
+		}
+		return a.Name < b.Name
+	})
+	privacy, err := newCO2Privacy(ctx, s.Privacy, viewerID)
+	if err != nil {
+		return err
+	}
+	for i, entry := range challenge.Leaderboard {
+		if value(entry) > 0 {
+			entry.Rank = int64(i + 1)
+		}
+		shows, err := privacy.shows(ctx, entry.UserID)
+		if err != nil {
+			return err
+		}
+		if !shows {
+			privacy.hide(entry, challenge.Metric)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/repository/ram_storage"
+	"github.com/aygoko/EcoMInd/backend/usecases/service"
+)
+
+func TestTeamServiceInviteRespectsBlocks(t *testing.T) {
+	tests := []struct {
+		name    string
+		blocker string // "owner" or "invitee"; "" blocks nobody
+		// blockAfter blocks once the invite was sent instead of before
+		blockAfter bool
+	}{
+		{"no block", "", false},
+		{"owner blocked invitee", "owner", false},
+		{"invitee blocked owner", "invitee", false},
+		{"invitee blocked owner after the invite", "invitee", true},
+		{"owner blocked invitee after the invite", "owner", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			users := ram_storage.NewUserStorage()
+			relations := ram_storage.NewSocialStorage()
+			social := service.NewSocialService(relations, users, ram_storage.NewActivityStorage(users))
+			teams := service.NewTeamService(ram_storage.NewTeamStorage(), users, ram_storage.NewTaskStorage(users), relations)
+
+			owner := &domain.User{ID: service.GenerateUserID(), Login: "owner"}
+			invitee := &domain.User{ID: service.GenerateUserID(), Login: "invitee"}
+			for _, user := range []*domain.User{owner, invitee} {
+				if err := users.Create(ctx, user); err != nil {
+					t.Fatalf("Create(%s): %v", user.Login, err)
+				}
+			}
+			team, err := teams.Create(ctx, owner.ID, "Green team", "")
+			if err != nil {
+				t.Fatalf("Create team: %v", err)
+			}
+			block := func() {
+				var err error
+				switch tt.blocker {
+				case "owner":
+					err = social.Block(ctx, owner.ID, invitee.Login)
+				case "invitee":
+					err = social.Block(ctx, invitee.ID, owner.Login)
+				}
+				if err != nil {
+					t.Fatalf("Block: %v", err)
+				}
+			}
+
+			if !tt.blockAfter {
+				block()
+			}
+			_, err = teams.Invite(ctx, owner.ID, team.ID, invitee.Login)
+			if tt.blocker != "" && !tt.blockAfter {
+				if !errors.Is(err, domain.ErrUserNotFound) {
+					t.Fatalf("Invite error = %v, want ErrUserNotFound", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Invite: %v", err)
+			}
+			if tt.blockAfter {
+				block()
+			}
+
+			invites, err := teams.Invites(ctx, invitee.ID)
+			if err != nil {
+				t.Fatalf("Invites: %v", err)
+			}
+			_, acceptErr := teams.AcceptInvite(ctx, invitee.ID, team.ID)
+			if tt.blocker == "" {
+				if len(invites) != 1 || invites[0].TeamName != "Green team" {
+					t.Errorf("Invites = %v, want the team's invite", invites)
+				}
+				if acceptErr != nil {
+					t.Errorf("AcceptInvite: %v", acceptErr)
+				}
+				return
+			}
+			if len(invites) != 0 {
+				t.Errorf("Invites = %v, want none", invites)
+			}
+			if !errors.Is(acceptErr, domain.ErrTeamInviteNotFound) {
+				t.Errorf("AcceptInvite error = %v, want ErrTeamInviteNotFound", acceptErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aygoko/EcoMInd/backend/config"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Token types, carried in the "typ" claim so a token minted for one purpose
+// can't be used for another
+const (
+	TokenTypeAccess            = "access"
+	TokenTypeEmailVerification = "email_verification"
+	TokenTypePasswordReset     = "password_reset"
+)
+
+// ErrInvalidToken is returned for tokens that fail any signature or claim check
+var ErrInvalidToken = errs.Unauthorized("invalid token")
+
+// Claims are the JWT claims issued by the backend
+type Claims struct {
+	UserID    string `json:"user_id"`
+	SessionID string `json:"sid,omitempty"`
+	Email     string `json:"email,omitempty"` // the address an emailed token was sent to
+	Type      string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// TokenService signs and verifies JWTs with the configured algorithm.
+type TokenService struct {
+	Config    config.JWTConfig
+	method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// NewTokenService creates a token service, loading the RSA key for RS256.
+func NewTokenService(cfg config.JWTConfig) (*TokenService, error) {
+	s := &TokenService{Config: cfg}
+	switch cfg.Algorithm {
+	case "HS256":
+		s.method = jwt.SigningMethodHS256
+		s.signKey = []byte(cfg.Secret)
+		s.verifyKey = []byte(cfg.Secret)
+	case "RS256":
+		pemBytes, err := os.ReadFile(cfg.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read jwt private key: %w", err)
+		}
+		key, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse jwt private key: %w", err)
+		}
+		s.method = jwt.SigningMethodRS256
+		s.signKey = key
+		s.verifyKey = &key.PublicKey
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", cfg.Algorithm)
+	}
+	return s, nil
+}
+
+// Issue signs a token of the given type for the user. Access tokens carry
+// the ID of the session they were issued for.
+func (s *TokenService) Issue(userID, sessionID, tokenType string, ttl time.Duration) (string, error) {
+	return s.sign(Claims{UserID: userID, SessionID: sessionID, Type: tokenType}, "", ttl)
+}
+
+// IssueOneTime signs a token of the given type for an emailed link. id, the
+// jti claim, lets the caller track whether the token was used; email is the
+// address the link goes to.
+func (s *TokenService) IssueOneTime(userID, tokenType, id, email string, ttl time.Duration) (string, error) {
+	return s.sign(Claims{UserID: userID, Email: email, Type: tokenType}, id, ttl)
+}
+
+// sign fills in the registered claims and signs claims
+func (s *TokenService) sign(claims Claims, id string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        id,
+		Issuer:    s.Config.Issuer,
+		Subject:   claims.UserID,
+		Audience:  jwt.ClaimStrings{s.Config.Audience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+	}
+	return jwt.NewWithClaims(s.method, claims).SignedString(s.signKey)
+}
+
+// Parse verifies the signature, exp, nbf, iss, aud and type of a token and
+// returns its claims. Every failure is reported as ErrInvalidToken.
+func (s *TokenService) Parse(tokenString, tokenType string) (*Claims, error) {
+	var claims Claims
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{s.method.Alg()}))
+	_, err := parser.ParseWithClaims(tokenString, &claims, func(*jwt.Token) (interface{}, error) {
+		return s.verifyKey, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	now := time.Now()
+	switch {
+	case !claims.VerifyExpiresAt(now, true):
+		return nil, fmt.Errorf("%w: missing or past exp", ErrInvalidToken)
+	case !claims.VerifyNotBefore(now, false):
+		return nil, fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	case !claims.VerifyIssuer(s.Config.Issuer, true):
+		return nil, fmt.Errorf("%w: wrong issuer", ErrInvalidToken)
+	case !claims.VerifyAudience(s.Config.Audience, true):
+		return nil, fmt.Errorf("%w: wrong audience", ErrInvalidToken)
+	case claims.Type != tokenType:
+		return nil, fmt.Errorf("%w: expected a %s token", ErrInvalidToken, tokenType)
+	case claims.UserID == "":
+		return nil, fmt.Errorf("%w: missing user_id", ErrInvalidToken)
+	}
+	return &claims, nil
+}
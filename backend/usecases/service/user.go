@@ -1,36 +1,128 @@
 package service
 
 import (
-    "github.com/aygoko/EcoMind/backend/domain"
+    "context"
+    "strings"
+
+    "github.com/aygoko/EcoMInd/backend/domain"
+    "github.com/aygoko/EcoMInd/backend/usecases/validate"
+    "github.com/google/uuid"
+    "golang.org/x/crypto/bcrypt"
 )
 
 // UserService implements business logic for user operations.
 type UserService struct {
-    Repo domain.UserService
+    Repo        domain.UserService
+    PhoneRegion string // region of phone numbers without a country code
 }
 
-// NewUserService creates a new user service instance.
+// NewUserService creates a new user service instance. Phone numbers without
+// a country code are read as numbers of phoneRegion.
 // Panics if the provided repository is nil.
-func NewUserService(repo domain.UserService) *UserService {
+func NewUserService(repo domain.UserService, phoneRegion string) *UserService {
     if repo == nil {
         panic("repository must not be nil")
     }
     return &UserService{
-        Repo: repo,
+        Repo:        repo,
+        PhoneRegion: phoneRegion,
+    }
+}
+
+// GenerateUserID returns a new random user ID.
+func GenerateUserID() string {
+    return uuid.NewString()
+}
+
+// Create registers a new user with a bcrypt-hashed password. Input is checked
+// and normalised by package validate first, so differently written emails
+// and phone numbers of one person collide.
+// Returns a *domain.ConflictError if the login, email or phone number is taken.
+func (s *UserService) Create(ctx context.Context, login, email, phoneNumber, password string) (*domain.User, error) {
+    v := validate.New(ctx)
+    login = v.Login("login", login)
+    email = v.Email("email", email)
+    phoneNumber = v.Phone("phone_number", phoneNumber, s.PhoneRegion)
+    v.Password("password", password, login, emailName(email))
+    if err := v.Err(); err != nil {
+        return nil, err
+    }
+
+    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+    if err != nil {
+        return nil, err
+    }
+
+    user := &domain.User{
+        ID:          GenerateUserID(),
+        Login:       login,
+        Email:       email,
+        PhoneNumber: phoneNumber,
+        Password:    string(hashedPassword),
+    }
+    if err := s.Repo.Create(ctx, user); err != nil {
+        return nil, err
     }
+    return user, nil
+}
+
+// Update changes the user's email and phone number where the update sets
+// them, validated and normalised like in Create. user is updated in place.
+// Returns a *domain.ConflictError if the new email or phone number is taken.
+func (s *UserService) Update(ctx context.Context, user *domain.User, update domain.UserUpdate) error {
+    updated := *user
+    v := validate.New(ctx)
+    if update.Email != nil {
+        updated.Email = v.Email("email", *update.Email)
+        // A new address has to be verified again
+        updated.EmailVerified = user.EmailVerified && updated.Email == user.Email
+    }
+    if update.PhoneNumber != nil {
+        updated.PhoneNumber = v.Phone("phone_number", *update.PhoneNumber, s.PhoneRegion)
+    }
+    if err := v.Err(); err != nil {
+        return err
+    }
+
+    if err := s.Repo.UpdateUser(ctx, &updated); err != nil {
+        return err
+    }
+    *user = updated
+    return nil
+}
+
+// emailName returns the part of an email address before the @
+func emailName(email string) string {
+    name, _, _ := strings.Cut(email, "@")
+    return name
+}
+
+// GetByID retrieves a user by ID.
+func (s *UserService) GetByID(ctx context.Context, id string) (*domain.User, error) {
+    return s.Repo.GetByID(ctx, id)
 }
 
 // Get retrieves a user by login.
-func (s *UserService) Get(login string) (*domain.User, error) {
-    return s.Repo.Get(login)
+func (s *UserService) Get(ctx context.Context, login string) (*domain.User, error) {
+    return s.Repo.Get(ctx, login)
 }
 
 // GetByEmail retrieves a user by email.
-func (s *UserService) GetByEmail(email string) (*domain.User, error) {
-    return s.Repo.GetByEmail(email)
+func (s *UserService) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
+    return s.Repo.GetByEmail(ctx, email)
 }
 
 // GetByPhoneNumber retrieves a user by phone number.
-func (s *UserService) GetByPhoneNumber(phoneNumber string) (*domain.User, error) {
-    return s.Repo.GetByPhoneNumber(phoneNumber)
-}
\ No newline at end of file
+func (s *UserService) GetByPhoneNumber(ctx context.Context, phoneNumber string) (*domain.User, error) {
+    return s.Repo.GetByPhoneNumber(ctx, phoneNumber)
+}
+
+// Delete soft-deletes a user by login.
+func (s *UserService) Delete(ctx context.Context, login string) error {
+    return s.Repo.Delete(ctx, login)
+}
+
+// Restore reactivates a soft-deleted user by login.
+func (s *UserService) Restore(ctx context.Context, login string) error {
+    return s.Repo.Restore(ctx, login)
+}
@@ -0,0 +1,68 @@
+package validate
+
+import "fmt"
+
+// Codes of the rules a field can break. They are stable for clients that
+// word messages themselves.
+const (
+	CodeRequired         = "required"
+	CodeTooShort         = "too_short"
+	CodeTooLong          = "too_long"
+	CodeTooLongBytes     = "too_long_bytes"
+	CodeLoginCharset     = "login_charset"
+	CodeLoginStart       = "login_start"
+	CodeInvalidEmail     = "invalid_email"
+	CodeInvalidPhone     = "invalid_phone"
+	CodeWeakPassword     = "weak_password"
+	CodePersonalPassword = "personal_password"
+
+	// codeInvalid is the summary of a failed validation
+	codeInvalid = "invalid"
+)
+
+// Languages are the languages messages are written in, the default first
+var Languages = []string{"en", "ru"}
+
+// messages holds the message of every code per language. Messages take the
+// arguments passed to Validator.Fail.
+var messages = map[string]map[string]string{
+	"en": {
+		codeInvalid:          "some fields are invalid",
+		CodeRequired:         "is required",
+		CodeTooShort:         "must be at least %d characters long",
+		CodeTooLong:          "must be at most %d characters long",
+		CodeTooLongBytes:     "must be at most %d bytes long",
+		CodeLoginCharset:     "may only contain Latin letters, digits, '.', '_' and '-'",
+		CodeLoginStart:       "must start with a letter or a digit",
+		CodeInvalidEmail:     "must be a valid email address",
+		CodeInvalidPhone:     "must be a phone number with its country code, like +79001234567",
+		CodeWeakPassword:     "must contain at least one letter and one digit",
+		CodePersonalPassword: "must not contain your login or email",
+	},
+	"ru": {
+		codeInvalid:          "некоторые поля заполнены неверно",
+		CodeRequired:         "обязательное поле",
+		CodeTooShort:         "должно содержать не менее %d символов",
+		CodeTooLong:          "должно содержать не более %d символов",
+		CodeTooLongBytes:     "должно занимать не более %d байт",
+		CodeLoginCharset:     "может содержать только латинские буквы, цифры, «.», «_» и «-»",
+		CodeLoginStart:       "должно начинаться с буквы или цифры",
+		CodeInvalidEmail:     "должно быть корректным адресом электронной почты",
+		CodeInvalidPhone:     "должно быть номером телефона с кодом страны, например +79001234567",
+		CodeWeakPassword:     "должно содержать хотя бы одну букву и одну цифру",
+		CodePersonalPassword: "не должно содержать ваш логин или адрес почты",
+	},
+}
+
+// message returns the message of code in language, falling back to the
+// default language
+func message(language, code string, args ...interface{}) string {
+	format, ok := messages[language][code]
+	if !ok {
+		format = messages[Languages[0]][code]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
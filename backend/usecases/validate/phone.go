@@ -0,0 +1,107 @@
+package validate
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// e164 matches a phone number in E.164 form: a + and up to 15 digits
+var e164 = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// phoneRegion describes how numbers of a region are dialled without the
+// country code
+type phoneRegion struct {
+	code   string // country calling code
+	trunk  string // national prefix, as in 8 900 123-45-67
+	digits []int  // lengths of a national number without the trunk prefix
+}
+
+// national reports whether number is a national number of the region
+// without the trunk prefix. None of them start with 0, which is how a trunk
+// prefix 0 is told apart from the number.
+func (r phoneRegion) national(number string) bool {
+	if number == "" || number[0] == '0' {
+		return false
+	}
+	for _, n := range r.digits {
+		if len(number) == n {
+			return true
+		}
+	}
+	return false
+}
+
+// phoneRegions are the regions NormalizePhone can read national numbers of
+var phoneRegions = map[string]phoneRegion{
+	"RU": {code: "7", trunk: "8", digits: []int{10}},
+	"KZ": {code: "7", trunk: "8", digits: []int{10}},
+	"BY": {code: "375", trunk: "80", digits: []int{9}},
+	// Mobile numbers are dialled without a trunk prefix, landlines with one
+	"CN": {code: "86", trunk: "0", digits: []int{10, 11}},
+	"US": {code: "1", trunk: "1", digits: []int{10}},
+	"GB": {code: "44", trunk: "0", digits: []int{10}},
+}
+
+// PhoneRegionSupported reports whether NormalizePhone can read national
+// numbers of region
+func PhoneRegionSupported(region string) bool {
+	_, ok := phoneRegions[region]
+	return ok
+}
+
+// PhoneRegions lists the regions NormalizePhone can read national numbers
+// of, sorted
+func PhoneRegions() []string {
+	regions := make([]string, 0, len(phoneRegions))
+	for region := range phoneRegions {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	return regions
+}
+
+// NormalizePhone returns phone in E.164 form, so that every way of writing
+// a number stores and finds the same one: "+7 (900) 123-45-67",
+// "8 900 123 45 67" and "79001234567" all become "+79001234567" in region RU.
+// Numbers starting with + or the international prefix 00 keep their country
+// code; others are read as national numbers of region, with or without its
+// trunk prefix or country code. It reports false for anything else.
+func NormalizePhone(phone, region string) (string, bool) {
+	international := strings.HasPrefix(phone, "+")
+	var digits strings.Builder
+	for i, r := range phone {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')' || (r == '+' && i == 0):
+		default:
+			return "", false
+		}
+	}
+	number := digits.String()
+
+	if !international {
+		if rest, ok := strings.CutPrefix(number, "00"); ok {
+			number = rest
+		} else if local, ok := phoneRegions[region]; ok {
+			switch {
+			case strings.HasPrefix(number, local.trunk) && local.national(number[len(local.trunk):]):
+				number = local.code + number[len(local.trunk):]
+			case local.national(number):
+				number = local.code + number
+			case strings.HasPrefix(number, local.code) && local.national(number[len(local.code):]):
+			default:
+				return "", false
+			}
+		} else {
+			return "", false
+		}
+	}
+
+	number = "+" + number
+	if !e164.MatchString(number) {
+		return "", false
+	}
+	return number, true
+}
@@ -0,0 +1,75 @@
+package validate
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		region string
+		phone  string
+		want   string // "" when the number is rejected
+	}{
+		// Every way of writing a number is the same number
+		{"RU", "+7 (900) 123-45-67", "+79001234567"},
+		{"RU", "8 900 123 45 67", "+79001234567"},
+		{"RU", "89001234567", "+79001234567"},
+		{"RU", "79001234567", "+79001234567"},
+		{"RU", "900 123-45-67", "+79001234567"},
+		{"RU", "8 800 555 35 35", "+78005553535"},
+		{"RU", "900 123-45", ""},
+
+		{"KZ", "8 701 123 45 67", "+77011234567"},
+		{"KZ", "701 123 45 67", "+77011234567"},
+		{"KZ", "+7 701 123 45 67", "+77011234567"},
+
+		{"BY", "80 29 123-45-67", "+375291234567"},
+		{"BY", "29 123-45-67", "+375291234567"},
+		{"BY", "375 29 123-45-67", "+375291234567"},
+		{"BY", "+375 (29) 123-45-67", "+375291234567"},
+
+		// Mobile numbers have no trunk prefix, landlines drop theirs
+		{"CN", "138 1234 5678", "+8613812345678"},
+		{"CN", "86 138 1234 5678", "+8613812345678"},
+		{"CN", "010 1234 5678", "+861012345678"},
+		{"CN", "0755 1234 5678", "+8675512345678"},
+		{"CN", "+86 10 1234 5678", "+861012345678"},
+		{"CN", "010 123 456", ""},
+
+		{"US", "(212) 555-1234", "+12125551234"},
+		{"US", "1 212 555 1234", "+12125551234"},
+		{"US", "+1 212-555-1234", "+12125551234"},
+		{"US", "555-1234", ""},
+
+		{"GB", "020 7946 0018", "+442079460018"},
+		{"GB", "07700 900123", "+447700900123"},
+		{"GB", "44 7700 900123", "+447700900123"},
+		{"GB", "+44 20 7946 0018", "+442079460018"},
+		{"GB", "7946 0018", ""},
+
+		// International numbers keep their country code in any region
+		{"RU", "+44 20 7946 0018", "+442079460018"},
+		{"US", "0049 30 123456", "+4930123456"},
+		{"", "+375 29 123-45-67", "+375291234567"},
+		{"", "8 900 123 45 67", ""},
+		{"RU", "+0 900 123 45 67", ""},
+		{"RU", "+7 900 123 45 67 89 01 23", ""},
+		{"RU", "+7 900 CALL-NOW", ""},
+		{"RU", "8 900 123+45-67", ""},
+	}
+	for _, tt := range tests {
+		got, ok := NormalizePhone(tt.phone, tt.region)
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("NormalizePhone(%q, %q) = %q, %v, want %q", tt.phone, tt.region, got, ok, tt.want)
+		}
+	}
+
+	// Every supported region is covered above
+	covered := map[string]bool{}
+	for _, tt := range tests {
+		covered[tt.region] = true
+	}
+	for _, region := range PhoneRegions() {
+		if !covered[region] {
+			t.Errorf("no NormalizePhone cases for region %s", region)
+		}
+	}
+}
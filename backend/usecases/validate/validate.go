@@ -0,0 +1,192 @@
+// Package validate checks and normalises user input in the service layer.
+// A Validator collects one error per field, with messages in the caller's
+// language, and reports them together as an errs.Validation error.
+package validate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/aygoko/EcoMInd/backend/domain"
+	"github.com/aygoko/EcoMInd/backend/domain/errs"
+)
+
+// Login and password rules
+const (
+	MinLoginLength    = 3
+	MaxLoginLength    = 32
+	MinPasswordLength = 10
+	// MaxPasswordLength is in bytes: bcrypt ignores everything after 72
+	MaxPasswordLength = 72
+	maxEmailLength    = 254
+)
+
+var (
+	loginCharset = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+	emailDomain  = regexp.MustCompile(`^[a-z0-9-]+(\.[a-z0-9-]+)+$`)
+)
+
+// Validator collects the field errors of one request
+type Validator struct {
+	language string
+	fields   []errs.FieldError
+}
+
+// New returns a Validator writing messages in the language of ctx
+func New(ctx context.Context) *Validator {
+	return &Validator{language: domain.LanguageFromContext(ctx)}
+}
+
+// Fail records that field breaks the rule code. args fill in the rule's
+// message.
+func (v *Validator) Fail(field, code string, args ...interface{}) {
+	v.fields = append(v.fields, errs.FieldError{
+		Field:   field,
+		Code:    code,
+		Message: message(v.language, code, args...),
+	})
+}
+
+// Valid reports whether no field has failed yet
+func (v *Validator) Valid() bool {
+	return len(v.fields) == 0
+}
+
+// Err returns the collected field errors as one validation error, or nil
+func (v *Validator) Err() error {
+	if v.Valid() {
+		return nil
+	}
+	return errs.Validation(message(v.language, codeInvalid), v.fields...)
+}
+
+// Required records a required error when value is empty and reports whether
+// it isn't
+func (v *Validator) Required(field, value string) bool {
+	if value == "" {
+		v.Fail(field, CodeRequired)
+		return false
+	}
+	return true
+}
+
+// Login checks a login chosen by a user and returns it without surrounding
+// space. Logins appear in URLs, so they are restricted to ASCII letters,
+// digits, '.', '_' and '-' and start with a letter or digit.
+func (v *Validator) Login(field, login string) string {
+	login = strings.TrimSpace(login)
+	if !v.Required(field, login) {
+		return login
+	}
+	switch {
+	case len(login) < MinLoginLength:
+		v.Fail(field, CodeTooShort, MinLoginLength)
+	case len(login) > MaxLoginLength:
+		v.Fail(field, CodeTooLong, MaxLoginLength)
+	case !loginCharset.MatchString(login):
+		v.Fail(field, CodeLoginCharset)
+	case !isAlphanumeric(rune(login[0])):
+		v.Fail(field, CodeLoginStart)
+	}
+	return login
+}
+
+// ProviderLogin derives the login of a user signing up through an OAuth
+// provider from the provider's name and the account ID there. IDs that would
+// break the login rules are replaced by a hash of them.
+func ProviderLogin(provider, subject string) string {
+	login := provider + "_" + subject
+	if len(login) <= MaxLoginLength && loginCharset.MatchString(login) && isAlphanumeric(rune(login[0])) {
+		return login
+	}
+	if len(provider) > MaxLoginLength/2 {
+		provider = provider[:MaxLoginLength/2]
+	}
+	sum := sha256.Sum256([]byte(subject))
+	return (provider + "_" + hex.EncodeToString(sum[:]))[:MaxLoginLength]
+}
+
+// Email checks an email address and returns it normalised by NormalizeEmail
+func (v *Validator) Email(field, email string) string {
+	email = NormalizeEmail(email)
+	if !v.Required(field, email) {
+		return email
+	}
+	if len(email) > maxEmailLength || !isEmail(email) {
+		v.Fail(field, CodeInvalidEmail)
+	}
+	return email
+}
+
+// Phone checks a phone number and returns it in E.164 form, reading numbers
+// without a country code as numbers of region. See NormalizePhone.
+func (v *Validator) Phone(field, phone, region string) string {
+	phone = strings.TrimSpace(phone)
+	if !v.Required(field, phone) {
+		return phone
+	}
+	normalized, ok := NormalizePhone(phone, region)
+	if !ok {
+		v.Fail(field, CodeInvalidPhone)
+		return phone
+	}
+	return normalized
+}
+
+// Password checks a new password against the password policy: a length
+// between MinPasswordLength characters and MaxPasswordLength bytes, at least
+// one letter and one digit, and none of the personal values, like the login,
+// in it.
+func (v *Validator) Password(field, password string, personal ...string) {
+	if !v.Required(field, password) {
+		return
+	}
+	var letter, digit bool
+	for _, r := range password {
+		letter = letter || unicode.IsLetter(r)
+		digit = digit || unicode.IsDigit(r)
+	}
+	switch {
+	case utf8.RuneCountInString(password) < MinPasswordLength:
+		v.Fail(field, CodeTooShort, MinPasswordLength)
+	case len(password) > MaxPasswordLength:
+		v.Fail(field, CodeTooLongBytes, MaxPasswordLength)
+	case !letter || !digit:
+		v.Fail(field, CodeWeakPassword)
+	default:
+		lower := strings.ToLower(password)
+		for _, value := range personal {
+			// Skip values too short to matter, like an email's "me" before the @
+			if len(value) >= MinLoginLength && strings.Contains(lower, strings.ToLower(value)) {
+				v.Fail(field, CodePersonalPassword)
+				return
+			}
+		}
+	}
+}
+
+// NormalizeEmail trims an email address and lower-cases it, so addresses
+// differing in case belong to one account
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// isEmail reports whether email is a bare address with a dotted domain
+func isEmail(email string) bool {
+	address, err := mail.ParseAddress(email)
+	if err != nil || address.Address != email || address.Name != "" {
+		return false
+	}
+	at := strings.LastIndexByte(email, '@')
+	return at > 0 && emailDomain.MatchString(email[at+1:])
+}
+
+func isAlphanumeric(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
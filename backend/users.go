@@ -0,0 +1,71 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+
+    "github.com/aygoko/EcoMInd/backend/config"
+    "github.com/aygoko/EcoMInd/backend/domain"
+    "github.com/aygoko/EcoMInd/backend/repository"
+    "github.com/aygoko/EcoMInd/backend/repository/postgres"
+    "github.com/aygoko/EcoMInd/backend/usecases/validate"
+)
+
+const usersUsage = "usage: ecomind users [flags] normalize-phones"
+
+// runUsers implements the users subcommand, which rewrites the phone numbers
+// stored before they were normalised into E.164 form. Numbers that can't be
+// read, or that another user already has in E.164 form, are reported and
+// left as they are.
+func runUsers(cfg *config.Config, args []string) error {
+    if cfg.Storage != "postgres" {
+        return fmt.Errorf("users needs storage postgres, configured storage is %q", cfg.Storage)
+    }
+    if len(args) != 1 || args[0] != "normalize-phones" {
+        return fmt.Errorf(usersUsage)
+    }
+
+    pgDB := openPostgres(cfg)
+    defer pgDB.Close()
+    redisClient := openRedis(cfg)
+    defer redisClient.Close()
+
+    ctx := context.Background()
+    users := postgres.NewUserRepository(pgDB, redisClient, cfg.Redis.CacheTTL, repository.DefaultLogger).(*postgres.UserRepositoryDB)
+    list, err := users.ListWithPhoneNumber(ctx)
+    if err != nil {
+        return err
+    }
+
+    updated, skipped := 0, 0
+    for _, user := range list {
+        phone, ok := validate.NormalizePhone(user.PhoneNumber, cfg.Users.PhoneRegion)
+        if !ok {
+            log.Printf("Skipped user %s: can't read phone number %q", user.Login, user.PhoneNumber)
+            skipped++
+            continue
+        }
+        if phone == user.PhoneNumber {
+            continue
+        }
+        old := user.PhoneNumber
+        user.PhoneNumber = phone
+        err := users.UpdateUser(ctx, user)
+        var conflict *domain.ConflictError
+        if errors.As(err, &conflict) {
+            log.Printf("Skipped user %s: phone number %q is %s, which another user has", user.Login, old, phone)
+            skipped++
+            continue
+        } else if err != nil {
+            return err
+        }
+        updated++
+    }
+    log.Printf("Normalised %d phone number(s), skipped %d", updated, skipped)
+    if skipped > 0 {
+        return fmt.Errorf("%d phone number(s) need fixing by hand", skipped)
+    }
+    return nil
+}